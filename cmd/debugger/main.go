@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"gameboy-emulator/pkg/gameboy"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const help = `Commands while stopped:
+  break [bank:]addr [if cond]  add a breakpoint, e.g. "break 01:4000 if a == 0x12 && hl != $C000"
+  watch r|w|x start[-end] [val] add a watchpoint for reads, writes and/or execution, e.g. "watch rw C000-C0FF 42"
+  delete id                    remove a breakpoint or watchpoint
+  list                         list all breakpoints and watchpoints
+  continue                     run until the next breakpoint
+  step                         execute one instruction
+  next                         execute one instruction, run subroutines until they return
+  finish                       run until the current subroutine returns
+  until addr                   run until the instruction at addr
+  regs                         print the registers
+  x addr [count]               dump memory
+  quit                         exit
+Commands while running:
+  pause                        stop before the next instruction, other commands wait for the stop
+Addresses and banks are hexadecimal, commands may be abbreviated by their first letter.`
+
+// Runs a ROM without window and sound under the control of a debugger, which reads commands from stdin. The
+// emulation is stopped before the first instruction.
+func main() {
+	biosPath := flag.String("bios", "dmg_boot.bin", "Path to the boot image")
+	romPath := flag.String("rom", "", "Path to the ROM image to debug, may be compressed (.zip, .gz)")
+	romEntry := flag.String("rom-entry", "", "Name of the ROM image in a zip archive (default: first .gb/.gbc entry)")
+	savePath := flag.String("save", "", "Path to a save game the cartridge RAM is initialized with")
+	flag.Parse()
+
+	if *romPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	bios, err := os.ReadFile(*biosPath)
+	if err != nil {
+		fail("Error reading boot image", err)
+	}
+
+	rom, err := gameboy.ReadCartridgeImage(*romPath, *romEntry)
+	if err != nil {
+		fail("Error reading ROM image", err)
+	}
+
+	var saveData []byte
+	if *savePath != "" {
+		if saveData, err = os.ReadFile(*savePath); err != nil {
+			fail("Error reading save game", err)
+		}
+	}
+
+	gb, err := gameboy.New(
+		gameboy.WithBootROM(bios),
+		gameboy.WithCartridge(rom),
+		gameboy.WithSaveData(saveData),
+		gameboy.WithHostClockSync(false),
+	)
+	if err != nil {
+		fail("Error creating emulator", err)
+	}
+
+	session := newSession(gb.Debugger(), os.Stdin, os.Stdout)
+	session.debugger.Pause()
+	go func() {
+		for {
+			gb.StepFrame()
+		}
+	}()
+	session.run()
+}
+
+// session reads commands and passes them to the debugger. The emulation runs in another goroutine, which reports
+// stops of the debugger to the session.
+type session struct {
+	debugger *gameboy.Debugger
+	stops    chan gameboy.DebugStop
+	lines    chan string
+	pending  []string // commands entered while running
+	out      io.Writer
+	stop     gameboy.DebugStop // last stop
+}
+
+func newSession(debugger *gameboy.Debugger, in io.Reader, out io.Writer) *session {
+	s := &session{
+		debugger: debugger,
+		stops:    make(chan gameboy.DebugStop, 1),
+		lines:    make(chan string),
+		out:      out,
+	}
+	debugger.SetStopHandler(func(stop gameboy.DebugStop) { s.stops <- stop })
+
+	go func() {
+		defer close(s.lines)
+		scanner := bufio.NewScanner(in)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+	}()
+	return s
+}
+
+// run alternates between waiting for the next stop and executing commands while stopped, until quit is entered or
+// the input ends
+func (s *session) run() {
+	for {
+		if !s.waitForStop() {
+			return
+		}
+		s.printStop()
+
+		resumed := false
+		for !resumed {
+			fmt.Fprint(s.out, "(gb) ")
+			line, ok := s.nextLine()
+			if !ok {
+				return
+			}
+
+			var quit bool
+			if resumed, quit = s.execute(strings.Fields(line)); quit {
+				return
+			}
+		}
+	}
+}
+
+// waitForStop waits until the debugger stops the emulation. Meanwhile, pause is executed right away and all other
+// commands are kept for when the emulation is stopped, so commands may be entered ahead (or piped in). It returns
+// false if the input ended without commands left.
+func (s *session) waitForStop() bool {
+	for {
+		select {
+		case s.stop = <-s.stops:
+			return true
+		case line, ok := <-s.lines:
+			command, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch {
+			case !ok && len(s.pending) == 0:
+				return false
+			case !ok:
+				s.lines = nil // wait for the stop only
+			case matches(command, "pause"):
+				s.debugger.Pause()
+			default:
+				s.pending = append(s.pending, line)
+			}
+		}
+	}
+}
+
+// nextLine returns the next command entered, the second result is false if the input ended
+func (s *session) nextLine() (string, bool) {
+	if len(s.pending) > 0 {
+		line := s.pending[0]
+		s.pending = s.pending[1:]
+		fmt.Fprintln(s.out, line)
+		return line, true
+	}
+	if s.lines == nil {
+		return "", false
+	}
+
+	line, ok := <-s.lines
+	return line, ok
+}
+
+// execute executes the given command while stopped. It returns whether the emulation was resumed or quit was
+// entered.
+func (s *session) execute(args []string) (resumed bool, quit bool) {
+	if len(args) == 0 {
+		return false, false
+	}
+
+	command, args := args[0], args[1:]
+	switch {
+	case matches(command, "break"):
+		s.addBreakpoint(args)
+	case matches(command, "watch"):
+		s.addWatchpoint(args)
+	case matches(command, "delete"):
+		s.removeBreakpoint(args)
+	case matches(command, "list"):
+		s.listBreakpoints()
+	case matches(command, "continue"):
+		s.debugger.Continue()
+		return true, false
+	case matches(command, "step"):
+		s.debugger.Step()
+		return true, false
+	case matches(command, "next"):
+		s.debugger.StepOver()
+		return true, false
+	case matches(command, "finish"):
+		s.debugger.StepOut()
+		return true, false
+	case matches(command, "until"):
+		if address, ok := s.parseAddress(args); ok {
+			s.debugger.RunTo(address)
+			return true, false
+		}
+	case matches(command, "regs"):
+		s.printRegisters()
+	case command == "x":
+		s.dumpMemory(args)
+	case matches(command, "help"):
+		fmt.Fprintln(s.out, help)
+	case matches(command, "quit"):
+		return false, true
+	default:
+		fmt.Fprintf(s.out, "Unknown command %q, enter help for a list of commands\n", command)
+	}
+	return false, false
+}
+
+func (s *session) addBreakpoint(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(s.out, "Usage: break [bank:]addr [if cond]")
+		return
+	}
+
+	var condition string
+	if len(args) > 1 {
+		if args[1] != "if" {
+			fmt.Fprintln(s.out, "Usage: break [bank:]addr [if cond]")
+			return
+		}
+		condition = strings.Join(args[2:], " ")
+	}
+
+	bank := -1
+	location := args[0]
+	if bankText, addressText, found := strings.Cut(location, ":"); found {
+		parsed, err := strconv.ParseUint(bankText, 16, 16)
+		if err != nil {
+			fmt.Fprintf(s.out, "Invalid bank %q\n", bankText)
+			return
+		}
+		bank, location = int(parsed), addressText
+	}
+
+	address, ok := s.parseAddress([]string{location})
+	if !ok {
+		return
+	}
+
+	breakpoint, err := s.debugger.AddBreakpoint(address, bank, condition)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	fmt.Fprintf(s.out, "Breakpoint %d at %s\n", breakpoint.ID, formatLocation(breakpoint.Bank, breakpoint.Address))
+}
+
+func (s *session) removeBreakpoint(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(s.out, "Usage: delete id")
+		return
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil || (!s.debugger.RemoveBreakpoint(id) && !s.debugger.RemoveWatchpoint(id)) {
+		fmt.Fprintf(s.out, "No breakpoint or watchpoint %s\n", args[0])
+	}
+}
+
+func (s *session) addWatchpoint(args []string) {
+	const usage = "Usage: watch r|w|x start[-end] [value]"
+	if len(args) < 2 || len(args) > 3 {
+		fmt.Fprintln(s.out, usage)
+		return
+	}
+
+	var access gameboy.WatchAccess
+	for _, kind := range args[0] {
+		switch kind {
+		case 'r':
+			access |= gameboy.WatchRead
+		case 'w':
+			access |= gameboy.WatchWrite
+		case 'x':
+			access |= gameboy.WatchExecute
+		default:
+			fmt.Fprintln(s.out, usage)
+			return
+		}
+	}
+
+	startText, endText, isRange := strings.Cut(args[1], "-")
+	start, ok := s.parseAddress([]string{startText})
+	if !ok {
+		return
+	}
+	end := start
+	if isRange {
+		if end, ok = s.parseAddress([]string{endText}); !ok {
+			return
+		}
+	}
+
+	value := -1
+	if len(args) == 3 {
+		parsed, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(args[2], "0x"), "$"), 16, 8)
+		if err != nil {
+			fmt.Fprintf(s.out, "Invalid value %q\n", args[2])
+			return
+		}
+		value = int(parsed)
+	}
+
+	watchpoint, err := s.debugger.AddWatchpoint(start, end, access, value)
+	if err != nil {
+		fmt.Fprintln(s.out, err)
+		return
+	}
+	fmt.Fprintf(s.out, "Watchpoint %d: %s\n", watchpoint.ID, formatWatchpoint(watchpoint))
+}
+
+func (s *session) listBreakpoints() {
+	breakpoints := s.debugger.Breakpoints()
+	if len(breakpoints) == 0 {
+		fmt.Fprintln(s.out, "No breakpoints")
+	}
+
+	for _, b := range breakpoints {
+		fmt.Fprintf(s.out, "%3d  %s", b.ID, formatLocation(b.Bank, b.Address))
+		if len(b.Condition) > 0 {
+			fmt.Fprintf(s.out, " if %s", b.Condition)
+		}
+		fmt.Fprintln(s.out)
+	}
+
+	watchpoints := s.debugger.Watchpoints()
+	if len(watchpoints) == 0 {
+		fmt.Fprintln(s.out, "No watchpoints")
+	}
+
+	for _, w := range watchpoints {
+		fmt.Fprintf(s.out, "%3d  %s\n", w.ID, formatWatchpoint(w))
+	}
+}
+
+func (s *session) dumpMemory(args []string) {
+	address, ok := s.parseAddress(args)
+	if !ok {
+		return
+	}
+
+	count := 16
+	if len(args) > 1 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed <= 0 {
+			fmt.Fprintf(s.out, "Invalid count %q\n", args[1])
+			return
+		}
+		count = parsed
+	}
+
+	for row := 0; row < count; row += 16 {
+		fmt.Fprintf(s.out, "%04X:", address+uint16(row))
+		for column := row; column < min(row+16, count); column++ {
+			fmt.Fprintf(s.out, " %02X", s.debugger.ReadMemory(address+uint16(column)))
+		}
+		fmt.Fprintln(s.out)
+	}
+}
+
+func (s *session) printStop() {
+	switch s.stop.Reason {
+	case gameboy.StopBreakpoint:
+		fmt.Fprintf(s.out, "Breakpoint %d, ", s.stop.Breakpoint)
+	case gameboy.StopPause:
+		fmt.Fprint(s.out, "Paused, ")
+	case gameboy.StopWatchpoint:
+		hit := s.stop.Watch
+		fmt.Fprintf(s.out, "Watchpoint %d, %s %04X", hit.Watchpoint, hit.Access, hit.Address)
+		if hit.Access == gameboy.WatchWrite {
+			fmt.Fprintf(s.out, " %02X -> %02X", hit.Old, hit.New)
+		} else {
+			fmt.Fprintf(s.out, " %02X", hit.New)
+		}
+		if hit.RAMBank >= 0 {
+			fmt.Fprintf(s.out, " (RAM bank %02X)", hit.RAMBank)
+		}
+		fmt.Fprintf(s.out, " by %s, now at ", formatLocation(hit.ROMBank, hit.PC))
+	}
+
+	fmt.Fprintf(s.out, "%s  %s", formatLocation(s.stop.Bank, s.stop.Registers.PC), s.stop.Instruction)
+	if s.stop.Halted {
+		fmt.Fprint(s.out, " (halted)")
+	}
+	fmt.Fprintln(s.out)
+}
+
+func (s *session) printRegisters() {
+	r := s.stop.Registers
+	fmt.Fprintf(s.out, "A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X\n",
+		r.A, r.F, r.B, r.C, r.D, r.E, r.H, r.L, r.SP, r.PC)
+}
+
+// parseAddress parses the hexadecimal address in the first argument, optionally prefixed by "0x" or "$"
+func (s *session) parseAddress(args []string) (uint16, bool) {
+	if len(args) == 0 {
+		fmt.Fprintln(s.out, "Address missing")
+		return 0, false
+	}
+
+	text := strings.TrimPrefix(strings.TrimPrefix(args[0], "0x"), "$")
+	address, err := strconv.ParseUint(text, 16, 16)
+	if err != nil {
+		fmt.Fprintf(s.out, "Invalid address %q\n", args[0])
+		return 0, false
+	}
+	return uint16(address), true
+}
+
+// formatLocation formats an address like "01:4000", or "C000" if the bank doesn't matter
+func formatLocation(bank int, address uint16) string {
+	if bank < 0 {
+		return fmt.Sprintf("%04X", address)
+	}
+	return fmt.Sprintf("%02X:%04X", bank, address)
+}
+
+// formatWatchpoint formats a watchpoint like "write C000-C0FF == 42"
+func formatWatchpoint(w gameboy.Watchpoint) string {
+	text := fmt.Sprintf("%s %04X", w.Access, w.Start)
+	if w.End != w.Start {
+		text += fmt.Sprintf("-%04X", w.End)
+	}
+	if w.Value >= 0 {
+		text += fmt.Sprintf(" == %02X", w.Value)
+	}
+	return text
+}
+
+// matches returns true if the given input is the command or its first letter
+func matches(input string, command string) bool {
+	return input == command || input == command[:1]
+}
+
+func fail(message string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
+	os.Exit(1)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"gameboy-emulator/pkg/disasm"
+	"gameboy-emulator/pkg/gameboy"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Disassembles a range of ROM banks of a ROM image. Labels are taken from a symbol file, which is by default the one
+// next to the ROM image (e.g. "game.sym" for "game.gb").
+func main() {
+	romPath := flag.String("rom", "", "Path to the ROM image to disassemble, may be compressed (.zip, .gz)")
+	romEntry := flag.String("rom-entry", "", "Name of the ROM image in a zip archive (default: first .gb/.gbc entry)")
+	symPath := flag.String("sym", "", "Path to an RGBDS/BGB symbol file (default: .sym file next to the ROM image)")
+	banks := flag.String("bank", "0", "Hexadecimal ROM bank or range of banks to disassemble, e.g. \"1\" or \"1-1F\"")
+	start := flag.String("start", "", "Hexadecimal address to start at in each bank (default: start of the bank)")
+	end := flag.String("end", "", "Hexadecimal address to end at (inclusive) in each bank (default: end of the bank)")
+	// Addresses are mapped into the window of each bank, so "-bank 0-3 -start 100" starts at 0x0100 in bank 0 and
+	// at 0x4100 in the other banks
+	flag.Parse()
+
+	if *romPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	firstBank, lastBank, err := parseBanks(*banks)
+	if err != nil {
+		fail("Invalid bank", err)
+	}
+
+	rom, err := gameboy.ReadCartridgeImage(*romPath, *romEntry)
+	if err != nil {
+		fail("Error reading ROM image", err)
+	}
+
+	symbols, err := loadSymbols(*symPath, *romPath)
+	if err != nil {
+		fail("Error reading symbol file", err)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	for bank := firstBank; bank <= lastBank && bank*0x4000 < len(rom); bank++ {
+		window := uint16(0x4000)
+		if bank == 0 {
+			window = 0x0000
+		}
+
+		from, err := parseAddress(*start, window, window)
+		if err != nil {
+			fail("Invalid start address", err)
+		}
+		to, err := parseAddress(*end, window, window+0x3FFF)
+		if err != nil {
+			fail("Invalid end address", err)
+		}
+
+		instructions, err := disasm.DisassembleROM(rom, bank, from, to, symbols)
+		if err != nil {
+			fail(fmt.Sprintf("Error disassembling bank %02X", bank), err)
+		}
+		for _, instruction := range instructions {
+			if name, ok := symbols.Lookup(bank, instruction.Address); ok {
+				fmt.Fprintf(out, "%s:\n", name)
+			}
+			fmt.Fprintf(out, "%02X:%04X  %-8s  %s\n", bank, instruction.Address, formatBytes(instruction.Bytes),
+				instruction.Text)
+		}
+	}
+}
+
+// loadSymbols reads the given symbol file, or the one next to the ROM image if the path is empty. Without symbol
+// file, the disassembly has no labels.
+func loadSymbols(path string, romPath string) (*disasm.Symbols, error) {
+	if path != "" {
+		return disasm.LoadSymbols(path)
+	}
+
+	symbols, err := disasm.LoadSymbols(gameboy.CompanionPath(romPath, ".sym"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return symbols, err
+}
+
+// parseBanks parses a bank or a range of banks like "1-1F"
+func parseBanks(text string) (int, int, error) {
+	first, last, isRange := strings.Cut(text, "-")
+	firstBank, err := strconv.ParseUint(first, 16, 16)
+	if err != nil || !isRange {
+		return int(firstBank), int(firstBank), err
+	}
+
+	lastBank, err := strconv.ParseUint(last, 16, 16)
+	if err == nil && lastBank < firstBank {
+		err = fmt.Errorf("bank range %q is empty", text)
+	}
+	return int(firstBank), int(lastBank), err
+}
+
+// parseAddress parses an address and maps it into the given window of a bank (0x0000 or 0x4000)
+func parseAddress(text string, window uint16, defaultAddress uint16) (uint16, error) {
+	if text == "" {
+		return defaultAddress, nil
+	}
+	address, err := strconv.ParseUint(text, 16, 16)
+	return window | uint16(address)&0x3FFF, err
+}
+
+func formatBytes(code []byte) string {
+	hex := make([]string, len(code))
+	for i, b := range code {
+		hex[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(hex, " ")
+}
+
+func fail(message string, err error) {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
+	os.Exit(1)
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"gameboy-emulator/pkg/gameboy"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Exit codes to be evaluated by CI pipelines
+const (
+	exitConditionMet = 0
+	exitFailure      = 1
+	exitUsage        = 2
+)
+
+var grayscale = [4]color.Gray{{255}, {192}, {96}, {0}}
+
+// Runs a ROM without window and sound for a given number of frames or until the serial output
+// contains a given text. Afterward, the last frame is written as PNG and the serial output is
+// written to a file.
+func main() {
+	os.Exit(run())
+}
+
+// run runs the emulation as configured by the command line flags and returns the exit code. It returns instead of
+// exiting, so deferred functions like flushing the log are executed.
+func run() int {
+	biosPath := flag.String("bios", "dmg_boot.bin", "Path to the boot image")
+	romPath := flag.String("rom", "", "Path to the ROM image to run, may be compressed (.zip, .gz)")
+	romEntry := flag.String("rom-entry", "", "Name of the ROM image in a zip archive (default: first .gb/.gbc entry)")
+	logConfigPath := flag.String("log", "", "Path to zap logging config (logging is disabled if empty)")
+	frames := flag.Int("frames", 600, "Maximum number of frames to run")
+	untilSerial := flag.String("until-serial", "", "Stop as soon as the serial output contains this text")
+	pngPath := flag.String("png", "", "Path to write the last frame to as PNG")
+	serialPath := flag.String("serial", "", "Path to write the serial output to")
+	moviePath := flag.String("movie", "", "Path to a movie whose input is played back")
+	savePath := flag.String("save", "", "Path to a save game the cartridge RAM is initialized with")
+	exportSavePath := flag.String("export-save", "", "Path to write the save game to after the run (.sav format)")
+	cheatsPath := flag.String("cheats", "", "Path to a cheat file with Game Genie and GameShark codes")
+	cameraPath := flag.String("camera", "", "PNG image or directory of PNG images seen by the Game Boy Camera")
+	info := flag.Bool("info", false, "Print the cartridge header and exit")
+	tracePath := flag.String("trace", "", "Path to write a CPU trace in the format of Gameboy Doctor to")
+	traceBootROM := flag.Bool("trace-boot-rom", false, "Trace the boot ROM, too")
+	traceStart := flag.String("trace-start", "", "Hexadecimal address of the instruction to start the trace at")
+	traceStop := flag.String("trace-stop", "", "Hexadecimal address of the instruction to stop the trace at")
+	traceLines := flag.Int("trace-lines", 0, "Maximum number of instructions to trace (0 for no limit)")
+	traceDoctor := flag.Bool("trace-doctor", false, "Let LY read 0x90 while tracing, as the Gameboy Doctor logs expect")
+	flag.Parse()
+
+	if *romPath == "" {
+		flag.Usage()
+		return exitUsage
+	}
+
+	if *logConfigPath != "" {
+		logger, err := createLogger(*logConfigPath)
+		if err != nil {
+			return fail("Error creating logger", err)
+		}
+		defer logger.Sync()
+		undo := zap.ReplaceGlobals(logger)
+		defer undo()
+	}
+
+	bios, err := os.ReadFile(*biosPath)
+	if err != nil {
+		return fail("Error reading boot image", err)
+	}
+
+	rom, err := gameboy.ReadCartridgeImage(*romPath, *romEntry)
+	if err != nil {
+		return fail("Error reading ROM image", err)
+	}
+
+	var saveData []byte
+	if *savePath != "" {
+		if saveData, err = os.ReadFile(*savePath); err != nil {
+			return fail("Error reading save game", err)
+		}
+	}
+
+	var camera gameboy.CameraSource
+	if *cameraPath != "" {
+		if camera, err = gameboy.LoadCameraImages(*cameraPath); err != nil {
+			return fail("Error reading camera images", err)
+		}
+	}
+
+	var serialOutput bytes.Buffer
+	gb, err := gameboy.New(
+		gameboy.WithBootROM(bios),
+		gameboy.WithCameraSource(camera),
+		gameboy.WithCartridge(rom),
+		gameboy.WithSaveData(saveData),
+		gameboy.WithHostClockSync(false), // runs have to be reproducible
+		gameboy.WithSerialSink(func(data byte) {
+			serialOutput.WriteByte(data)
+		}),
+	)
+	if err != nil {
+		return fail("Error creating emulator", err)
+	}
+
+	if *cheatsPath != "" {
+		if err := gb.LoadCheats(*cheatsPath); err != nil {
+			return fail("Error reading cheats", err)
+		}
+	}
+
+	if *info {
+		header, _ := gb.CartridgeHeader()
+		printHeader(header)
+		return exitConditionMet
+	}
+
+	if *moviePath != "" {
+		if err := playMovie(gb, *moviePath); err != nil {
+			return fail("Error playing movie", err)
+		}
+	}
+
+	var trace *os.File
+	if *tracePath != "" {
+		options, err := traceOptions(!*traceBootROM, *traceStart, *traceStop, *traceLines)
+		if err != nil {
+			return fail("Invalid trace address", err)
+		}
+		options.StubLY = *traceDoctor
+		if trace, err = os.Create(*tracePath); err != nil {
+			return fail("Error creating trace", err)
+		}
+		gb.StartTrace(trace, options)
+	}
+
+	conditionMet := false
+	for frame := 0; frame < *frames && !conditionMet; frame++ {
+		gb.StepFrame()
+		conditionMet = *untilSerial != "" && bytes.Contains(serialOutput.Bytes(), []byte(*untilSerial))
+	}
+
+	if trace != nil {
+		if err := errors.Join(gb.StopTrace(), trace.Close()); err != nil {
+			return fail("Error writing trace", err)
+		}
+	}
+
+	if *pngPath != "" {
+		if err := writePNG(*pngPath, gb.Framebuffer()); err != nil {
+			return fail("Error writing frame", err)
+		}
+	}
+
+	if *serialPath != "" {
+		if err := os.WriteFile(*serialPath, serialOutput.Bytes(), 0644); err != nil {
+			return fail("Error writing serial output", err)
+		}
+	}
+
+	if *exportSavePath != "" {
+		if err := gb.ExportSaveGame(*exportSavePath); err != nil {
+			return fail("Error writing save game", err)
+		}
+	}
+
+	if *untilSerial != "" && !conditionMet {
+		fmt.Fprintf(os.Stderr, "Serial output did not contain %q after %d frames\n", *untilSerial, *frames)
+		return exitFailure
+	}
+	return exitConditionMet
+}
+
+func printHeader(header gameboy.CartridgeHeader) {
+	fmt.Printf("Title:           %s\n", header.Title)
+	fmt.Printf("Type:            %s\n", header.TypeName())
+	fmt.Printf("ROM size:        %d KiB\n", header.ROMSize/1024)
+	fmt.Printf("RAM size:        %d KiB\n", header.RAMSize/1024)
+	fmt.Printf("Version:         %d\n", header.Version)
+	fmt.Printf("Header checksum: 0x%02X\n", header.HeaderChecksum)
+	fmt.Printf("Global checksum: 0x%04X\n", header.GlobalChecksum)
+	if err := header.Verify(); err != nil {
+		fmt.Printf("Corrupt image:   %v\n", strings.ReplaceAll(err.Error(), "\n", ", "))
+	}
+}
+
+func traceOptions(skipBootROM bool, start string, stop string, maxLines int) (gameboy.TraceOptions, error) {
+	options := gameboy.TraceOptions{SkipBootROM: skipBootROM, Start: -1, Stop: -1, MaxLines: maxLines}
+	for _, address := range []struct {
+		text   string
+		parsed *int
+	}{{start, &options.Start}, {stop, &options.Stop}} {
+		if address.text == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(address.text, "0x"), "$"), 16, 16)
+		if err != nil {
+			return options, err
+		}
+		*address.parsed = int(value)
+	}
+	return options, nil
+}
+
+func playMovie(gb *gameboy.Emulator, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return gb.PlayMovie(file)
+}
+
+func writePNG(path string, screen gameboy.Frame) error {
+	img := image.NewGray(image.Rect(0, 0, len(screen[0]), len(screen)))
+	for y, line := range screen {
+		for x, pixel := range line {
+			img.SetGray(x, y, grayscale[pixel&0x3])
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = png.Encode(file, img); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func createLogger(logConfigPath string) (*zap.Logger, error) {
+	configFile, err := os.ReadFile(logConfigPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := zap.Config{}
+	if err = yaml.Unmarshal(configFile, &config); err != nil {
+		return nil, err
+	}
+	return config.Build()
+}
+
+// fail reports the error and returns the exit code for it
+func fail(message string, err error) int {
+	fmt.Fprintf(os.Stderr, "%s: %v\n", message, err)
+	return exitFailure
+}
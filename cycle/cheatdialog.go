@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"gameboy-emulator/pkg/gameboy"
+)
+
+// CheatDialog lists the cheats of the running game, which are enabled, disabled, added and removed while the game
+// is running. The cheats are written to the cheat file next to the ROM image when the dialog is closed.
+type CheatDialog struct {
+	gb     *gameboy.Emulator
+	window fyne.Window
+	list   *fyne.Container
+}
+
+func NewCheatDialog(window fyne.Window, gb *gameboy.Emulator) *CheatDialog {
+	return &CheatDialog{
+		gb:     gb,
+		window: window,
+		list:   container.NewVBox(),
+	}
+}
+
+func (cd *CheatDialog) Open() {
+	codeEntry := widget.NewEntry()
+	codeEntry.SetPlaceHolder("ABC-DEF-GHI or 01VVAAAA")
+	descriptionEntry := widget.NewEntry()
+	descriptionEntry.SetPlaceHolder("Description")
+
+	addButton := widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), func() {
+		if err := cd.gb.AddCheat(codeEntry.Text, descriptionEntry.Text); err != nil {
+			dialog.ShowError(err, cd.window)
+			return
+		}
+		codeEntry.SetText("")
+		descriptionEntry.SetText("")
+		cd.refresh()
+	})
+
+	form := container.New(layout.NewGridLayout(3), codeEntry, descriptionEntry, addButton)
+	content := container.NewBorder(nil, form, nil, nil, container.NewVScroll(cd.list))
+	cd.refresh()
+
+	d := dialog.NewCustom("Cheats", "Close", content, cd.window)
+	d.SetOnClosed(func() {
+		if err := cd.gb.SaveCheats(); err != nil {
+			dialog.ShowError(err, cd.window)
+		}
+	})
+	d.Resize(fyne.NewSize(420, 400))
+	d.Show()
+}
+
+// refresh rebuilds the rows of the cheats
+func (cd *CheatDialog) refresh() {
+	cd.list.RemoveAll()
+	for i, code := range cd.gb.Cheats() {
+		index := i
+		check := widget.NewCheck(code.Text+" "+code.Description, func(enabled bool) {
+			cd.gb.SetCheatEnabled(index, enabled)
+		})
+		check.SetChecked(code.Enabled)
+
+		remove := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			cd.gb.RemoveCheat(index)
+			cd.refresh()
+		})
+		cd.list.Add(container.NewBorder(nil, nil, nil, remove, check))
+	}
+	cd.list.Refresh()
+}
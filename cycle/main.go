@@ -2,14 +2,7 @@ package main
 
 import (
 	"flag"
-	"gameboy-emulator/internal/cycle/apu"
-	"gameboy-emulator/internal/cycle/cpu"
-	"gameboy-emulator/internal/cycle/emulation"
-	"gameboy-emulator/internal/cycle/gpu"
-	"gameboy-emulator/internal/cycle/interrupts"
-	"gameboy-emulator/internal/cycle/joypad"
-	"gameboy-emulator/internal/cycle/memory"
-	"gameboy-emulator/internal/cycle/timer"
+	"gameboy-emulator/pkg/gameboy"
 	"github.com/ebitengine/oto/v3"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
@@ -26,6 +19,9 @@ func main() {
 
 	biosPath := flag.String("bios", filepath.Join(appPath, "dmg_boot.bin"), "Path to the boot image")
 	logConfigPath := flag.String("log", filepath.Join(appPath, "zap_config.yaml"), "Path to zap logging config")
+	paced := flag.Bool("paced", false, "Pace the emulation on frame boundaries instead of audio playback (no sound)")
+	moviePath := flag.String("record", "", "Record the joypad input into this movie file, starting at power-on")
+	rewindSeconds := flag.Int("rewind", 120, "Seconds of history kept for rewinding (0 disables rewinding)")
 	flag.Parse()
 
 	// Setup Logger
@@ -41,21 +37,28 @@ func main() {
 		panic(err)
 	}
 
-	// Wire dependencies
-	a := apu.New()
-	i := interrupts.New()
-	j := joypad.New(i)
-	t := timer.New(i)
-	p := gpu.NewPPU(i)
-	m := memory.New(i, t, p, j, a, (*[0x100]byte)(bios))
-	c := cpu.New(m, i)
+	ui := NewUserInterface()
+	gb, err := gameboy.New(
+		gameboy.WithBootROM(bios),
+		gameboy.WithVideoSink(ui.UpdateFrame),
+		gameboy.WithRumbleSink(ui.onRumble),
+		gameboy.WithRewind(*rewindSeconds),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer gb.SaveGame()
 
-	emulatorCore := emulation.NewCore(i, j, t, p, m, c, a)
-	defer emulatorCore.SaveGame()
+	if *paced {
+		ui.Attach(gb, gameboy.NewPacer(gb, nil))
+		ui.RecordMovie(*moviePath)
+		ui.ShowAndRun()
+		return
+	}
 
 	// Setup sound
 	op := &oto.NewContextOptions{}
-	op.SampleRate = apu.SamplingRate
+	op.SampleRate = gameboy.SampleRate
 	op.ChannelCount = 2
 	op.Format = oto.FormatUnsignedInt8
 	op.BufferSize = 4096
@@ -67,8 +70,8 @@ func main() {
 	<-ready
 
 	// Create Sound d
-	driver := NewSoundDriver(ctx, emulatorCore)
-	ui := NewUserInterface(driver)
+	ui.Attach(gb, NewSoundDriver(ctx, gb))
+	ui.RecordMovie(*moviePath)
 	ui.ShowAndRun()
 }
 
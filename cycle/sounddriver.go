@@ -1,31 +1,43 @@
 package main
 
 import (
-	"gameboy-emulator/internal/cycle/emulation"
+	"gameboy-emulator/pkg/gameboy"
 	"github.com/ebitengine/oto/v3"
 	"io"
+	"sync/atomic"
+	"time"
 )
 
 const playerBufferSize = 4096
 const readAhead = 512
 
+// silence is played for every frame while rewinding, one frame worth of stereo samples
+var silence = make([]byte, 2*int(gameboy.SampleRate*gameboy.FrameDuration/time.Second))
+
+// SoundDriver runs the emulation whenever the audio player needs more samples, so the emulation is paced by the
+// sound card.
 type SoundDriver struct {
-	ctx     *oto.Context
-	pl      *oto.Player
-	core    *emulation.Core
-	stopped bool
-	muted   bool
+	ctx       *oto.Context
+	pl        *oto.Player
+	emulator  *gameboy.Emulator
+	stopped   atomic.Bool
+	rewinding atomic.Bool
+
+	// samples of the last emulated frame not played yet
+	pending []byte
 }
 
-func NewSoundDriver(ctx *oto.Context, core *emulation.Core) *SoundDriver {
+func NewSoundDriver(ctx *oto.Context, emulator *gameboy.Emulator) *SoundDriver {
 	return &SoundDriver{
-		ctx:  ctx,
-		core: core,
+		ctx:      ctx,
+		emulator: emulator,
 	}
 }
 
 func (d *SoundDriver) Run() {
-	d.stopped = false
+	d.stopped.Store(false)
+	d.pending = nil
+
 	d.pl = d.ctx.NewPlayer(d)
 	d.pl.SetBufferSize(playerBufferSize)
 	d.pl.SetVolume(0)
@@ -49,8 +61,9 @@ func (d *SoundDriver) IsPaused() bool {
 	return d.pl != nil && !d.pl.IsPlaying()
 }
 
+// Stop ends the emulation and waits until the player doesn't read samples anymore.
 func (d *SoundDriver) Stop() {
-	d.stopped = true
+	d.stopped.Store(true)
 	d.pl.SetVolume(0)
 	d.pl.Pause()
 	err := d.pl.Close()
@@ -58,31 +71,45 @@ func (d *SoundDriver) Stop() {
 		panic(err)
 	}
 	d.pl = nil
-	d.core.SaveGame()
-	d.core.Reset()
 }
 
-func (d *SoundDriver) GetCore() *emulation.Core {
-	return d.core
+// SetRewinding starts or stops playing back the recorded history in reverse, see gameboy.Emulator.Rewind.
+func (d *SoundDriver) SetRewinding(rewinding bool) {
+	d.rewinding.Store(rewinding)
 }
 
 func (d *SoundDriver) Read(buffer []byte) (int, error) {
 	for i := 0; i < readAhead; {
-		if d.stopped {
+		if d.stopped.Load() {
 			return 0, io.EOF
 		}
 
-		left, right, play := d.core.Tick()
-
-		if play {
-			buffer[i] = left
-			buffer[i+1] = right
-			i += 2
+		if len(d.pending) == 0 {
+			d.nextFrame()
 		}
+		n := copy(buffer[i:readAhead], d.pending)
+		d.pending = d.pending[n:]
+		i += n
 	}
 	return readAhead, nil
 }
 
+// nextFrame emulates the next frame, or rewinds one frame without sound. If the history is exhausted, the
+// emulation stands still until rewinding is stopped.
+func (d *SoundDriver) nextFrame() {
+	if d.rewinding.Load() {
+		d.emulator.Rewind()
+		d.pending = silence
+		return
+	}
+
+	d.emulator.StepFrame()
+	d.pending = d.emulator.Audio()
+	if len(d.pending) == 0 {
+		d.pending = silence
+	}
+}
+
 func (d *SoundDriver) ToggleMute() {
 	if d.pl == nil {
 		return
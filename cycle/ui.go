@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/canvas"
@@ -10,11 +12,25 @@ import (
 	"fyne.io/fyne/v2/storage"
 	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
-	"gameboy-emulator/internal/cycle/emulation"
+	"gameboy-emulator/pkg/gameboy"
+	log "go.uber.org/zap"
 	"image"
 	"image/color"
+	"os"
+	"time"
 )
 
+// Holding this key plays back the recent history in reverse, if enabled
+const rewindKey = fyne.KeyBackspace
+
+// Keys tilting cartridges with an accelerometer by 90 degrees on the x and y axis while held
+var tiltKeys = map[fyne.KeyName][2]float64{
+	fyne.KeyJ: {-1, 0},
+	fyne.KeyL: {1, 0},
+	fyne.KeyI: {0, -1},
+	fyne.KeyK: {0, 1},
+}
+
 var colorPalettes = map[string][4]color.NRGBA{
 	"plainGrayscale": {
 		{255, 255, 255, 255},
@@ -36,6 +52,13 @@ type UserInterface struct {
 	display *canvas.Image
 
 	screenContents *image.NRGBA
+	romName        string
+	rumbling       bool
+	tiltX, tiltY   float64
+	tiltKeysHeld   map[fyne.KeyName]bool
+
+	moviePath string
+	recording bool
 
 	openAction     *widget.ToolbarAction
 	stopAction     *widget.ToolbarAction
@@ -43,24 +66,56 @@ type UserInterface struct {
 	playAction     *widget.ToolbarAction
 	muteAction     *widget.ToolbarAction
 	settingsAction *widget.ToolbarAction
+	importAction   *widget.ToolbarAction
+	exportAction   *widget.ToolbarAction
+	cheatAction    *widget.ToolbarAction
 
-	driver   emulation.Driver
+	gb       *gameboy.Emulator
+	driver   gameboy.Driver
 	settings *Settings
+
+	// closed to stop showing the statistics of the running pacer, nil if they aren't shown
+	statisticsDone chan struct{}
 }
 
-func NewUserInterface(driver emulation.Driver) *UserInterface {
+func NewUserInterface() *UserInterface {
 	ui := &UserInterface{
-		driver: driver,
+		tiltKeysHeld: make(map[fyne.KeyName]bool),
 	}
 	ui.initialize()
-
-	driver.GetCore().SetScreenHandler(ui.UpdateFrame)
-
 	return ui
 }
 
+// Attach sets the emulator controlled by the user interface and the driver running it. The video and rumble sinks
+// of the emulator have to be set to UpdateFrame and onRumble.
+func (ui *UserInterface) Attach(gb *gameboy.Emulator, driver gameboy.Driver) {
+	ui.gb = gb
+	ui.driver = driver
+}
+
 func (ui *UserInterface) ShowAndRun() {
 	ui.window.ShowAndRun()
+	ui.stopStatistics()
+
+	if ui.recording {
+		ui.driver.Stop()
+		ui.writeMovie()
+	}
+}
+
+// onRumble logs whenever the rumble motor of the cartridge is turned on or off
+func (ui *UserInterface) onRumble(on bool, intensity float64) {
+	if on == ui.rumbling {
+		return
+	}
+	ui.rumbling = on
+	log.L().Info("Rumble", log.Bool("on", on), log.Float64("intensity", intensity))
+}
+
+// RecordMovie records the input into a movie file at the given path as soon as a ROM is opened. The movie is
+// written when the emulation is stopped. Nothing is recorded if the path is empty.
+func (ui *UserInterface) RecordMovie(path string) {
+	ui.moviePath = path
 }
 
 func (ui *UserInterface) initialize() {
@@ -89,12 +144,26 @@ func (ui *UserInterface) initialize() {
 
 	ui.settingsAction = widget.NewToolbarAction(theme.SettingsIcon(), ui.onSettings)
 
+	// Save games can only be exchanged while the emulation is stopped
+	ui.importAction = widget.NewToolbarAction(theme.DownloadIcon(), ui.onImportSaveGame)
+	ui.importAction.Disable()
+
+	ui.exportAction = widget.NewToolbarAction(theme.UploadIcon(), ui.onExportSaveGame)
+	ui.exportAction.Disable()
+
+	ui.cheatAction = widget.NewToolbarAction(theme.ListIcon(), ui.onCheats)
+	ui.cheatAction.Disable()
+
 	toolBar := widget.NewToolbar(
 		ui.openAction,
 		widget.NewToolbarSeparator(),
 		ui.playAction,
 		ui.pauseAction,
 		ui.stopAction,
+		widget.NewToolbarSeparator(),
+		ui.importAction,
+		ui.exportAction,
+		ui.cheatAction,
 		widget.NewToolbarSpacer(),
 		ui.muteAction,
 		ui.settingsAction,
@@ -108,22 +177,62 @@ func (ui *UserInterface) initialize() {
 
 	if deskCanvas, ok := ui.window.Canvas().(desktop.Canvas); ok {
 		deskCanvas.SetOnKeyDown(func(e *fyne.KeyEvent) {
+			if e.Name == rewindKey {
+				ui.driver.SetRewinding(true)
+				return
+			}
+			if _, exists := tiltKeys[e.Name]; exists {
+				ui.tiltKeysHeld[e.Name] = true
+				ui.updateTilt()
+				return
+			}
 
 			if keyIndex, exists := ui.settings.GetKeyMap()[e.Name]; exists {
-				ui.driver.GetCore().KeyPressed(keyIndex)
+				ui.gb.SetButton(gameboy.Button(keyIndex), true)
 			}
 		})
 
 		deskCanvas.SetOnKeyUp(func(e *fyne.KeyEvent) {
+			if e.Name == rewindKey {
+				ui.driver.SetRewinding(false)
+				return
+			}
+			if _, exists := tiltKeys[e.Name]; exists {
+				delete(ui.tiltKeysHeld, e.Name)
+				ui.updateTilt()
+				return
+			}
+
 			if keyIndex, exists := ui.settings.GetKeyMap()[e.Name]; exists {
-				ui.driver.GetCore().KeyReleased(keyIndex)
+				ui.gb.SetButton(gameboy.Button(keyIndex), false)
 			}
 		})
 	}
 
+	// Key releases are missed while the window is in the background, so the cartridge is leveled again
+	ui.app.Lifecycle().SetOnExitedForeground(func() {
+		clear(ui.tiltKeysHeld)
+		ui.updateTilt()
+	})
+
 	ui.window.CenterOnScreen()
 }
 
+// updateTilt tilts the cartridge by the sum of the held tilt keys, at most 90 degrees on each axis
+func (ui *UserInterface) updateTilt() {
+	ui.tiltX, ui.tiltY = 0, 0
+	for key := range ui.tiltKeysHeld {
+		ui.tiltX += tiltKeys[key][0]
+		ui.tiltY += tiltKeys[key][1]
+	}
+	ui.tiltX = max(-1, min(1, ui.tiltX))
+	ui.tiltY = max(-1, min(1, ui.tiltY))
+
+	if ui.gb != nil {
+		ui.gb.SetTilt(ui.tiltX, ui.tiltY)
+	}
+}
+
 func (ui *UserInterface) onOpen() {
 	w := ui.app.NewWindow("Open ROM Image")
 	size := fyne.NewSize(1000, 600)
@@ -140,20 +249,41 @@ func (ui *UserInterface) onOpen() {
 			return
 		}
 		selectedFile := f.URI()
+		if err := ui.gb.InsertCartridgeFile(selectedFile.Path()); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
 
-		ui.window.SetTitle(selectedFile.Name())
+		ui.romName = selectedFile.Name()
+		if header, ok := ui.gb.CartridgeHeader(); ok && header.Title != "" {
+			ui.romName = header.Title
+		}
+		ui.window.SetTitle(ui.romName)
 
 		ui.pauseAction.Enable()
 		ui.stopAction.Enable()
 		ui.muteAction.Enable()
 		ui.openAction.Disable()
 		ui.settingsAction.Disable()
-
-		ui.driver.GetCore().InsertCartridge(f.URI().Path())
+		ui.importAction.Disable()
+		ui.exportAction.Disable()
+
+		// cheats enabled during the recording would not be part of the movie
+		if ui.moviePath != "" {
+			if err := ui.gb.RecordMovie(true); err != nil {
+				dialog.ShowError(err, ui.window)
+			} else {
+				ui.recording = true
+			}
+		}
+		if !ui.recording {
+			ui.cheatAction.Enable()
+		}
 		ui.driver.Run()
+		ui.startStatistics()
 		w.Close()
 	}, w)
-	fo.SetFilter(storage.NewExtensionFileFilter([]string{".gb"}))
+	fo.SetFilter(storage.NewExtensionFileFilter(gameboy.ImageExtensions))
 
 	fo.Resize(size)
 	fo.Show()
@@ -167,8 +297,64 @@ func (ui *UserInterface) onStop() {
 	ui.playAction.Enable()
 	ui.openAction.Enable()
 	ui.settingsAction.Enable()
+	ui.importAction.Enable()
+	ui.exportAction.Enable()
+	ui.cheatAction.Disable()
 
 	ui.driver.Stop()
+	ui.stopStatistics()
+	ui.window.SetTitle(ui.romName)
+
+	if ui.recording {
+		ui.writeMovie()
+	}
+
+	// save cartridge RAM when the emulation ends, playing again starts the game from power-on
+	ui.gb.SaveGame()
+	ui.gb.Reset()
+}
+
+// onImportSaveGame replaces the save game of the cartridge with a .sav file, e.g. written by another emulator
+func (ui *UserInterface) onImportSaveGame() {
+	fo := dialog.NewFileOpen(func(f fyne.URIReadCloser, err error) {
+		if err == nil && f != nil {
+			f.Close()
+			err = ui.gb.ImportSaveGame(f.URI().Path())
+		}
+		if err != nil {
+			dialog.ShowError(err, ui.window)
+		}
+	}, ui.window)
+	fo.SetFilter(storage.NewExtensionFileFilter([]string{".sav"}))
+	fo.Show()
+}
+
+// onExportSaveGame writes the save game of the cartridge as .sav file for other emulators
+func (ui *UserInterface) onExportSaveGame() {
+	fs := dialog.NewFileSave(func(f fyne.URIWriteCloser, err error) {
+		if err == nil && f != nil {
+			f.Close()
+			err = ui.gb.ExportSaveGame(f.URI().Path())
+		}
+		if err != nil {
+			dialog.ShowError(err, ui.window)
+		}
+	}, ui.window)
+	fs.SetFileName(ui.romName + ".sav")
+	fs.Show()
+}
+
+// writeMovie writes the recorded movie, the emulation has to be stopped before
+func (ui *UserInterface) writeMovie() {
+	var buffer bytes.Buffer
+	err := ui.gb.StopMovie(&buffer)
+	if err == nil {
+		err = os.WriteFile(ui.moviePath, buffer.Bytes(), 0644)
+	}
+	if err != nil {
+		dialog.ShowError(err, ui.window)
+	}
+	ui.recording = false
 }
 
 func (ui *UserInterface) onPause() {
@@ -185,11 +371,14 @@ func (ui *UserInterface) onPlay() {
 	ui.pauseAction.Enable()
 	ui.muteAction.Enable()
 	ui.settingsAction.Disable()
+	ui.importAction.Disable()
+	ui.exportAction.Disable()
 
 	if ui.driver.IsPaused() {
 		ui.driver.TogglePause()
 	} else {
 		ui.driver.Run()
+		ui.startStatistics()
 	}
 }
 
@@ -204,7 +393,7 @@ func (ui *UserInterface) onMute() {
 	}
 }
 
-func (ui *UserInterface) UpdateFrame(screen [144][160]byte) {
+func (ui *UserInterface) UpdateFrame(screen gameboy.Frame) {
 	go fyne.DoAndWait(func() {
 		for y, line := range screen {
 			for x, pixel := range line {
@@ -216,6 +405,51 @@ func (ui *UserInterface) UpdateFrame(screen [144][160]byte) {
 	})
 }
 
+// startStatistics shows the measured emulation speed in the window title while a pacer is running
+func (ui *UserInterface) startStatistics() {
+	p, ok := ui.driver.(*gameboy.Pacer)
+	if !ok || ui.statisticsDone != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	ui.statisticsDone = done
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			if p.IsPaused() {
+				continue
+			}
+
+			fps, speed := p.FPS(), p.Speed()
+			fyne.Do(func() {
+				// the title is only changed on the main goroutine, which also sets the ROM name
+				if ui.statisticsDone == done {
+					ui.window.SetTitle(fmt.Sprintf("%s - %.1f FPS (%.0f%%)", ui.romName, fps, speed))
+				}
+			})
+		}
+	}()
+}
+
+// stopStatistics stops showing the emulation speed, must be called on the main goroutine
+func (ui *UserInterface) stopStatistics() {
+	if ui.statisticsDone != nil {
+		close(ui.statisticsDone)
+		ui.statisticsDone = nil
+	}
+}
+
+func (ui *UserInterface) onCheats() {
+	NewCheatDialog(ui.window, ui.gb).Open()
+}
+
 func (ui *UserInterface) onSettings() {
 	NewSettingsDialog(ui.window.Canvas(), ui.settings).Open()
 }
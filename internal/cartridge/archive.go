@@ -0,0 +1,90 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ImageExtensions are the file extensions of ROM images which can be loaded, either raw or compressed
+var ImageExtensions = []string{".gb", ".gbc", ".zip", ".gz"}
+
+var ErrNoROMInArchive = errors.New("no ROM image found in archive")
+
+// ReadImage reads the ROM image at the given path. Images compressed with gzip (.gz) are decompressed. For zip
+// archives (.zip), the entry with the given name is read. Without a name, the first .gb or .gbc entry is read.
+func ReadImage(path string, entry string) ([]byte, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return readZipEntry(path, entry)
+	case ".gz":
+		return readGzip(path)
+	default:
+		return os.ReadFile(path)
+	}
+}
+
+func readGzip(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer reader.Close()
+
+	return readLimited(path, reader)
+}
+
+func readZipEntry(path string, entry string) ([]byte, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, f := range archive.File {
+		if f.FileInfo().IsDir() || entry == "" && !isROMImage(f.Name) || entry != "" && f.Name != entry {
+			continue
+		}
+
+		reader, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer reader.Close()
+		return readLimited(path, reader)
+	}
+
+	if entry != "" {
+		return nil, fmt.Errorf("%w: %s has no entry %s", ErrNoROMInArchive, path, entry)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoROMInArchive, path)
+}
+
+// readLimited reads a decompressed ROM image, refusing anything bigger than the biggest possible ROM
+func readLimited(path string, reader io.Reader) ([]byte, error) {
+	maxSize := int64(0x8000) << maxROMSizeCode
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%s: ROM image bigger than %d bytes", path, maxSize)
+	}
+	return data, nil
+}
+
+func isROMImage(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".gb" || ext == ".gbc"
+}
@@ -0,0 +1,94 @@
+package cartridge
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestZip writes a zip archive with the given entries
+func writeTestZip(t *testing.T, path string, entries map[string][]byte, order ...string) {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	archive := zip.NewWriter(file)
+	for _, name := range order {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(entries[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+}
+
+func TestReadImage_zip(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "games.zip")
+	entries := map[string][]byte{
+		"readme.txt": {0x01},
+		"first.gb":   {0x02},
+		"second.GBC": {0x03},
+	}
+	writeTestZip(t, path, entries, "readme.txt", "first.gb", "second.GBC")
+
+	// WHEN
+	first, errFirst := ReadImage(path, "")
+	second, errSecond := ReadImage(path, "second.GBC")
+	_, errMissing := ReadImage(path, "third.gb")
+
+	// THEN
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+	assert.Equal(t, []byte{0x02}, first)
+	assert.Equal(t, []byte{0x03}, second)
+	assert.ErrorIs(t, errMissing, ErrNoROMInArchive)
+}
+
+func TestReadImage_zipWithoutROM(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "games.zip")
+	writeTestZip(t, path, map[string][]byte{"readme.txt": {0x01}}, "readme.txt")
+
+	// WHEN
+	_, err := ReadImage(path, "")
+
+	// THEN
+	assert.ErrorIs(t, err, ErrNoROMInArchive)
+}
+
+func TestReadImage_gzip(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "game.gb.gz")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	w := gzip.NewWriter(file)
+	_, err = w.Write([]byte{0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, file.Close())
+
+	// WHEN
+	data, err := ReadImage(path, "")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x01, 0x02}, data)
+}
+
+func TestLoadCartridgeImage_zip(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	path := filepath.Join(dir, "game.zip")
+	writeTestZip(t, path, map[string][]byte{"game.gb": newTestImage(0x03, 0x00, 0x02)}, "game.gb")
+
+	// WHEN
+	cartridge, err := LoadCartridgeImage(path)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "game.sav"), cartridge.(*mbc1).saveGamePath)
+}
@@ -0,0 +1,387 @@
+package cartridge
+
+import (
+	"gameboy-emulator/internal/util"
+	"image"
+	"image/color"
+)
+
+const (
+	// The sensor has 128x128 pixels, of which the middle 128x120 are emulated. 128x112 of them end up in the
+	// picture, the 4 lines above and below are only seen by the edge enhancement filters.
+	cameraSensorWidth  = 128
+	cameraSensorHeight = 120
+	cameraImageHeight  = 112
+
+	// Captured pictures are written as 16x14 tiles into RAM bank 0
+	cameraImageAddress = 0x0100
+
+	// Registers A000–A035, repeated every 0x80 bytes
+	cameraRegisterCount = 0x36
+	cameraDitherMatrix  = 0x06
+)
+
+// Edge enhancement ratios selected by bits 4–6 of register A004
+var cameraEdgeRatios = [8]float64{0.50, 0.75, 1.00, 1.25, 2.00, 3.00, 4.00, 5.00}
+
+// Gains of the output amplifier of the sensor selected by bits 0–4 of register A001, relative to the gain of G = 4.
+// The steps follow the gain curve of the M64282FP datasheet (like SameBoy does).
+var cameraGains = [32]float64{
+	0.8809390, 0.9149149, 0.9457498, 0.9739758, 1.0000000, 1.0241412, 1.0466537, 1.0677433,
+	1.0875793, 1.1240310, 1.1568911, 1.1868043, 1.2142561, 1.2396208, 1.2743837, 1.3157323,
+	1.3525190, 1.3856512, 1.4157897, 1.4434309, 1.4689574, 1.4926697, 1.5148087, 1.5355703,
+	1.5551159, 1.5735801, 1.5910762, 1.6077008, 1.6235366, 1.6386550, 1.6531183, 1.6669808,
+}
+
+// Neighbors of a pixel which its edges are computed with, selected by bits 5–6 (VH) of register A001: none,
+// horizontal, vertical and both (2-D)
+var cameraEdgeNeighbors = [4][][2]int{
+	nil,
+	{{-1, 0}, {1, 0}},
+	{{0, -1}, {0, 1}},
+	{{-1, 0}, {1, 0}, {0, -1}, {0, 1}},
+}
+
+type (
+	// CameraSource provides the pictures seen by the sensor of the Game Boy Camera. See LoadCameraImages.
+	CameraSource interface {
+		// Capture returns the picture in front of the lens when the game takes a photo. It is converted to
+		// grayscale and stretched to the 128x120 pixels used of the sensor.
+		Capture() image.Image
+	}
+
+	// darkCamera is the default camera source, the lens is covered
+	darkCamera struct{}
+
+	// sensorPixels holds the signed voltages of the pixels of the sensor while a picture is processed
+	sensorPixels [cameraSensorWidth][cameraSensorHeight]int
+
+	// pocketCamera is the mapper of the Game Boy Camera (POCKET CAMERA) with 1 MiB ROM and 128 KiB of banked RAM.
+	// Setting bit 4 of the RAM bank register maps the registers of the M64282FP image sensor into A000–BFFF instead of
+	// RAM. Writing 1 to bit 0 of A000 starts a capture, which is processed as configured by the registers (exposure,
+	// edge enhancement, dithering matrix) and written to RAM as tiles once bit 0 reads 0 again.
+	//
+	// RAM can be read without enabling it, only writes require 0x0A written to 0000–1FFF.
+	//
+	// Source: https://gbdev.io/pandocs/Gameboy_Camera.html
+	pocketCamera struct {
+		*cartridgeCore
+
+		romb          byte
+		ramb          byte
+		ramEnabled    bool
+		registersOn   bool
+		registers     [cameraRegisterCount]byte
+		captureCycles uint32 // T-cycles until the capture in progress is finished
+
+		source CameraSource
+	}
+)
+
+func newPocketCamera(core *cartridgeCore, source CameraSource) Cartridge {
+	mbc := &pocketCamera{
+		cartridgeCore: core,
+		romb:          1,
+		source:        source,
+	}
+	mbc.load()
+	return mbc
+}
+
+func (darkCamera) Capture() image.Image {
+	return image.NewGray(image.Rect(0, 0, cameraSensorWidth, cameraSensorHeight))
+}
+
+func (mbc *pocketCamera) Reset() {
+	mbc.romb = 1
+	mbc.ramb = 0
+	mbc.ramEnabled = false
+	mbc.registersOn = false
+	mbc.registers = [cameraRegisterCount]byte{}
+	mbc.captureCycles = 0
+}
+
+func (mbc *pocketCamera) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		physicalAddress := (uint32(mbc.romb)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *pocketCamera) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.romb) << 14)
+}
+
+func (mbc *pocketCamera) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x2000: // Enable/Disable writing RAM
+		mbc.ramEnabled = data&0x0F == 0x0A
+
+	case address < 0x4000: // ROM bank
+		mbc.romb = data & 0x3F
+
+	case address < 0x6000: // RAM bank or sensor registers
+		mbc.registersOn = util.BitIsSet8(data, 4)
+		mbc.ramb = data & 0x0F
+	}
+}
+
+func (mbc *pocketCamera) WriteRAM(address uint16, data byte) {
+	if address >= 0x2000 {
+		return // outside of the RAM area
+	}
+
+	if mbc.registersOn {
+		mbc.writeRegister(byte(address&0x7F), data)
+		return
+	}
+
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return
+	}
+	mbc.ram[mbc.physicalRAMAddress(address)] = data
+}
+
+func (mbc *pocketCamera) RAMBank(address uint16) int {
+	if address >= 0x2000 || mbc.registersOn || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.physicalRAMAddress(address) >> 13)
+}
+
+func (mbc *pocketCamera) ReadRAM(address uint16) byte {
+	if address >= 0x2000 {
+		return 0xFF // outside of the RAM area
+	}
+
+	if mbc.registersOn {
+		// Only the control register can be read back, the others are write-only
+		if address&0x7F == 0x00 {
+			return mbc.registers[0]
+		}
+		return 0x00
+	}
+
+	if len(mbc.ram) == 0 {
+		return 0xFF
+	}
+	return mbc.ram[mbc.physicalRAMAddress(address)]
+}
+
+func (mbc *pocketCamera) physicalRAMAddress(address uint16) uint32 {
+	return (uint32(mbc.ramb)<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
+}
+
+func (mbc *pocketCamera) writeRegister(register byte, data byte) {
+	if register >= cameraRegisterCount {
+		return
+	}
+	if register != 0x00 {
+		mbc.registers[register] = data
+		return
+	}
+
+	mbc.registers[0] = data & 0x07
+	switch {
+	case !util.BitIsSet8(data, 0): // stops a capture in progress
+		mbc.captureCycles = 0
+	case mbc.captureCycles == 0:
+		mbc.captureCycles = mbc.captureDuration()
+	}
+}
+
+// captureDuration returns the number of T-cycles a capture takes with the configured exposure time
+func (mbc *pocketCamera) captureDuration() uint32 {
+	exposure := uint32(mbc.registers[2])<<8 | uint32(mbc.registers[3])
+	cycles := 32446 + 16*exposure
+	if !util.BitIsSet8(mbc.registers[1], 7) {
+		cycles += 512
+	}
+	return 4 * cycles
+}
+
+// Tick advances a capture in progress by one T-cycle
+func (mbc *pocketCamera) Tick() {
+	if mbc.captureCycles == 0 {
+		return
+	}
+
+	if mbc.captureCycles--; mbc.captureCycles == 0 {
+		mbc.capture()
+		util.UnsetBit8(&mbc.registers[0], 0)
+	}
+}
+
+// capture takes a picture from the camera source, processes it like the sensor and writes it into RAM
+func (mbc *pocketCamera) capture() {
+	pixels := mbc.expose(mbc.source.Capture())
+	pixels = mbc.filter(pixels)
+
+	if len(mbc.ram) < cameraImageAddress+cameraSensorWidth*cameraImageHeight/4 {
+		return
+	}
+	offset := (cameraSensorHeight - cameraImageHeight) / 2
+	for y := 0; y < cameraImageHeight; y++ {
+		for x := 0; x < cameraSensorWidth; x++ {
+			mbc.writePixel(x, y, mbc.dither(x, y, pixels[x][y+offset]+128))
+		}
+	}
+}
+
+// expose samples the picture with the sensor and returns the signed voltages of the pixels. The charge of a pixel
+// grows linearly with the light and the exposure time (registers A002–A003), 0x1000 saturates a white pixel at the
+// gain of G = 4. The charge is amplified by the gain selected with register A001 and inverted by bit 3 of A004.
+func (mbc *pocketCamera) expose(picture image.Image) (pixels sensorPixels) {
+	exposure := float64(int(mbc.registers[2])<<8 | int(mbc.registers[3]))
+	gain := cameraGains[mbc.registers[1]&0x1F]
+	invert := util.BitIsSet8(mbc.registers[4], 3)
+
+	var bounds image.Rectangle
+	if picture != nil {
+		bounds = picture.Bounds()
+	}
+
+	for x := 0; x < cameraSensorWidth; x++ {
+		for y := 0; y < cameraSensorHeight; y++ {
+			light := 0
+			if !bounds.Empty() {
+				px := bounds.Min.X + x*bounds.Dx()/cameraSensorWidth
+				py := bounds.Min.Y + y*bounds.Dy()/cameraSensorHeight
+				light = int(color.GrayModel.Convert(picture.At(px, py)).(color.Gray).Y)
+			}
+
+			value := int(float64(light) * gain * exposure / 0x1000)
+			value = max(0, min(255, value))
+			if invert {
+				value = 255 - value
+			}
+			pixels[x][y] = value - 128
+		}
+	}
+	return
+}
+
+// filter applies the edge enhancement and the 1-D filter selected by the registers to the signed pixels. The edge
+// operation (VH) of register A001 enhances the differences to the horizontal, vertical or all four neighbors, or none.
+// The 1-D filter follows unless the edge enhancement is exclusive (bit 7 of A001).
+func (mbc *pocketCamera) filter(pixels sensorPixels) sensorPixels {
+	exclusive := util.BitIsSet8(mbc.registers[1], 7)
+	neighbors := cameraEdgeNeighbors[mbc.registers[1]>>5&0x03]
+	edgeAlpha := cameraEdgeRatios[mbc.registers[4]>>4&0x07]
+	e3 := util.BitIsSet8(mbc.registers[4], 7)
+
+	if !exclusive && len(neighbors) == 0 && e3 {
+		return sensorPixels{} // undocumented, a real cartridge outputs a blank picture
+	}
+
+	result := pixels.enhanceEdges(neighbors, edgeAlpha)
+	if exclusive {
+		return result
+	}
+
+	// Pixels added (P) and subtracted (M) by the 1-D filter, see filter1D
+	var p, m int
+	switch mbc.registers[0] >> 1 & 0x03 {
+	case 0:
+		m = 0x01
+	case 1:
+		p = 0x01
+	default:
+		p, m = 0x01, 0x02
+	}
+	return result.filter1D(p, m)
+}
+
+// at returns the pixel at the given position, pixels outside of the sensor repeat the nearest edge
+func (pixels *sensorPixels) at(x, y int) int {
+	return pixels[max(0, min(cameraSensorWidth-1, x))][max(0, min(cameraSensorHeight-1, y))]
+}
+
+// enhanceEdges adds the difference of each pixel to the given neighbors, weighted with alpha
+func (pixels *sensorPixels) enhanceEdges(neighbors [][2]int, alpha float64) (result sensorPixels) {
+	if len(neighbors) == 0 {
+		return *pixels
+	}
+
+	for x := range pixels {
+		for y := range pixels[x] {
+			px := pixels[x][y]
+			edge := len(neighbors) * px
+			for _, neighbor := range neighbors {
+				edge -= pixels.at(x+neighbor[0], y+neighbor[1])
+			}
+			result[x][y] = max(-128, min(127, px+int(float64(edge)*alpha)))
+		}
+	}
+	return
+}
+
+// filter1D adds (p) and subtracts (m) each pixel (bit 0) and the one below (bit 1)
+func (pixels *sensorPixels) filter1D(p, m int) (result sensorPixels) {
+	for x := range pixels {
+		for y := range pixels[x] {
+			px, below := pixels[x][y], pixels.at(x, y+1)
+			value := 0
+			if p&0x01 != 0 {
+				value += px
+			}
+			if p&0x02 != 0 {
+				value += below
+			}
+			if m&0x01 != 0 {
+				value -= px
+			}
+			if m&0x02 != 0 {
+				value -= below
+			}
+			result[x][y] = max(-128, min(127, value))
+		}
+	}
+	return
+}
+
+// dither converts the value of a pixel to one of the four shades (0 = white) with the 4x4 matrix of thresholds
+// from the registers
+func (mbc *pocketCamera) dither(x, y int, value int) byte {
+	thresholds := mbc.registers[cameraDitherMatrix+3*((y&3)*4+(x&3)):]
+	switch {
+	case value < int(thresholds[0]):
+		return 3
+	case value < int(thresholds[1]):
+		return 2
+	case value < int(thresholds[2]):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// writePixel sets the color of a pixel in the tiles of the picture in RAM bank 0
+func (mbc *pocketCamera) writePixel(x, y int, shade byte) {
+	tile := (y/8)*(cameraSensorWidth/8) + x/8
+	address := cameraImageAddress + 16*tile + 2*(y%8)
+	bit := 7 - byte(x%8)
+
+	mbc.ram[address] &^= 1 << bit
+	mbc.ram[address+1] &^= 1 << bit
+	mbc.ram[address] |= (shade & 0x01) << bit
+	mbc.ram[address+1] |= (shade >> 1) << bit
+}
+
+func (mbc *pocketCamera) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb, mbc.ramb, mbc.ramEnabled, mbc.registersOn, mbc.registers[:], mbc.captureCycles)
+}
+
+func (mbc *pocketCamera) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb, &mbc.ramb, &mbc.ramEnabled, &mbc.registersOn, mbc.registers[:], &mbc.captureCycles)
+}
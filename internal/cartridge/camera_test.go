@@ -0,0 +1,240 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// staticCamera is a camera source always returning the same picture
+type staticCamera struct {
+	picture image.Image
+}
+
+func (c staticCamera) Capture() image.Image {
+	return c.picture
+}
+
+// newSplitPicture creates a picture whose left half is white and whose right half is black
+func newSplitPicture() image.Image {
+	picture := image.NewGray(image.Rect(0, 0, 256, 240))
+	for x := 0; x < 128; x++ {
+		for y := 0; y < 240; y++ {
+			picture.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return picture
+}
+
+// takeTestPhoto configures the sensor without edge enhancement and a fixed dithering matrix, starts a capture and
+// ticks until it is finished
+func takeTestPhoto(cartridge Cartridge) {
+	cartridge.HandleBanking(0x4000, 0x10)
+	cartridge.WriteRAM(0x0001, 0x04) // no edge enhancement, gain G = 4
+	cartridge.WriteRAM(0x0002, 0x10) // exposure 0x1000, white saturates
+	cartridge.WriteRAM(0x0003, 0x00)
+	cartridge.WriteRAM(0x0004, 0x00)
+	for i := uint16(0); i < 16; i++ {
+		cartridge.WriteRAM(0x0006+3*i, 0x40)
+		cartridge.WriteRAM(0x0007+3*i, 0x80)
+		cartridge.WriteRAM(0x0008+3*i, 0xC0)
+	}
+	cartridge.WriteRAM(0x0000, 0x03) // start with the 1-D filter passing pixels unchanged
+
+	for cartridge.ReadRAM(0x0000)&0x01 != 0 {
+		cartridge.Tick()
+	}
+	cartridge.HandleBanking(0x4000, 0x00)
+}
+
+func TestPocketCamera_capture(t *testing.T) {
+	// GIVEN
+	cartridge := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), staticCamera{newSplitPicture()})
+
+	// WHEN
+	takeTestPhoto(cartridge)
+
+	// THEN
+	ram := cartridge.RAM()
+	assert.Equal(t, make([]byte, 16), ram[cameraImageAddress:cameraImageAddress+16]) // white tile
+	blackTile := ram[cameraImageAddress+8*16 : cameraImageAddress+9*16]
+	for _, b := range blackTile {
+		assert.Equal(t, byte(0xFF), b)
+	}
+	assert.Equal(t, byte(0x00), ram[cameraImageAddress-1])
+	assert.Equal(t, byte(0x00), ram[cameraImageAddress+16*16*14])
+}
+
+func TestPocketCamera_expose(t *testing.T) {
+	tests := []struct {
+		name     string
+		a001     byte
+		exposure uint16
+		a004     byte
+		want     int
+	}{
+		{"reference", 0x04, 0x1000, 0x00, 0},
+		{"half exposure", 0x04, 0x0800, 0x00, -64},
+		{"saturated", 0x04, 0x2000, 0x00, 127},
+		{"lowest gain", 0x00, 0x1000, 0x00, -16},
+		{"highest gain", 0x1F, 0x1000, 0x00, 85},
+		{"inverted", 0x04, 0x1000, 0x08, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GIVEN
+			picture := image.NewGray(image.Rect(0, 0, 1, 1))
+			picture.SetGray(0, 0, color.Gray{Y: 128})
+
+			mbc := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), darkCamera{}).(*pocketCamera)
+			mbc.registers[1] = tt.a001
+			mbc.registers[2], mbc.registers[3] = byte(tt.exposure>>8), byte(tt.exposure)
+			mbc.registers[4] = tt.a004
+
+			// WHEN
+			pixels := mbc.expose(picture)
+
+			// THEN
+			assert.Equal(t, tt.want, pixels[0][0])
+			assert.Equal(t, tt.want, pixels[cameraSensorWidth-1][cameraSensorHeight-1])
+		})
+	}
+}
+
+func TestPocketCamera_filter(t *testing.T) {
+	tests := []struct {
+		name                      string
+		a001                      byte
+		center, right, below, top int
+	}{
+		{"none", 0x00, 100, 0, 0, -100},
+		{"horizontal", 0x20, 127, -100, 0, -127},
+		{"vertical", 0x40, 127, 0, -100, -128},
+		{"2-D", 0x60, 127, -100, -100, -128},
+		{"exclusive none", 0x80, 100, 0, 0, 0},
+		{"exclusive horizontal", 0xA0, 127, -100, 0, 0},
+		{"exclusive vertical", 0xC0, 127, 0, -100, -100},
+		{"exclusive 2-D", 0xE0, 127, -100, -100, -100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// GIVEN
+			var pixels sensorPixels
+			pixels[10][10] = 100
+
+			mbc := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), darkCamera{}).(*pocketCamera)
+			mbc.registers[0] = 0x06 // 1-D filter subtracting the pixel below
+			mbc.registers[1] = tt.a001
+			mbc.registers[4] = 0x20 // edge enhancement ratio 100%
+
+			// WHEN
+			result := mbc.filter(pixels)
+
+			// THEN
+			assert.Equal(t, tt.center, result[10][10])
+			assert.Equal(t, tt.right, result[11][10])
+			assert.Equal(t, tt.below, result[10][11])
+			assert.Equal(t, tt.top, result[10][9])
+		})
+	}
+}
+
+func TestPocketCamera_filter_blank(t *testing.T) {
+	// GIVEN
+	var pixels sensorPixels
+	pixels[10][10] = 100
+
+	mbc := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), darkCamera{}).(*pocketCamera)
+	mbc.registers[0] = 0x02
+	mbc.registers[4] = 0x80 // E3 without edge operation
+
+	// WHEN
+	result := mbc.filter(pixels)
+
+	// THEN
+	assert.Equal(t, sensorPixels{}, result)
+}
+
+func TestPocketCamera_captureDuration(t *testing.T) {
+	// GIVEN
+	cartridge := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), darkCamera{})
+	cartridge.HandleBanking(0x4000, 0x10)
+	cartridge.WriteRAM(0x0002, 0x00)
+	cartridge.WriteRAM(0x0003, 0x10)
+
+	// WHEN
+	cartridge.WriteRAM(0x0000, 0x01)
+	for i := 0; i < 4*(32446+512+16*0x10)-1; i++ {
+		cartridge.Tick()
+	}
+	busy := cartridge.ReadRAM(0x0000)
+	cartridge.Tick()
+
+	// THEN
+	assert.Equal(t, byte(0x01), busy)
+	assert.Equal(t, byte(0x00), cartridge.ReadRAM(0x0000))
+	assert.Equal(t, byte(0x00), cartridge.ReadRAM(0x0001)) // write-only
+}
+
+func TestPocketCamera_RAM(t *testing.T) {
+	// GIVEN
+	cartridge := newPocketCamera(newTestCore(make([]byte, 0x100000), 0x20000), darkCamera{})
+	cartridge.HandleBanking(0x4000, 0x0F)
+
+	// WHEN
+	cartridge.WriteRAM(0x0000, 0x24)
+	cartridge.HandleBanking(0x0000, 0x0A)
+	cartridge.WriteRAM(0x0001, 0x42)
+	cartridge.HandleBanking(0x0000, 0x00)
+
+	// THEN
+	assert.Equal(t, byte(0x00), cartridge.ReadRAM(0x0000))
+	assert.Equal(t, byte(0x42), cartridge.ReadRAM(0x0001)) // readable without enabling RAM
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0x1E001])
+}
+
+func TestLoadCameraImages(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	for i, name := range []string{"2.png", "1.png"} {
+		picture := image.NewGray(image.Rect(0, 0, 1, 1))
+		picture.SetGray(0, 0, color.Gray{Y: byte(i)})
+		file, err := os.Create(filepath.Join(dir, name))
+		require.NoError(t, err)
+		require.NoError(t, png.Encode(file, picture))
+		require.NoError(t, file.Close())
+	}
+
+	// WHEN
+	sequence, errSequence := LoadCameraImages(dir)
+	static, errStatic := LoadCameraImages(filepath.Join(dir, "2.png"))
+	_, errEmpty := LoadCameraImages(t.TempDir())
+
+	// THEN
+	require.NoError(t, errSequence)
+	require.NoError(t, errStatic)
+	assert.ErrorIs(t, errEmpty, ErrNoCameraImages)
+	assert.Equal(t, color.Gray{Y: 1}, sequence.Capture().At(0, 0))
+	assert.Equal(t, color.Gray{Y: 0}, sequence.Capture().At(0, 0))
+	assert.Equal(t, color.Gray{Y: 1}, sequence.Capture().At(0, 0))
+	assert.Equal(t, color.Gray{Y: 0}, static.Capture().At(0, 0))
+	assert.Equal(t, color.Gray{Y: 0}, static.Capture().At(0, 0))
+}
+
+func TestNewCartridge_pocketCamera(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0xFC, 0x05, 0x04)
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil, WithCameraSource(staticCamera{newSplitPicture()}))
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &pocketCamera{}, cartridge)
+	assert.Len(t, cartridge.RAM(), 0x20000)
+}
@@ -0,0 +1,80 @@
+package cartridge
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var ErrNoCameraImages = errors.New("no PNG images found")
+
+// imageSequence is a camera source returning one image after another for each capture, starting over after the
+// last one. A single image is a static camera source.
+type imageSequence struct {
+	images []image.Image
+	next   int
+}
+
+// LoadCameraImages creates a camera source from PNG images on disk. If the path is a file, every photo shows this
+// image. If it is a directory, the PNG images in it are returned one after another for each photo, ordered by name,
+// and start over after the last one.
+func LoadCameraImages(path string) (CameraSource, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = paths[:0]
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.EqualFold(filepath.Ext(entry.Name()), ".png") {
+				paths = append(paths, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("%w in %s", ErrNoCameraImages, path)
+		}
+		sort.Strings(paths)
+	}
+
+	sequence := &imageSequence{}
+	for _, p := range paths {
+		img, err := readPNG(p)
+		if err != nil {
+			return nil, err
+		}
+		sequence.images = append(sequence.images, img)
+	}
+	return sequence, nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return img, nil
+}
+
+func (s *imageSequence) Capture() image.Image {
+	img := s.images[s.next]
+	s.next = (s.next + 1) % len(s.images)
+	return img
+}
@@ -1,15 +1,32 @@
 package cartridge
 
 import (
+	"errors"
 	"fmt"
 	"gameboy-emulator/internal/util"
-	log "go.uber.org/zap"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 )
 
+const (
+	// Biggest RAM any supported cartridge type provides
+	maxRAMSize = 0x20000
+
+	// Biggest valid value of the ROM size in the header (8 MiB)
+	maxROMSizeCode = 0x08
+
+	// First address after the cartridge header
+	headerEnd = 0x150
+)
+
+var (
+	ErrUnsupportedMBC = errors.New("cartridge type not supported")
+	ErrTruncatedROM   = errors.New("ROM image truncated")
+	ErrBadHeader      = errors.New("invalid cartridge header")
+	ErrROMSize        = errors.New("ROM size isn't a power of two")
+
+	errStateRAMSize = errors.New("RAM size of the state doesn't match the cartridge")
+)
+
 var ramSizes = [6]int{
 	0,       // No RAM
 	0,       // Unused
@@ -22,11 +39,21 @@ var ramSizes = [6]int{
 type (
 	Cartridge interface {
 
-		// ReadROM returns the data stored under the given address in the cartridge ROM.
+		// Reset restores the power-on state of the banking registers. The RAM is kept.
+		Reset()
+
+		// ReadROM returns the data stored under the given address in the cartridge ROM. Reads outside of the ROM
+		// return 0xFF.
 		//
-		// Allowed values for address range from 0x0000 to 0x3FFF (both bounds inclusive)
+		// Allowed values for address range from 0x0000 to 0x7FFF (both bounds inclusive)
 		ReadROM(address uint16) byte
 
+		// ROMBank returns the number of the 16 KiB ROM bank mapped to the given address, e.g. for breakpoints in
+		// banked code. MBC6 reports its banks of 8 KiB instead, or -1 if flash memory is mapped.
+		//
+		// Allowed values for address range from 0x0000 to 0x7FFF (both bounds inclusive)
+		ROMBank(address uint16) int
+
 		// HandleBanking enables RAM banking and changing ROM and RAM banks.
 		// This function is called when there is a write attempt to a ROM address (nice hack nintendo!)
 		//
@@ -36,89 +63,305 @@ type (
 		HandleBanking(address uint16, data byte)
 
 		// ReadRAM returns the data stored under the given address in the cartridge RAM used for persistently
-		// saving game data. Results in panic if RAM wasn't enabled before (see HandleBanking).
+		// saving game data. Returns 0xFF if RAM wasn't enabled before (see HandleBanking), isn't present or the
+		// address is out of range.
 		//
 		// Allowed values for address depend on the underlying cartridge type (which MBC is used).
 		// RAM always starts at 0x0000. Don't simply use the register ranges to access.
 		ReadRAM(address uint16) byte
 
 		// WriteRAM stores the given data under the given address in the cartridge RAM used for persistently
-		// saving game data. Results in panic if RAM wasn't enabled before (see HandleBanking).
+		// saving game data. The write is ignored if RAM wasn't enabled before (see HandleBanking), isn't present
+		// or the address is out of range.
 		//
 		// Allowed values for address depend on the underlying cartridge type (which MBC is used)
 		// RAM always starts at 0x0000. Don't simply use the register ranges to access.
 		WriteRAM(address uint16, data byte)
 
-		// Save persists the RAM data to survive turning off the emulator
+		// RAMBank returns the number of the 8 KiB RAM bank mapped to the given address like ReadRAM, e.g. for
+		// reporting watchpoints, or -1 if no RAM is accessible there (disabled, absent or registers mapped instead).
+		// MBC6 reports its banks of 4 KiB instead.
+		RAMBank(address uint16) int
+
+		// Tick advances the hardware of the cartridge (like the real time clock of MBC3) by one T-cycle.
+		Tick()
+
+		// Header returns the parsed cartridge header of the ROM image.
+		Header() Header
+
+		// Save persists the RAM data to survive turning off the emulator. The save file is only written if the
+		// RAM or the real time clock changed since it was last read or written.
 		Save()
+
+		// RAM returns the contents of the cartridge RAM, e.g. for persisting it as save game by the caller. For
+		// cartridges with a real time clock, the clock is appended in the footer format of BGB and VBA-M (MBC3)
+		// or SameBoy (HuC3).
+		RAM() []byte
+
+		// SaveState writes the banking registers and the RAM of the cartridge to the given state writer.
+		SaveState(s *util.StateWriter)
+
+		// LoadState restores the banking registers and the RAM of the cartridge from the given state reader.
+		LoadState(s *util.StateReader)
+	}
+
+	// Rumbler is implemented by cartridges with a rumble motor.
+	Rumbler interface {
+		// Rumble returns the share of T-cycles the motor was turned on since the last call, from 0 (off) to 1.
+		Rumble() float64
+	}
+
+	// Tilter is implemented by cartridges with an accelerometer.
+	Tilter interface {
+		// SetTilt sets the tilt of the cartridge on both axes from -1 to 1 (tilted by 90 degrees). Positive values
+		// mean tilted to the right and towards the player.
+		SetTilt(x, y float64)
+	}
+
+	// InfraredEndpoint is the device on the other side of the infrared port of a cartridge (like HuC1 and HuC3),
+	// e.g. another Game Boy or a toy. See WithInfraredEndpoint.
+	InfraredEndpoint interface {
+		// SetLED is called whenever the cartridge turns its infrared LED on or off.
+		SetLED(on bool)
+
+		// Light reports whether the infrared receiver of the cartridge currently sees light.
+		Light() bool
+	}
+
+	// noInfrared is the default infrared endpoint, nothing is ever sent or received
+	noInfrared struct{}
+
+	// saveGameRestorer is implemented by all cartridges through cartridgeCore. Cartridges which store more than
+	// the RAM in their save game (like the clock of MBC3) override restoreSaveGame.
+	saveGameRestorer interface {
+		restoreSaveGame(data []byte)
+	}
+
+	// Option configures the creation of a cartridge, see LoadCartridgeImage and NewCartridge
+	Option func(o *options)
+
+	options struct {
+		syncRTC     bool
+		infrared    InfraredEndpoint
+		camera      CameraSource
+		entry       string
+		saveBackups int
 	}
 
 	cartridgeCore struct {
 		rom          *[]byte
 		ram          []byte
+		header       Header
 		imagePath    string
 		saveGamePath string
+		saveBackups  int
+		persisted    []byte // contents of the save file as last read or written, see unstamped
+		backedUp     bool   // the save file of the previous session was turned into a backup
+		syncRTC      bool   // a real time clock catches up with the host when restoring a save game, see rtcFooter
+		getNow       nowProvider
 	}
 )
 
-func LoadCartridgeImage(imagePath string) Cartridge {
-	data, err := os.ReadFile(imagePath)
+// WithHostClockSync controls whether the real time clock of a cartridge is advanced by the time passed on the host
+// since the save game was written. Enabled by default. Disable it for reproducible runs, as the clock otherwise runs
+// in emulated time only.
+func WithHostClockSync(enabled bool) Option {
+	return func(o *options) {
+		o.syncRTC = enabled
+	}
+}
+
+// WithInfraredEndpoint connects the infrared port of a cartridge to the given endpoint. By default, the port never
+// receives any light.
+func WithInfraredEndpoint(endpoint InfraredEndpoint) Option {
+	return func(o *options) {
+		o.infrared = endpoint
+	}
+}
+
+// WithCameraSource sets the source of the pictures taken with the Game Boy Camera, see LoadCameraImages. By default,
+// the lens is covered and all pictures are black.
+func WithCameraSource(source CameraSource) Option {
+	return func(o *options) {
+		o.camera = source
+	}
+}
+
+// WithArchiveEntry selects the ROM image loaded from a zip archive by the name of its entry. By default, the first
+// .gb or .gbc entry is loaded.
+func WithArchiveEntry(name string) Option {
+	return func(o *options) {
+		o.entry = name
+	}
+}
+
+// LoadCartridgeImage creates a cartridge from the ROM image at the given path, which may be compressed, see
+// ReadImage. A patch next to the image is applied, see PatchImage. The RAM is backed by a save file next to the
+// image or archive.
+func LoadCartridgeImage(imagePath string, opts ...Option) (Cartridge, error) {
+	o := options{saveBackups: defaultSaveBackups}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := ReadImage(imagePath, o.entry)
+	if err != nil {
+		return nil, err
+	}
+	data, err = PatchImage(imagePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	core, err := newCartridgeCore(data)
+	if err != nil {
+		return nil, err
+	}
+	core.imagePath = imagePath
+	core.saveGamePath = saveGamePath(imagePath)
+	core.saveBackups = o.saveBackups
+
+	return createCartridge(core, opts)
+}
+
+// NewCartridge creates a cartridge from a ROM image in memory. The RAM is initialized with the given save
+// game, which may be nil. Such a cartridge isn't backed by a save file, use RAM to persist the save game.
+func NewCartridge(rom []byte, ram []byte, opts ...Option) (Cartridge, error) {
+	data := make([]byte, len(rom))
+	copy(data, rom)
+
+	core, err := newCartridgeCore(data)
+	if err != nil {
+		return nil, err
+	}
 
+	cart, err := createCartridge(core, opts)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
+	cart.(saveGameRestorer).restoreSaveGame(ram)
+	return cart, nil
+}
 
-	core := &cartridgeCore{
-		rom:          &data,
-		ram:          make([]byte, ramSizes[data[0x149]]),
-		imagePath:    imagePath,
-		saveGamePath: strings.TrimSuffix(imagePath, filepath.Ext(imagePath)) + ".sgo",
+// newCartridgeCore validates the size of the image and the parts of the header required for creating a cartridge
+func newCartridgeCore(data []byte) (*cartridgeCore, error) {
+	header, err := ParseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < header.ROMSize {
+		return nil, fmt.Errorf("%w: %d bytes instead of %d", ErrTruncatedROM, len(data), header.ROMSize)
+	}
+	if len(data)&(len(data)-1) != 0 { // the mappers mask ROM addresses with the size
+		return nil, fmt.Errorf("%w: %d bytes", ErrROMSize, len(data))
 	}
 
-	return createCartridge(core)
+	return &cartridgeCore{
+		rom:    &data,
+		ram:    make([]byte, header.RAMSize),
+		header: header,
+		getNow: time.Now,
+	}, nil
 }
 
-func createCartridge(core *cartridgeCore) Cartridge {
-	switch (*core.rom)[0x147] {
+func createCartridge(core *cartridgeCore, opts []Option) (Cartridge, error) {
+	o := options{syncRTC: true, infrared: noInfrared{}, camera: darkCamera{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	core.syncRTC = o.syncRTC
+
+	if menu, ok := mmm01MenuHeader(*core.rom); ok {
+		core.header = menu
+		core.ram = make([]byte, menu.RAMSize)
+	}
+
+	switch core.header.Type {
 	case 0x00, 0x08, 0x09:
-		return newNoMBC(core)
+		return newNoMBC(core), nil
 	case 0x01, 0x02, 0x03:
-		return newMBC1(core)
+		return newMBC1(core), nil
 	case 0x05, 0x06:
-		return newMBC2(core)
+		return newMBC2(core), nil
+	case 0x0B, 0x0C, 0x0D:
+		return newMMM01(core), nil
 	case 0x0F, 0x10, 0x11:
-		return newMBC3(core, time.Now)
+		return newMBC3(core), nil
 	case 0x12, 0x13:
-		return newMBC3(core, time.Now)
+		return newMBC3(core), nil
 	case 0x19, 0x1A, 0x1B:
-		return newMBC5(core)
+		return newMBC5(core), nil
 	case 0x1C, 0x1D, 0x1E:
-		return newMBC5(core)
+		return newMBC5(core), nil
+	case 0x20:
+		return newMBC6(core), nil
+	case 0x22:
+		return newMBC7(core), nil
+	case 0xFC:
+		return newPocketCamera(core, o.camera), nil
+	case 0xFD:
+		return newTAMA5(core), nil
+	case 0xFE:
+		return newHuC3(core, o.infrared), nil
+	case 0xFF:
+		return newHuC1(core, o.infrared), nil
 	default:
-		log.L().Panic("Required MBC not implemented", log.String("value", fmt.Sprintf("0x%02X", (*core.rom)[0x147])))
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMBC, core.header.TypeName())
 	}
-	return nil
+}
+
+func (noInfrared) SetLED(bool) {}
+
+func (noInfrared) Light() bool {
+	return false
 }
 
 func (c *cartridgeCore) Save() {
-	// If RAM is completely empty (= all zeroes) don't save
-	if util.IsEmpty(c.ram) {
-		return
-	}
-	err := os.WriteFile(c.saveGamePath, c.ram, 0644)
-	if err != nil {
-		log.L().Error("Error writing save file", log.Error(err))
-		return
-	}
+	c.persist(c)
+}
+
+func (c *cartridgeCore) Tick() {}
+
+// bankOf returns the 16 KiB bank of the given address in the ROM image after masking it to the size of the ROM
+func (c *cartridgeCore) bankOf(physicalAddress uint32) int {
+	return int(physicalAddress & uint32(len(*c.rom)-1) >> 14)
+}
+
+func (c *cartridgeCore) Header() Header {
+	return c.header
+}
+
+func (c *cartridgeCore) RAM() []byte {
+	ram := make([]byte, len(c.ram))
+	copy(ram, c.ram)
+	return ram
+}
+
+func (c *cartridgeCore) restoreSaveGame(data []byte) {
+	c.restoreRAM(data)
+}
+
+// restoreRAM replaces the RAM with the beginning of the save game. RAM not covered by a shorter save game is
+// cleared, so nothing of the previous save game is left behind.
+func (c *cartridgeCore) restoreRAM(data []byte) {
+	clear(c.ram)
+	copy(c.ram, data)
 }
 
 func (c *cartridgeCore) load() {
-	data, err := os.ReadFile(c.saveGamePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			log.L().Error("Error reading save file", log.Error(err))
-		}
-		return
+	c.loadSaveGame(c)
+}
+
+func (c *cartridgeCore) SaveState(s *util.StateWriter) {
+	s.WriteBytes(c.ram)
+}
+
+func (c *cartridgeCore) LoadState(s *util.StateReader) {
+	ram := s.ReadBytes(maxRAMSize)
+	if s.Err() == nil && len(ram) != len(c.ram) {
+		s.Fail(errStateRAMSize)
+	}
+	if s.Err() == nil {
+		c.ram = ram
 	}
-	c.ram = data
 }
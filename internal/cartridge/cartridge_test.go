@@ -2,48 +2,125 @@ package cartridge
 
 import (
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
-func TestLoadCartridge_noMBC(t *testing.T) {
+var rtcTestTime = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestCore creates a cartridge core for the given ROM image without validating its header
+func newTestCore(rom []byte, ramSize int) *cartridgeCore {
+	return &cartridgeCore{
+		rom: &rom,
+		ram: make([]byte, ramSize),
+	}
+}
+
+// newTestClockCartridge creates a cartridge of the given type with a real time clock, which reads the time of the
+// host from getNow. Syncing the clock with the host is enabled unless disabled by the options.
+func newTestClockCartridge(core *cartridgeCore, cartridgeType byte, getNow nowProvider, opts ...Option) Cartridge {
+	core.header.Type = cartridgeType
+	core.getNow = getNow
+	cartridge, err := createCartridge(core, opts)
+	if err != nil {
+		panic(err)
+	}
+	return cartridge
+}
+
+// newTestImage creates a ROM image with the given cartridge type, ROM size and RAM size in its header
+func newTestImage(cartridgeType byte, romSizeCode byte, ramSizeCode byte) []byte {
+	rom := make([]byte, 0x8000<<romSizeCode)
+	rom[0x147] = cartridgeType
+	rom[0x148] = romSizeCode
+	rom[0x149] = ramSizeCode
+	return rom
+}
+
+func TestNewCartridge_noMBC(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0x00, 0x00, 0x00)
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &noMBC{}, cartridge)
+	assert.Len(t, cartridge.RAM(), 0x2000)
+}
+
+func TestNewCartridge_mbc1(t *testing.T) {
 	// GIVEN
-	pathToImage := "../../roms/Tetris.gb"
+	rom := newTestImage(0x03, 0x02, 0x03)
 
 	// WHEN
-	cartridge := LoadCartridgeImage(pathToImage)
+	cartridge, err := NewCartridge(rom, []byte{0x42})
 
 	// THEN
-	assert.IsType(t, &noMBC{
-		rom: nil,
-		ram: [8192]byte{},
-	}, cartridge)
+	require.NoError(t, err)
+	assert.IsType(t, &mbc1{}, cartridge)
+	assert.Len(t, cartridge.RAM(), 0x8000)
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0])
 }
 
-func TestLoadCartridge_mbc1(t *testing.T) {
+func TestNewCartridge_mbc2(t *testing.T) {
 	// GIVEN
-	pathToImage := "../../roms/Super Mario Land.gb"
+	rom := newTestImage(0x06, 0x01, 0x00)
 
 	// WHEN
-	cartridge := LoadCartridgeImage(pathToImage)
+	cartridge, err := NewCartridge(rom, nil)
 
 	// THEN
-	assert.IsType(t, &mbc1{
-		rom: nil,
-	}, cartridge)
+	require.NoError(t, err)
+	assert.IsType(t, &mbc2{}, cartridge)
+	assert.Len(t, cartridge.RAM(), 0x200)
 }
 
-func TestLoadCartridge_mbc2(t *testing.T) {
+func TestNewCartridge_mbc5(t *testing.T) {
 	// GIVEN
-	pathToImage := "../../roms/Kirbys Pinball Land.gb"
+	rom := newTestImage(0x1B, 0x03, 0x04)
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &mbc5{}, cartridge)
+	assert.Len(t, cartridge.RAM(), 0x20000)
+}
+
+func TestNewCartridge_invalidImage(t *testing.T) {
+	// GIVEN
+	unsupportedMBC := newTestImage(0x04, 0x00, 0x00)
+	badROMSize := newTestImage(0x00, 0x00, 0x00)
+	badROMSize[0x148] = 0x52
+	badRAMSize := newTestImage(0x00, 0x00, 0x06)
+	truncated := newTestImage(0x01, 0x02, 0x00)[:0x10000]
+	overdumped := append(newTestImage(0x01, 0x02, 0x00), make([]byte, 0x8000)...)
+
+	// WHEN
+	_, errMBC := NewCartridge(unsupportedMBC, nil)
+	_, errROMSize := NewCartridge(badROMSize, nil)
+	_, errRAMSize := NewCartridge(badRAMSize, nil)
+	_, errTruncated := NewCartridge(truncated, nil)
+	_, errOverdumped := NewCartridge(overdumped, nil)
+	_, errHeader := NewCartridge(make([]byte, 0x100), nil)
+
+	// THEN
+	assert.ErrorIs(t, errMBC, ErrUnsupportedMBC)
+	assert.ErrorIs(t, errROMSize, ErrBadHeader)
+	assert.ErrorIs(t, errRAMSize, ErrBadHeader)
+	assert.ErrorIs(t, errTruncated, ErrTruncatedROM)
+	assert.ErrorIs(t, errOverdumped, ErrROMSize)
+	assert.ErrorIs(t, errHeader, ErrTruncatedROM)
+}
 
+func TestLoadCartridgeImage_missingFile(t *testing.T) {
 	// WHEN
-	cartridge := LoadCartridgeImage(pathToImage)
+	_, err := LoadCartridgeImage(t.TempDir() + "/missing.gb")
 
 	// THEN
-	assert.IsType(t, &mbc2{
-		rom:            nil,
-		ram:            [512]byte{},
-		currentROMBank: 0,
-		ramEnabled:     false,
-	}, cartridge)
+	assert.Error(t, err)
 }
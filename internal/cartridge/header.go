@@ -0,0 +1,246 @@
+package cartridge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Layout of the cartridge header
+//
+// Source: https://gbdev.io/pandocs/The_Cartridge_Header.html
+const (
+	logoAddress           = 0x104
+	titleAddress          = 0x134
+	manufacturerAddress   = 0x13F
+	cgbFlagAddress        = 0x143
+	newLicenseeAddress    = 0x144
+	sgbFlagAddress        = 0x146
+	typeAddress           = 0x147
+	romSizeAddress        = 0x148
+	ramSizeAddress        = 0x149
+	destinationAddress    = 0x14A
+	oldLicenseeAddress    = 0x14B
+	versionAddress        = 0x14C
+	headerChecksumAddress = 0x14D
+	globalChecksumAddress = 0x14E
+
+	// Old licensee code indicating that the new licensee code is used instead
+	useNewLicensee = 0x33
+)
+
+// CGB flags
+const (
+	CGBSupported = 0x80 // game supports CGB enhancements, but is backwards compatible
+	CGBOnly      = 0xC0 // game works on CGB only
+)
+
+// Destination codes
+const (
+	DestinationJapan    = 0x00
+	DestinationOverseas = 0x01
+)
+
+var (
+	ErrBadLogo           = errors.New("nintendo logo mismatch")
+	ErrBadHeaderChecksum = errors.New("header checksum mismatch")
+	ErrBadGlobalChecksum = errors.New("global checksum mismatch")
+)
+
+// The boot ROM refuses to start a game if its header doesn't contain this bitmap
+var nintendoLogo = [48]byte{
+	0xCE, 0xED, 0x66, 0x66, 0xCC, 0x0D, 0x00, 0x0B, 0x03, 0x73, 0x00, 0x83, 0x00, 0x0C, 0x00, 0x0D,
+	0x00, 0x08, 0x11, 0x1F, 0x88, 0x89, 0x00, 0x0E, 0xDC, 0xCC, 0x6E, 0xE6, 0xDD, 0xDD, 0xD9, 0x99,
+	0xBB, 0xBB, 0x67, 0x63, 0x6E, 0x0E, 0xEC, 0xCC, 0xDD, 0xDC, 0x99, 0x9F, 0xBB, 0xB9, 0x33, 0x3E,
+}
+
+var typeNames = map[byte]string{
+	0x00: "ROM ONLY",
+	0x01: "MBC1",
+	0x02: "MBC1+RAM",
+	0x03: "MBC1+RAM+BATTERY",
+	0x05: "MBC2",
+	0x06: "MBC2+BATTERY",
+	0x08: "ROM+RAM",
+	0x09: "ROM+RAM+BATTERY",
+	0x0B: "MMM01",
+	0x0C: "MMM01+RAM",
+	0x0D: "MMM01+RAM+BATTERY",
+	0x0F: "MBC3+TIMER+BATTERY",
+	0x10: "MBC3+TIMER+RAM+BATTERY",
+	0x11: "MBC3",
+	0x12: "MBC3+RAM",
+	0x13: "MBC3+RAM+BATTERY",
+	0x19: "MBC5",
+	0x1A: "MBC5+RAM",
+	0x1B: "MBC5+RAM+BATTERY",
+	0x1C: "MBC5+RUMBLE",
+	0x1D: "MBC5+RUMBLE+RAM",
+	0x1E: "MBC5+RUMBLE+RAM+BATTERY",
+	0x20: "MBC6",
+	0x22: "MBC7+SENSOR+RUMBLE+RAM+BATTERY",
+	0xFC: "POCKET CAMERA",
+	0xFD: "BANDAI TAMA5",
+	0xFE: "HuC3",
+	0xFF: "HuC1+RAM+BATTERY",
+}
+
+// Header holds the information stored in the cartridge header (0x0100 to 0x014F) of a ROM image.
+type Header struct {
+	// Title of the game in upper case ASCII
+	Title string
+
+	// Manufacturer code of newer cartridges, empty if not present
+	ManufacturerCode string
+
+	// CGBFlag is either CGBSupported, CGBOnly or any other value for games without CGB support
+	CGBFlag byte
+
+	// SGBSupported is set if the game supports Super Game Boy functions
+	SGBSupported bool
+
+	// Licensee (publisher) of the game, NewLicenseeCode is only used if OldLicenseeCode is 0x33
+	OldLicenseeCode byte
+	NewLicenseeCode string
+
+	// Type of the cartridge hardware, see TypeName
+	Type byte
+
+	// Sizes in bytes decoded from the header
+	ROMSize int
+	RAMSize int
+
+	// Destination is either DestinationJapan or DestinationOverseas
+	Destination byte
+
+	// Version number of the game, usually 0x00
+	Version byte
+
+	// Checksums as stored in the header
+	HeaderChecksum byte
+	GlobalChecksum uint16
+
+	// Results of the verification of the image, see Verify
+	LogoValid           bool
+	HeaderChecksumValid bool
+	GlobalChecksumValid bool
+}
+
+// ParseHeader reads the cartridge header of the given ROM image. The logo and checksums are verified against the
+// image, but a mismatch isn't treated as error (see Verify). Invalid ROM or RAM sizes are reported as ErrBadHeader.
+func ParseHeader(rom []byte) (Header, error) {
+	if len(rom) < headerEnd {
+		return Header{}, fmt.Errorf("%w: %d bytes is smaller than the header", ErrTruncatedROM, len(rom))
+	}
+
+	romSizeCode := rom[romSizeAddress]
+	if romSizeCode > maxROMSizeCode {
+		return Header{}, fmt.Errorf("%w: invalid ROM size 0x%02X", ErrBadHeader, romSizeCode)
+	}
+	ramSizeCode := rom[ramSizeAddress]
+	if int(ramSizeCode) >= len(ramSizes) {
+		return Header{}, fmt.Errorf("%w: invalid RAM size 0x%02X", ErrBadHeader, ramSizeCode)
+	}
+
+	h := Header{
+		CGBFlag:         rom[cgbFlagAddress],
+		SGBSupported:    rom[sgbFlagAddress] == 0x03,
+		OldLicenseeCode: rom[oldLicenseeAddress],
+		Type:            rom[typeAddress],
+		ROMSize:         0x8000 << romSizeCode,
+		RAMSize:         ramSizes[ramSizeCode],
+		Destination:     rom[destinationAddress],
+		Version:         rom[versionAddress],
+		HeaderChecksum:  rom[headerChecksumAddress],
+		GlobalChecksum:  uint16(rom[globalChecksumAddress])<<8 | uint16(rom[globalChecksumAddress+1]),
+	}
+
+	// Newer cartridges use the end of the title area for the manufacturer code and the CGB flag
+	titleEnd := cgbFlagAddress + 1
+	if h.CGBFlag&0x80 != 0 {
+		titleEnd = cgbFlagAddress
+		if code := rom[manufacturerAddress:cgbFlagAddress]; isManufacturerCode(code) {
+			titleEnd = manufacturerAddress
+			h.ManufacturerCode = string(code)
+		}
+	}
+	h.Title = asciiString(rom[titleAddress:titleEnd])
+
+	if h.OldLicenseeCode == useNewLicensee {
+		h.NewLicenseeCode = asciiString(rom[newLicenseeAddress:sgbFlagAddress])
+	}
+
+	h.LogoValid = bytes.Equal(rom[logoAddress:logoAddress+len(nintendoLogo)], nintendoLogo[:])
+	h.HeaderChecksumValid = headerChecksum(rom) == h.HeaderChecksum
+	h.GlobalChecksumValid = globalChecksum(rom) == h.GlobalChecksum
+
+	return h, nil
+}
+
+// TypeName returns the name of the cartridge hardware as listed in the Pan Docs.
+func (h Header) TypeName() string {
+	if name, ok := typeNames[h.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("UNKNOWN (0x%02X)", h.Type)
+}
+
+// Verify returns an error wrapping ErrBadLogo, ErrBadHeaderChecksum and/or ErrBadGlobalChecksum if the image is
+// corrupt. Real hardware refuses to start games with a bad logo or header checksum, the global checksum is ignored.
+func (h Header) Verify() error {
+	var errs []error
+	if !h.LogoValid {
+		errs = append(errs, ErrBadLogo)
+	}
+	if !h.HeaderChecksumValid {
+		errs = append(errs, ErrBadHeaderChecksum)
+	}
+	if !h.GlobalChecksumValid {
+		errs = append(errs, ErrBadGlobalChecksum)
+	}
+	return errors.Join(errs...)
+}
+
+// headerChecksum computes the checksum over the header bytes from 0x0134 to 0x014C, which is checked by the boot ROM
+func headerChecksum(rom []byte) byte {
+	var checksum byte
+	for _, b := range rom[titleAddress:headerChecksumAddress] {
+		checksum = checksum - b - 1
+	}
+	return checksum
+}
+
+// globalChecksum computes the sum of all bytes of the image, except the global checksum itself
+func globalChecksum(rom []byte) uint16 {
+	var checksum uint16
+	for i, b := range rom {
+		if i != globalChecksumAddress && i != globalChecksumAddress+1 {
+			checksum += uint16(b)
+		}
+	}
+	return checksum
+}
+
+// isManufacturerCode checks whether the given bytes consist of upper case letters and digits only
+func isManufacturerCode(code []byte) bool {
+	for _, c := range code {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// asciiString converts the zero padded bytes to a string, replacing non-printable characters
+func asciiString(data []byte) string {
+	if end := bytes.IndexByte(data, 0x00); end >= 0 {
+		data = data[:end]
+	}
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7E {
+			return '?'
+		}
+		return r
+	}, string(data)))
+}
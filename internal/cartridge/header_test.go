@@ -0,0 +1,123 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// newValidImage creates a ROM image with the given title area, the Nintendo logo and correct checksums
+func newValidImage(titleArea string) []byte {
+	rom := newTestImage(0x13, 0x01, 0x03)
+	copy(rom[logoAddress:], nintendoLogo[:])
+	copy(rom[titleAddress:], titleArea)
+	rom[destinationAddress] = DestinationOverseas
+	rom[oldLicenseeAddress] = 0x01
+	rom[versionAddress] = 0x02
+	rom[0x4000] = 0xAB // something to sum up in the second bank
+
+	rom[headerChecksumAddress] = headerChecksum(rom)
+	checksum := globalChecksum(rom)
+	rom[globalChecksumAddress] = byte(checksum >> 8)
+	rom[globalChecksumAddress+1] = byte(checksum)
+	return rom
+}
+
+func TestParseHeader(t *testing.T) {
+	// GIVEN
+	rom := newValidImage("POKEMON RED")
+
+	// WHEN
+	header, err := ParseHeader(rom)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "POKEMON RED", header.Title)
+	assert.Empty(t, header.ManufacturerCode)
+	assert.Equal(t, byte(0x13), header.Type)
+	assert.Equal(t, "MBC3+RAM+BATTERY", header.TypeName())
+	assert.Equal(t, 0x10000, header.ROMSize)
+	assert.Equal(t, 0x8000, header.RAMSize)
+	assert.Equal(t, byte(DestinationOverseas), header.Destination)
+	assert.Equal(t, byte(0x01), header.OldLicenseeCode)
+	assert.Empty(t, header.NewLicenseeCode)
+	assert.Equal(t, byte(0x02), header.Version)
+	assert.False(t, header.SGBSupported)
+	assert.NoError(t, header.Verify())
+}
+
+func TestParseHeader_newCartridge(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0x1B, 0x00, 0x00)
+	copy(rom[titleAddress:], "ZELDA\x00\x00\x00\x00\x00\x00AZ7E\x80")
+	copy(rom[newLicenseeAddress:], "01")
+	rom[sgbFlagAddress] = 0x03
+	rom[oldLicenseeAddress] = useNewLicensee
+
+	// WHEN
+	header, err := ParseHeader(rom)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "ZELDA", header.Title)
+	assert.Equal(t, "AZ7E", header.ManufacturerCode)
+	assert.Equal(t, byte(CGBSupported), header.CGBFlag)
+	assert.Equal(t, "01", header.NewLicenseeCode)
+	assert.True(t, header.SGBSupported)
+}
+
+func TestParseHeader_corruptImage(t *testing.T) {
+	// GIVEN
+	rom := newValidImage("TETRIS")
+	rom[logoAddress] = 0x00
+	rom[titleAddress] = 'X'
+
+	// WHEN
+	header, err := ParseHeader(rom)
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, header.LogoValid)
+	assert.False(t, header.HeaderChecksumValid)
+	assert.False(t, header.GlobalChecksumValid)
+	assert.ErrorIs(t, header.Verify(), ErrBadLogo)
+	assert.ErrorIs(t, header.Verify(), ErrBadHeaderChecksum)
+	assert.ErrorIs(t, header.Verify(), ErrBadGlobalChecksum)
+}
+
+func TestParseHeader_invalid(t *testing.T) {
+	// GIVEN
+	badRAMSize := newTestImage(0x00, 0x00, 0x00)
+	badRAMSize[ramSizeAddress] = 0x10
+
+	// WHEN
+	_, errTruncated := ParseHeader(make([]byte, 0x14F))
+	_, errRAMSize := ParseHeader(badRAMSize)
+
+	// THEN
+	assert.ErrorIs(t, errTruncated, ErrTruncatedROM)
+	assert.ErrorIs(t, errRAMSize, ErrBadHeader)
+}
+
+func TestHeader_TypeName_unknown(t *testing.T) {
+	// GIVEN
+	header := Header{Type: 0x42}
+
+	// WHEN
+	result := header.TypeName()
+
+	// THEN
+	assert.Equal(t, "UNKNOWN (0x42)", result)
+}
+
+func TestCartridge_Header(t *testing.T) {
+	// GIVEN
+	rom := newValidImage("TETRIS")
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "TETRIS", cartridge.Header().Title)
+}
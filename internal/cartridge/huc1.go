@@ -0,0 +1,129 @@
+package cartridge
+
+import "gameboy-emulator/internal/util"
+
+// HuC1 by Hudson Soft supports up to 1 MiB ROM and 32 KiB of banked RAM. Besides RAM, an infrared port can be mapped
+// into the range A000–BFFF by writing 0x0E to 0000–1FFF. Any other value maps RAM, which has no separate enable.
+//
+// Source: https://gbdev.io/pandocs/HuC1.html
+type huc1 struct {
+	*cartridgeCore
+
+	romb   byte
+	ramb   byte
+	ramOn  bool // any value other than 0x0E was written to 0000–1FFF
+	irMode bool
+
+	infrared InfraredEndpoint
+}
+
+func newHuC1(core *cartridgeCore, infrared InfraredEndpoint) Cartridge {
+	mbc := &huc1{
+		cartridgeCore: core,
+		romb:          1,
+		infrared:      infrared,
+	}
+	mbc.load()
+	return mbc
+}
+
+func (mbc *huc1) Reset() {
+	mbc.romb = 1
+	mbc.ramb = 0
+	mbc.ramOn = false
+	mbc.irMode = false
+}
+
+func (mbc *huc1) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		physicalAddress := (uint32(mbc.romb)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *huc1) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.romb) << 14)
+}
+
+func (mbc *huc1) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x2000: // RAM or infrared port
+		mbc.irMode = data == 0x0E
+		mbc.ramOn = !mbc.irMode
+
+	case address < 0x4000: // ROM bank, unlike MBC1 bank 0 can be selected
+		mbc.romb = data & 0x3F
+
+	case address < 0x6000: // RAM bank
+		mbc.ramb = data & 0x03
+	}
+}
+
+func (mbc *huc1) WriteRAM(address uint16, data byte) {
+	if address >= 0x2000 {
+		return // outside of the RAM area
+	}
+
+	if mbc.irMode {
+		mbc.infrared.SetLED(util.BitIsSet8(data, 0))
+		return
+	}
+
+	if !mbc.ramOn || len(mbc.ram) == 0 {
+		return
+	}
+	mbc.ram[mbc.physicalRAMAddress(address)] = data
+}
+
+func (mbc *huc1) RAMBank(address uint16) int {
+	if address >= 0x2000 || mbc.irMode || !mbc.ramOn || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.physicalRAMAddress(address) >> 13)
+}
+
+func (mbc *huc1) ReadRAM(address uint16) byte {
+	if address >= 0x2000 {
+		return 0xFF // outside of the RAM area
+	}
+
+	if mbc.irMode {
+		return readInfrared(mbc.infrared)
+	}
+
+	if !mbc.ramOn || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+	return mbc.ram[mbc.physicalRAMAddress(address)]
+}
+
+func (mbc *huc1) physicalRAMAddress(address uint16) uint32 {
+	return (uint32(mbc.ramb)<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
+}
+
+// readInfrared returns the value of the infrared register of the Hudson mappers: bit 0 is set while light is
+// received, the unused upper bits read 0xC0.
+func readInfrared(infrared InfraredEndpoint) byte {
+	if infrared.Light() {
+		return 0xC1
+	}
+	return 0xC0
+}
+
+func (mbc *huc1) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb, mbc.ramb, mbc.ramOn, mbc.irMode)
+}
+
+func (mbc *huc1) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb, &mbc.ramb, &mbc.ramOn, &mbc.irMode)
+}
@@ -0,0 +1,82 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// testInfrared is an infrared endpoint recording the state of the LED
+type testInfrared struct {
+	led   bool
+	light bool
+}
+
+func (i *testInfrared) SetLED(on bool) {
+	i.led = on
+}
+
+func (i *testInfrared) Light() bool {
+	return i.light
+}
+
+func TestHuC1ReadROM_bank(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x100000)
+	rom[0x3F*0x4000+0x0123] = 0x42
+	rom[0x0123] = 0x24
+	cartridge := newHuC1(newTestCore(rom, 0x8000), noInfrared{})
+
+	// WHEN
+	cartridge.HandleBanking(0x2000, 0xFF)
+	high := cartridge.ReadROM(0x4123)
+	cartridge.HandleBanking(0x2000, 0x00)
+	zero := cartridge.ReadROM(0x4123)
+
+	// THEN
+	assert.Equal(t, byte(0x42), high)
+	assert.Equal(t, byte(0x24), zero) // bank 0 isn't mapped to bank 1
+}
+
+func TestHuC1RAM_banked(t *testing.T) {
+	// GIVEN
+	cartridge := newHuC1(newTestCore(make([]byte, 0x8000), 0x8000), noInfrared{})
+	cartridge.HandleBanking(0x0000, 0x0A)
+
+	// WHEN
+	cartridge.HandleBanking(0x4000, 0x02)
+	cartridge.WriteRAM(0x0010, 0x42)
+
+	// THEN
+	assert.Equal(t, byte(0x42), cartridge.ReadRAM(0x0010))
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0x4010])
+}
+
+func TestHuC1ReadRAM_notSelected(t *testing.T) {
+	// GIVEN
+	cartridge := newHuC1(newTestCore(make([]byte, 0x8000), 0x8000), noInfrared{})
+
+	// WHEN
+	result := cartridge.ReadRAM(0x0000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
+}
+
+func TestHuC1Infrared(t *testing.T) {
+	// GIVEN
+	infrared := &testInfrared{}
+	cartridge := newHuC1(newTestCore(make([]byte, 0x8000), 0x8000), infrared)
+	cartridge.HandleBanking(0x0000, 0x0E)
+
+	// WHEN
+	cartridge.WriteRAM(0x0000, 0x01)
+	dark := cartridge.ReadRAM(0x0000)
+	infrared.light = true
+	light := cartridge.ReadRAM(0x0000)
+
+	// THEN
+	assert.True(t, infrared.led)
+	assert.Equal(t, byte(0xC0), dark)
+	assert.Equal(t, byte(0xC1), light)
+	assert.Equal(t, make([]byte, 0x8000), cartridge.RAM())
+}
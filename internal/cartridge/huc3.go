@@ -0,0 +1,273 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"gameboy-emulator/internal/util"
+)
+
+// Modes of HuC3 selecting what is mapped into the range A000–BFFF
+const (
+	huc3ModeRAMReadOnly = 0x0
+	huc3ModeRAM         = 0xA
+	huc3ModeRTCCommand  = 0xB
+	huc3ModeRTCResponse = 0xC
+	huc3ModeRTCReady    = 0xD
+	huc3ModeInfrared    = 0xE
+)
+
+// Addresses of the nibbles in the memory of the HuC3 clock chip. The time is stored as minute of the day (3 nibbles)
+// followed by the day counter (4 nibbles), lowest nibble first.
+const (
+	huc3LatchedTime  = 0x00 // current time copied by command 0x60
+	huc3NewTime      = 0x10 // time set by command 0x61
+	huc3AlarmTime    = 0x58
+	huc3AlarmEnabled = 0x5F
+)
+
+const minutesPerDay = 24 * 60
+
+// Size of the clock footer appended to the save game as written by SameBoy: a 64-bit UNIX timestamp, the minute of
+// the day, the day counter, the alarm minute and day as 16-bit values and the alarm enable flag.
+const huc3FooterSize = 17
+
+// HuC3 by Hudson Soft supports up to 2 MiB ROM and 32 KiB of banked RAM. It comes with an infrared port and a clock
+// chip with alarm, which are mapped into the range A000–BFFF depending on the mode written to 0000–1FFF.
+//
+// The clock is accessed by writing commands in mode 0xB and reading their responses in mode 0xC. Its memory is
+// organized as 256 nibbles with an address register. The clock counts minutes and days and, like the clock of
+// MBC3, runs in emulated time (see Tick).
+//
+// Source: https://gbdev.io/pandocs/HuC3.html
+type huc3 struct {
+	*cartridgeCore
+
+	romb byte
+	ramb byte
+	mode byte
+
+	rtcMemory  [0x100]byte // nibbles
+	rtcAddress byte
+	response   byte // last command with its result in the lower nibble
+
+	minutes   uint16 // minute of the day
+	days      uint16
+	rtcCycles uint32 // T-cycles since the last increment of the minutes
+
+	infrared InfraredEndpoint
+}
+
+func newHuC3(core *cartridgeCore, infrared InfraredEndpoint) Cartridge {
+	mbc := &huc3{
+		cartridgeCore: core,
+		romb:          1,
+		infrared:      infrared,
+	}
+	mbc.load()
+	return mbc
+}
+
+func (mbc *huc3) Reset() {
+	mbc.romb = 1
+	mbc.ramb = 0
+	mbc.mode = huc3ModeRAMReadOnly
+}
+
+func (mbc *huc3) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		physicalAddress := (uint32(mbc.romb)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *huc3) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.romb) << 14)
+}
+
+func (mbc *huc3) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x2000: // select what is mapped into the RAM area
+		mbc.mode = data & 0x0F
+
+	case address < 0x4000: // ROM bank
+		mbc.romb = data & 0x7F
+
+	case address < 0x6000: // RAM bank
+		mbc.ramb = data & 0x03
+	}
+}
+
+func (mbc *huc3) WriteRAM(address uint16, data byte) {
+	if address >= 0x2000 {
+		return // outside of the RAM area
+	}
+
+	switch mbc.mode {
+	case huc3ModeRAM:
+		if len(mbc.ram) > 0 {
+			mbc.ram[mbc.physicalRAMAddress(address)] = data
+		}
+	case huc3ModeRTCCommand:
+		mbc.executeCommand(data>>4&0x07, data&0x0F)
+	case huc3ModeInfrared:
+		mbc.infrared.SetLED(util.BitIsSet8(data, 0))
+	}
+}
+
+func (mbc *huc3) RAMBank(address uint16) int {
+	if address >= 0x2000 || (mbc.mode != huc3ModeRAMReadOnly && mbc.mode != huc3ModeRAM) || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.physicalRAMAddress(address) >> 13)
+}
+
+func (mbc *huc3) ReadRAM(address uint16) byte {
+	if address >= 0x2000 {
+		return 0xFF // outside of the RAM area
+	}
+
+	switch mbc.mode {
+	case huc3ModeRAMReadOnly, huc3ModeRAM:
+		if len(mbc.ram) > 0 {
+			return mbc.ram[mbc.physicalRAMAddress(address)]
+		}
+	case huc3ModeRTCResponse:
+		return 0x80 | mbc.response
+	case huc3ModeRTCReady: // commands are executed immediately
+		return 0x01
+	case huc3ModeInfrared:
+		return readInfrared(mbc.infrared)
+	}
+	return 0xFF
+}
+
+func (mbc *huc3) physicalRAMAddress(address uint16) uint32 {
+	return (uint32(mbc.ramb)<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
+}
+
+// executeCommand executes a command of the clock chip with its 4-bit argument
+func (mbc *huc3) executeCommand(command byte, argument byte) {
+	mbc.response = command << 4
+
+	switch command {
+	case 0x1: // read nibble and increment the address
+		mbc.response |= mbc.rtcMemory[mbc.rtcAddress] & 0x0F
+		mbc.rtcAddress++
+	case 0x2: // write nibble
+		mbc.rtcMemory[mbc.rtcAddress] = argument
+	case 0x3: // write nibble and increment the address
+		mbc.rtcMemory[mbc.rtcAddress] = argument
+		mbc.rtcAddress++
+	case 0x4: // lower nibble of the address
+		mbc.rtcAddress = mbc.rtcAddress&0xF0 | argument
+	case 0x5: // upper nibble of the address
+		mbc.rtcAddress = mbc.rtcAddress&0x0F | argument<<4
+	case 0x6:
+		switch argument {
+		case 0x0: // copy the current time into memory
+			mbc.writeNibbles(huc3LatchedTime, 3, mbc.minutes)
+			mbc.writeNibbles(huc3LatchedTime+3, 4, mbc.days)
+		case 0x1: // set the time from memory
+			mbc.minutes = mbc.readNibbles(huc3NewTime, 3) % minutesPerDay
+			mbc.days = mbc.readNibbles(huc3NewTime+3, 4)
+			mbc.rtcCycles = 0
+		case 0x2: // status
+			mbc.response |= 0x01
+		}
+	}
+}
+
+func (mbc *huc3) writeNibbles(address byte, count int, value uint16) {
+	for i := 0; i < count; i++ {
+		mbc.rtcMemory[address+byte(i)] = byte(value>>(4*i)) & 0x0F
+	}
+}
+
+func (mbc *huc3) readNibbles(address byte, count int) uint16 {
+	var value uint16
+	for i := 0; i < count; i++ {
+		value |= uint16(mbc.rtcMemory[address+byte(i)]) << (4 * i)
+	}
+	return value
+}
+
+// Tick counts the T-cycles of a minute, the smallest unit of the clock
+func (mbc *huc3) Tick() {
+	if mbc.rtcCycles++; mbc.rtcCycles >= 60*rtcCyclesPerSecond {
+		mbc.rtcCycles = 0
+		mbc.advance(1)
+	}
+}
+
+// advance moves the clock forward by the given number of minutes
+func (mbc *huc3) advance(minutes int64) {
+	total := int64(mbc.minutes) + minutes
+	mbc.minutes = uint16(total % minutesPerDay)
+	mbc.days += uint16(total / minutesPerDay)
+}
+
+func (mbc *huc3) RAM() []byte {
+	return mbc.rtcSaveGame(mbc)
+}
+
+func (mbc *huc3) restoreSaveGame(data []byte) {
+	mbc.restoreRTCSaveGame(data, mbc)
+}
+
+// encodeRTC writes the time and the alarm in the layout of SameBoy, see huc3FooterSize
+func (mbc *huc3) encodeRTC(timestamp int64) []byte {
+	footer := make([]byte, huc3FooterSize)
+	binary.LittleEndian.PutUint64(footer[0:], uint64(timestamp))
+	binary.LittleEndian.PutUint16(footer[8:], mbc.minutes)
+	binary.LittleEndian.PutUint16(footer[10:], mbc.days)
+	binary.LittleEndian.PutUint16(footer[12:], mbc.readNibbles(huc3AlarmTime, 3))
+	binary.LittleEndian.PutUint16(footer[14:], mbc.readNibbles(huc3AlarmTime+3, 4))
+	footer[16] = mbc.rtcMemory[huc3AlarmEnabled] & 0x01
+	return footer
+}
+
+func (mbc *huc3) decodeRTC(footer []byte) (int64, bool) {
+	if len(footer) != huc3FooterSize {
+		return 0, false
+	}
+
+	mbc.minutes = binary.LittleEndian.Uint16(footer[8:]) % minutesPerDay
+	mbc.days = binary.LittleEndian.Uint16(footer[10:])
+	mbc.writeNibbles(huc3AlarmTime, 3, binary.LittleEndian.Uint16(footer[12:]))
+	mbc.writeNibbles(huc3AlarmTime+3, 4, binary.LittleEndian.Uint16(footer[14:]))
+	mbc.rtcMemory[huc3AlarmEnabled] = footer[16] & 0x01
+	mbc.rtcCycles = 0
+	return int64(binary.LittleEndian.Uint64(footer[0:])), true
+}
+
+// catchUp advances the clock by the full minutes of the given time
+func (mbc *huc3) catchUp(seconds int64) {
+	mbc.advance(seconds / 60)
+}
+
+func (mbc *huc3) Save() {
+	mbc.persist(mbc)
+}
+
+func (mbc *huc3) load() {
+	mbc.loadSaveGame(mbc)
+}
+
+func (mbc *huc3) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb, mbc.ramb, mbc.mode, mbc.rtcMemory[:], mbc.rtcAddress, mbc.response,
+		mbc.minutes, mbc.days, mbc.rtcCycles)
+}
+
+func (mbc *huc3) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb, &mbc.ramb, &mbc.mode, mbc.rtcMemory[:], &mbc.rtcAddress, &mbc.response,
+		&mbc.minutes, &mbc.days, &mbc.rtcCycles)
+}
@@ -0,0 +1,173 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// huc3Command executes a command of the clock and returns the response
+func huc3Command(cartridge Cartridge, command byte) byte {
+	cartridge.HandleBanking(0x0000, huc3ModeRTCCommand)
+	cartridge.WriteRAM(0x0000, command)
+	cartridge.HandleBanking(0x0000, huc3ModeRTCResponse)
+	return cartridge.ReadRAM(0x0000)
+}
+
+// readHuC3Time latches the clock and reads the minute of the day and the day counter through commands
+func readHuC3Time(cartridge Cartridge) (minutes uint16, days uint16) {
+	huc3Command(cartridge, 0x60)
+	huc3Command(cartridge, 0x40)
+	huc3Command(cartridge, 0x50)
+
+	var value uint32
+	for i := 0; i < 7; i++ {
+		value |= uint32(huc3Command(cartridge, 0x10)&0x0F) << (4 * i)
+	}
+	return uint16(value & 0xFFF), uint16(value >> 12)
+}
+
+// setHuC3Time sets the clock to the given minute of the day and day counter through commands
+func setHuC3Time(cartridge Cartridge, minutes uint16, days uint16) {
+	huc3Command(cartridge, 0x40)
+	huc3Command(cartridge, 0x51)
+
+	value := uint32(minutes) | uint32(days)<<12
+	for i := 0; i < 7; i++ {
+		huc3Command(cartridge, 0x30|byte(value>>(4*i))&0x0F)
+	}
+	huc3Command(cartridge, 0x61)
+}
+
+func TestHuC3RAM_modes(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0x8000), 0xFE, time.Now)
+	cartridge.HandleBanking(0x0000, huc3ModeRAM)
+	cartridge.HandleBanking(0x4000, 0x03)
+	cartridge.WriteRAM(0x0000, 0x42)
+
+	// WHEN
+	cartridge.HandleBanking(0x0000, huc3ModeRAMReadOnly)
+	cartridge.WriteRAM(0x0000, 0x24)
+	readOnly := cartridge.ReadRAM(0x0000)
+	cartridge.HandleBanking(0x0000, 0x05)
+	unmapped := cartridge.ReadRAM(0x0000)
+
+	// THEN
+	assert.Equal(t, byte(0x42), readOnly)
+	assert.Equal(t, byte(0xFF), unmapped)
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0x6000])
+}
+
+func TestHuC3Clock_setAndRead(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0x8000), 0xFE, time.Now)
+
+	// WHEN
+	setHuC3Time(cartridge, 754, 1234)
+	minutes, days := readHuC3Time(cartridge)
+
+	// THEN
+	assert.Equal(t, uint16(754), minutes)
+	assert.Equal(t, uint16(1234), days)
+}
+
+func TestHuC3Clock_status(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0x8000), 0xFE, time.Now)
+
+	// WHEN
+	response := huc3Command(cartridge, 0x62)
+	cartridge.HandleBanking(0x0000, huc3ModeRTCReady)
+
+	// THEN
+	assert.Equal(t, byte(0xE1), response)
+	assert.Equal(t, byte(0x01), cartridge.ReadRAM(0x0000))
+}
+
+func TestHuC3Tick(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0x8000), 0xFE, time.Now)
+	setHuC3Time(cartridge, minutesPerDay-1, 7)
+
+	// WHEN
+	for i := 0; i < 60*rtcCyclesPerSecond; i++ {
+		cartridge.Tick()
+	}
+	minutes, days := readHuC3Time(cartridge)
+
+	// THEN
+	assert.Equal(t, uint16(0), minutes)
+	assert.Equal(t, uint16(8), days)
+}
+
+func TestHuC3RestoreSaveGame_advancesClock(t *testing.T) {
+	// GIVEN
+	getNow := func() time.Time { return rtcTestTime }
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0x8000), 0xFE, getNow)
+
+	footer := make([]byte, huc3FooterSize)
+	binary.LittleEndian.PutUint64(footer[0:], uint64(rtcTestTime.Unix()-3*3600))
+	binary.LittleEndian.PutUint16(footer[8:], minutesPerDay-60)
+	binary.LittleEndian.PutUint16(footer[10:], 3)
+	binary.LittleEndian.PutUint16(footer[12:], 600)
+	footer[16] = 0x01
+
+	// WHEN
+	cartridge.(*huc3).restoreSaveGame(append(make([]byte, 0x8000), footer...))
+	minutes, days := readHuC3Time(cartridge)
+
+	// THEN
+	assert.Equal(t, uint16(120), minutes)
+	assert.Equal(t, uint16(4), days)
+	assert.Equal(t, footer[12:], cartridge.RAM()[0x8000+12:])
+}
+
+func TestHuC3SaveLoad(t *testing.T) {
+	// GIVEN
+	now := rtcTestTime
+	getNow := func() time.Time { return now }
+
+	core := newTestCore(make([]byte, 0x8000), 0x8000)
+	core.saveGamePath = filepath.Join(t.TempDir(), "game.sav")
+	cartridge := newTestClockCartridge(core, 0xFE, getNow)
+	setHuC3Time(cartridge, 100, 2)
+	cartridge.HandleBanking(0x0000, huc3ModeRAM)
+	cartridge.WriteRAM(0x0000, 0x42)
+
+	// WHEN
+	cartridge.Save()
+	now = now.Add(30 * time.Minute)
+
+	reloadedCore := newTestCore(make([]byte, 0x8000), 0x8000)
+	reloadedCore.saveGamePath = core.saveGamePath
+	reloaded := newTestClockCartridge(reloadedCore, 0xFE, getNow)
+
+	// THEN
+	minutes, days := readHuC3Time(reloaded)
+	assert.Equal(t, uint16(130), minutes)
+	assert.Equal(t, uint16(2), days)
+	require.Len(t, reloaded.RAM(), 0x8000+huc3FooterSize)
+	assert.Equal(t, byte(0x42), reloaded.RAM()[0])
+}
+
+func TestNewCartridge_hudson(t *testing.T) {
+	// GIVEN
+	huc1Image := newTestImage(0xFF, 0x01, 0x03)
+	huc3Image := newTestImage(0xFE, 0x01, 0x03)
+
+	// WHEN
+	huc1Cartridge, huc1Err := NewCartridge(huc1Image, nil)
+	huc3Cartridge, huc3Err := NewCartridge(huc3Image, nil, WithInfraredEndpoint(&testInfrared{light: true}))
+
+	// THEN
+	require.NoError(t, huc1Err)
+	require.NoError(t, huc3Err)
+	assert.IsType(t, &huc1{}, huc1Cartridge)
+	assert.IsType(t, &huc3{}, huc3Cartridge)
+	huc3Cartridge.HandleBanking(0x0000, huc3ModeInfrared)
+	assert.Equal(t, byte(0xC1), huc3Cartridge.ReadRAM(0x0000))
+}
@@ -1,7 +1,8 @@
 package cartridge
 
 import (
-	log "go.uber.org/zap"
+	"bytes"
+	"gameboy-emulator/internal/util"
 )
 
 // This is the first MBC chip for the Game Boy. Any newer MBC chips work similarly,
@@ -18,6 +19,9 @@ import (
 // Note that the memory in range 0000–7FFF is used both for reading from ROM and writing to the
 // MBCs Control Registers.
 //
+// MBC1M multi-game compilation carts connect only 4 bits of register BANK1, so BANK2 selects one of the 256 KiB
+// games. They can't be identified by their header, but each game comes with its own header including the logo.
+//
 // Source: docs/gbctr.pdf page 136 ff
 type mbc1 struct {
 	*cartridgeCore
@@ -27,34 +31,63 @@ type mbc1 struct {
 	currentRAMBank byte
 	mode           byte
 	ramEnabled     bool
+
+	multicart bool
 }
 
+// Size of one game of a MBC1M multi-game compilation cart
+const mbc1mGameSize = 0x40000
+
 func newMBC1(core *cartridgeCore) Cartridge {
-	return &mbc1{
+	mbc := &mbc1{
 		cartridgeCore: core,
 		bank1:         1,
+		multicart:     isMBC1M(*core.rom),
+	}
+	mbc.load()
+	return mbc
+}
+
+// isMBC1M detects MBC1M multi-game compilation carts, which are 1 MiB in size and have a game with a valid logo in
+// their header at the start of the second 256 KiB
+func isMBC1M(rom []byte) bool {
+	if len(rom) != 4*mbc1mGameSize {
+		return false
 	}
+
+	logo := rom[mbc1mGameSize+logoAddress : mbc1mGameSize+logoAddress+len(nintendoLogo)]
+	return bytes.Equal(logo, nintendoLogo[:])
+}
+
+func (mbc *mbc1) Reset() {
+	mbc.bank1 = 1
+	mbc.bank2 = 0
+	mbc.currentRAMBank = 0
+	mbc.mode = 0
+	mbc.ramEnabled = false
 }
 
 func (mbc *mbc1) ReadROM(address uint16) byte {
 
 	if address > 0x7FFF {
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
+		return 0xFF // outside of the ROM area
 	}
 
 	physicalAddress := (mbc.getROMBank(address) | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
 	return (*mbc.rom)[physicalAddress]
 }
 
+func (mbc *mbc1) ROMBank(address uint16) int {
+	return mbc.bankOf(mbc.getROMBank(address))
+}
+
 func (mbc *mbc1) HandleBanking(address uint16, data byte) {
 	switch {
 	case address < 0x2000: // Enable/Disable RAM
 		wasEnabled := mbc.ramEnabled
 		mbc.ramEnabled = data&0x0F == 0x0A
-		if wasEnabled && !mbc.ramEnabled {
+		if wasEnabled && !mbc.ramEnabled { // games disable RAM after saving
 			mbc.Save()
-		} else if !wasEnabled && mbc.ramEnabled {
-			mbc.load()
 		}
 
 	case address < 0x4000: // write to register BANK1
@@ -75,11 +108,11 @@ func (mbc *mbc1) HandleBanking(address uint16, data byte) {
 
 func (mbc *mbc1) WriteRAM(address uint16, data byte) {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM write attempt", log.Uint16("address", address))
+		return // outside of the RAM area
 	}
 
-	// If RAM is not enabled writes are simply ignored
-	if !mbc.ramEnabled {
+	// If RAM is not enabled (or not present) writes are simply ignored
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
 		return
 	}
 
@@ -92,13 +125,23 @@ func (mbc *mbc1) WriteRAM(address uint16, data byte) {
 	mbc.ram[physicalAddress] = data
 }
 
+func (mbc *mbc1) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return -1
+	}
+	if mbc.mode == 0 {
+		return 0
+	}
+	return int(mbc.bank2) & ((len(mbc.ram) - 1) >> 13)
+}
+
 func (mbc *mbc1) ReadRAM(address uint16) byte {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM read", log.Uint16("address", address))
+		return 0xFF // outside of the RAM area
 	}
 
-	// If RAM is not enabled reads return 0xFF
-	if !mbc.ramEnabled {
+	// If RAM is not enabled (or not present) reads return 0xFF
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
 		return 0xFF
 	}
 
@@ -112,6 +155,17 @@ func (mbc *mbc1) ReadRAM(address uint16) byte {
 }
 
 func (mbc *mbc1) getROMBank(address uint16) uint32 {
+	if mbc.multicart { // BANK2 is shifted by one bit and bit 4 of BANK1 isn't connected
+		switch {
+		case mbc.mode == 0 && address <= 0x3FFF:
+			return 0
+		case mbc.mode == 1 && address <= 0x3FFF:
+			return uint32(mbc.bank2) << 18
+		default:
+			return uint32(mbc.bank2)<<18 | uint32(mbc.bank1&0x0F)<<14
+		}
+	}
+
 	switch {
 	case mbc.mode == 0 && address <= 0x3FFF:
 		return 0
@@ -122,3 +176,13 @@ func (mbc *mbc1) getROMBank(address uint16) uint32 {
 	}
 	return 0
 }
+
+func (mbc *mbc1) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.bank1, mbc.bank2, mbc.currentRAMBank, mbc.mode, mbc.ramEnabled)
+}
+
+func (mbc *mbc1) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.bank1, &mbc.bank2, &mbc.currentRAMBank, &mbc.mode, &mbc.ramEnabled)
+}
@@ -8,7 +8,7 @@ import (
 func TestMbc1ReadRAM_notEnabled(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0)
-	cartridge := newMBC1(&rom, 0x2000)
+	cartridge := newMBC1(newTestCore(rom, 0x2000))
 
 	// WHEN
 	result := cartridge.ReadRAM(0x00)
@@ -20,19 +20,20 @@ func TestMbc1ReadRAM_notEnabled(t *testing.T) {
 func TestMbc1ReadRAM_outOfBounds(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0)
-	cartridge := newMBC1(&rom, 0x2000)
+	cartridge := newMBC1(newTestCore(rom, 0x2000))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid RAM read",
-		func() { cartridge.ReadRAM(0x2000) },
-	)
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	result := cartridge.ReadRAM(0x2000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
 }
 
 func TestMbc1WriteRAM_notEnabled(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0)
-	cartridge := newMBC1(&rom, 0x2000)
+	cartridge := newMBC1(newTestCore(rom, 0x2000))
 
 	address := uint16(0x0000)
 
@@ -47,20 +48,21 @@ func TestMbc1WriteRAM_notEnabled(t *testing.T) {
 func TestMbc1WriteRAM_outOfBounds(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0x2000)
-	cartridge := newMBC1(&rom, 0x2000)
+	cartridge := newMBC1(newTestCore(rom, 0x2000))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid RAM write attempt",
-		func() { cartridge.WriteRAM(0x2000, 0xAA) },
-	)
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.WriteRAM(0x2000, 0xAA)
+
+	// THEN
+	assert.Equal(t, make([]byte, 0x2000), cartridge.RAM())
 }
 
 func TestMbc1ReadROM_lowerRangeMode0(t *testing.T) {
 
 	// GIVEN
 	rom := make([]byte, 0xF000)
-	cartridge := newMBC1(&rom, 0)
+	cartridge := newMBC1(newTestCore(rom, 0))
 
 	address := uint16(0x3FFF)
 	expectedValue := byte(0xAB)
@@ -79,7 +81,7 @@ func TestMbc1ReadROM_lowerRangeMode1(t *testing.T) {
 
 	// GIVEN
 	rom := make([]byte, 0x100000)
-	cartridge := newMBC1(&rom, 0)
+	cartridge := newMBC1(newTestCore(rom, 0))
 
 	address := uint16(0x3FFF)
 	expectedValue := byte(0xAB)
@@ -95,11 +97,25 @@ func TestMbc1ReadROM_lowerRangeMode1(t *testing.T) {
 	assert.Equal(t, expectedValue, result)
 }
 
+func TestMbc1ROMBank_mode1(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC1(newTestCore(make([]byte, 0x100000), 0))
+
+	// WHEN
+	cartridge.HandleBanking(0x6000, 0x1) // Set Mode to one
+	cartridge.HandleBanking(0x4000, 0x1) // sets bit 5 (from 0) to 1
+	cartridge.HandleBanking(0x2000, 0x2)
+
+	// THEN
+	assert.Equal(t, 0x20, cartridge.ROMBank(0x0000))
+	assert.Equal(t, 0x22, cartridge.ROMBank(0x4000))
+}
+
 func TestMbc1ReadROM_higherRange(t *testing.T) {
 
 	// GIVEN
 	rom := make([]byte, 0x120000)
-	cartridge := newMBC1(&rom, 0)
+	cartridge := newMBC1(newTestCore(rom, 0))
 
 	address := uint16(0x72A7)
 	expectedValue := byte(0xAB)
@@ -116,4 +132,111 @@ func TestMbc1ReadROM_higherRange(t *testing.T) {
 	assert.Equal(t, expectedValue, result)
 }
 
+func TestMbc1ReadWriteRAM_noRAM(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC1(newTestCore(rom, 0))
+
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.WriteRAM(0x0000, 0xAA)
+	result := cartridge.ReadRAM(0x0000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
+}
+
+func TestMbc1Reset(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x10000)
+	rom[0x4000] = 0x01
+	rom[0xC000] = 0x03
+	cartridge := newMBC1(newTestCore(rom, 0x2000))
+
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.HandleBanking(0x2000, 0x03) // Switch to ROM bank 3
+	cartridge.WriteRAM(0x0000, 0x42)
+
+	// WHEN
+	cartridge.Reset()
+
+	// THEN
+	assert.Equal(t, byte(0x01), cartridge.ReadROM(0x4000))
+	assert.Equal(t, byte(0xFF), cartridge.ReadRAM(0x0000))
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0])
+}
+
+// newMBC1MTestROM creates a MBC1M multi-game compilation cart of 1 MiB with a logo in the header of the second game
+func newMBC1MTestROM() []byte {
+	rom := make([]byte, 0x100000)
+	copy(rom[0x40000+logoAddress:], nintendoLogo[:])
+	return rom
+}
+
+func TestIsMBC1M(t *testing.T) {
+	// GIVEN
+	multicart := newMBC1MTestROM()
+	regular := make([]byte, 0x100000)
+	small := newMBC1MTestROM()[:0x80000]
+
+	// WHEN + THEN
+	assert.True(t, isMBC1M(multicart))
+	assert.False(t, isMBC1M(regular))
+	assert.False(t, isMBC1M(small))
+}
+
+func TestMbc1ReadROM_multicartHigherRange(t *testing.T) {
+	// GIVEN
+	rom := newMBC1MTestROM()
+	cartridge := newMBC1(newTestCore(rom, 0))
+
+	address := uint16(0x5234)
+	expectedValue := byte(0xAB)
+
+	rom[0x49234] = expectedValue // game 1, bank 2
+
+	// WHEN
+	cartridge.HandleBanking(0x2000, 0x12) // bit 4 of BANK1 isn't connected
+	cartridge.HandleBanking(0x4000, 0x01) // BANK2 selects the game
+	result := cartridge.ReadROM(address)
+
+	// THEN
+	assert.True(t, cartridge.(*mbc1).multicart)
+	assert.Equal(t, expectedValue, result)
+}
+
+func TestMbc1ReadROM_multicartLowerRangeMode1(t *testing.T) {
+	// GIVEN
+	rom := newMBC1MTestROM()
+	cartridge := newMBC1(newTestCore(rom, 0))
+
+	address := uint16(0x0147)
+	expectedValue := byte(0x01)
+
+	rom[0xC0147] = expectedValue // header of game 3
+
+	// WHEN
+	cartridge.HandleBanking(0x6000, 0x1) // Set Mode to one
+	cartridge.HandleBanking(0x4000, 0x3) // BANK2 selects the game
+	result := cartridge.ReadROM(address)
+
+	// THEN
+	assert.Equal(t, expectedValue, result)
+}
+
+func TestMbc1ReadROM_multicartBank0x10(t *testing.T) {
+	// GIVEN
+	rom := newMBC1MTestROM()
+	cartridge := newMBC1(newTestCore(rom, 0))
+
+	rom[0x0000] = 0xAB
+
+	// WHEN
+	cartridge.HandleBanking(0x2000, 0x10) // only not connected bit 4 set, maps bank 0 of the game
+	result := cartridge.ReadROM(0x4000)
+
+	// THEN
+	assert.Equal(t, byte(0xAB), result)
+}
+
 // TODO write more MBC1 tests
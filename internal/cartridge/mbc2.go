@@ -2,7 +2,6 @@ package cartridge
 
 import (
 	"gameboy-emulator/internal/util"
-	log "go.uber.org/zap"
 )
 
 // MBC2 supports ROM sizes up to 2 Mbit (16 banks of 0x4000 bytes) and includes an internal
@@ -23,22 +22,34 @@ func newMBC2(core *cartridgeCore) Cartridge {
 		ramEnabled:     false,
 	}
 	m.cartridgeCore.ram = make([]byte, 0x200) // MBC2 always has 0x200 bytes of RAM
+	m.load()
 	return m
 }
 
+func (mbc *mbc2) Reset() {
+	mbc.currentROMBank = 1
+	mbc.ramEnabled = false
+}
+
 func (mbc *mbc2) ReadROM(address uint16) byte {
 	switch {
 	case address <= 0x3FFF: // hardcoded ROM Bank 0
-		return (*mbc.rom)[address]
+		return (*mbc.rom)[uint32(address)&uint32(len(*mbc.rom)-1)]
 
 	case address <= 0x7FFF: // other ROM Banks
 		physicalAddress := (uint32(mbc.currentROMBank)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
 		return (*mbc.rom)[physicalAddress]
 
 	default:
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
+		return 0xFF // outside of the ROM area
 	}
-	return 0
+}
+
+func (mbc *mbc2) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.currentROMBank) << 14)
 }
 
 func (mbc *mbc2) HandleBanking(address uint16, data byte) {
@@ -60,7 +71,7 @@ func (mbc *mbc2) HandleBanking(address uint16, data byte) {
 
 func (mbc *mbc2) WriteRAM(address uint16, data byte) {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM write attempt", log.Uint16("address", address))
+		return // outside of the RAM area
 	}
 
 	// If RAM is not enabled writes are simply ignored
@@ -72,9 +83,16 @@ func (mbc *mbc2) WriteRAM(address uint16, data byte) {
 	mbc.ram[address&0x1FF] = data | 0xF0 // only lower for bits are stored and upper for bits are set to 1 (open bus)
 }
 
+func (mbc *mbc2) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramEnabled {
+		return -1
+	}
+	return 0
+}
+
 func (mbc *mbc2) ReadRAM(address uint16) byte {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM read", log.Uint16("address", address))
+		return 0xFF // outside of the RAM area
 	}
 
 	// If RAM is not enabled reads return 0xFF
@@ -85,3 +103,13 @@ func (mbc *mbc2) ReadRAM(address uint16) byte {
 	// MBC2 only has 0x200 byte of RAM, that's why only the lower 9 bits of the address are used
 	return mbc.ram[address&0x1FF]
 }
+
+func (mbc *mbc2) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.currentROMBank, mbc.ramEnabled)
+}
+
+func (mbc *mbc2) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.currentROMBank, &mbc.ramEnabled)
+}
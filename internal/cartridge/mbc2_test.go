@@ -7,8 +7,8 @@ import (
 
 func TestMbc2ReadRAM_notEnabled(t *testing.T) {
 	// GIVEN
-	rom := make([]byte, 0)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	// WHEN
 	result := cartridge.ReadRAM(0x00)
@@ -19,20 +19,21 @@ func TestMbc2ReadRAM_notEnabled(t *testing.T) {
 
 func TestMbc2ReadRAM_outOfBounds(t *testing.T) {
 	// GIVEN
-	rom := make([]byte, 0)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid RAM read",
-		func() { cartridge.ReadRAM(0x2000) },
-	)
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	result := cartridge.ReadRAM(0x2000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
 }
 
 func TestMbc2WriteRAM_notEnabled(t *testing.T) {
 	// GIVEN
-	rom := make([]byte, 0)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	address := uint16(0x0000)
 
@@ -46,21 +47,22 @@ func TestMbc2WriteRAM_notEnabled(t *testing.T) {
 
 func TestMbc2WriteRAM_outOfBounds(t *testing.T) {
 	// GIVEN
-	rom := make([]byte, 0)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid RAM write attempt",
-		func() { cartridge.WriteRAM(0x2000, 0xAA) },
-	)
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.WriteRAM(0x2000, 0xAA)
+
+	// THEN
+	assert.Equal(t, make([]byte, 0x200), cartridge.RAM())
 }
 
 func TestMbc2WriteRAM_ReadRAM_HandleBanking(t *testing.T) {
 
 	// GIVEN
-	rom := make([]byte, 0)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	// WHEN
 	cartridge.HandleBanking(0x3000, 0xFA) // Enable RAM
@@ -80,8 +82,8 @@ func TestMbc2WriteRAM_ReadRAM_HandleBanking(t *testing.T) {
 
 func TestMbc2ReadRom_bank0(t *testing.T) {
 	// GIVEN
-	rom := make([]byte, 0x4000)
-	cartridge := newMBC2(&rom)
+	rom := make([]byte, 0x8000)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	address := uint16(0x3FFF)
 	expectedValue := uint8(0xAB)
@@ -98,19 +100,19 @@ func TestMbc2ReadRom_bank0(t *testing.T) {
 func TestMbc2ReadRom_outOfBounds(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0x8000)
-	cartridge := newMBC2(&rom)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid ROM read",
-		func() { cartridge.ReadROM(0x8000) },
-	)
+	// WHEN
+	result := cartridge.ReadROM(0x8000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
 }
 
 func TestMbc2ReadRom_HandleBanking(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0xC000)
-	cartridge := newMBC2(&rom)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	readAddress := uint16(0x7FFF)
 
@@ -137,7 +139,7 @@ func TestMbc2ReadRom_HandleBanking(t *testing.T) {
 func TestMbc2HandleBanking_romBankNever0(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0x8000)
-	cartridge := newMBC2(&rom)
+	cartridge := newMBC2(newTestCore(rom, 0))
 
 	// has to be an address < 0x4000 in which the least
 	// significant bit of upper address byte is set to 1
@@ -1,10 +1,15 @@
 package cartridge
 
 import (
+	"encoding/binary"
 	"gameboy-emulator/internal/util"
-	log "go.uber.org/zap"
-	"os"
-	"time"
+)
+
+// Size of the clock footer appended to the save game as written by BGB and VBA-M: the current and the latched
+// registers as 32-bit values followed by a 64-bit UNIX timestamp. Older emulators write a 32-bit timestamp.
+const (
+	rtcFooterSize      = 48
+	rtcFooterSizeShort = 44
 )
 
 // This is the first MBC chip for the Game Boy. Any newer MBC chips work similarly,
@@ -23,8 +28,6 @@ import (
 //
 // Source: docs/gbctr.pdf page 136 ff
 type (
-	nowProvider func() time.Time
-
 	mbc3 struct {
 		*cartridgeCore
 
@@ -45,41 +48,46 @@ type (
 		shadowRtcDL byte
 		shadowRtcDH byte
 
-		lastUpdate time.Time
-		getNow     nowProvider
-		ticker     *time.Ticker
-		stopTicker chan bool
+		hasRTC    bool
+		rtcCycles uint32 // T-cycles since the last increment of the seconds register
 	}
 )
 
-func newMBC3(core *cartridgeCore, getNow nowProvider) Cartridge {
+func newMBC3(core *cartridgeCore) Cartridge {
 	mbc := &mbc3{
 		cartridgeCore: core,
 		romb:          1,
-		lastUpdate:    getNow().UTC(),
-		getNow:        getNow,
-		stopTicker:    make(chan bool),
+		hasRTC:        core.header.Type == 0x0F || core.header.Type == 0x10,
 	}
-	mbc.startRTC()
+	mbc.load()
 	return mbc
 }
 
+func (mbc *mbc3) Reset() {
+	mbc.romb = 1
+	mbc.rambRtc = 0
+	mbc.rtcLatchHigh = false
+	mbc.ramRtcEnabled = false
+}
+
 func (mbc *mbc3) ReadROM(address uint16) byte {
-	if address >= 0x8000 {
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
-	}
 	switch {
 	case address < 0x4000:
 		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
 	case address < 0x8000:
 		bankOffset := uint32(mbc.romb) << 14
-		physicalAddress := bankOffset | uint32(address&0x3FFF)
+		physicalAddress := (bankOffset | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
 		return (*mbc.rom)[physicalAddress]
 	default:
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
+		return 0xFF // outside of the ROM area
 	}
+}
 
-	return 0xFF
+func (mbc *mbc3) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.romb) << 14)
 }
 
 func (mbc *mbc3) HandleBanking(address uint16, data byte) {
@@ -113,7 +121,7 @@ func (mbc *mbc3) HandleBanking(address uint16, data byte) {
 
 func (mbc *mbc3) WriteRAM(address uint16, data byte) {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM write attempt", log.Uint16("address", address))
+		return // outside of the RAM area
 	}
 
 	// If RAM is not enabled writes are simply ignored
@@ -121,7 +129,7 @@ func (mbc *mbc3) WriteRAM(address uint16, data byte) {
 		return
 	}
 
-	if mbc.rambRtc <= 0x07 {
+	if mbc.rambRtc <= 0x07 && len(mbc.ram) > 0 {
 		physicalAddress := address & 0x1FFF
 		physicalAddress |= uint16(mbc.rambRtc) << 13
 		physicalAddress &= uint16(len(mbc.ram)) - 1
@@ -132,6 +140,7 @@ func (mbc *mbc3) WriteRAM(address uint16, data byte) {
 	case 0x08:
 		mbc.rtcS = data & 0x3F
 		mbc.shadowRtcS = data & 0x3F
+		mbc.rtcCycles = 0 // writing the seconds resets the prescaler
 	case 0x09:
 		mbc.rtcM = data & 0x3F
 		mbc.shadowRtcM = data & 0x3F
@@ -144,17 +153,19 @@ func (mbc *mbc3) WriteRAM(address uint16, data byte) {
 	case 0x0C:
 		mbc.rtcDH = data & 0xC1
 		mbc.shadowRtcDH = data & 0xC1
-		if util.BitIsSet8(data, 6) {
-			mbc.stopRTC()
-		} else {
-			mbc.startRTC()
-		}
 	}
 }
 
+func (mbc *mbc3) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramRtcEnabled || mbc.rambRtc > 0x07 || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.rambRtc) & ((len(mbc.ram) - 1) >> 13)
+}
+
 func (mbc *mbc3) ReadRAM(address uint16) byte {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM read", log.Uint16("address", address))
+		return 0xFF // outside of the RAM area
 	}
 
 	// If RAM is not enabled reads return 0xFF
@@ -162,7 +173,7 @@ func (mbc *mbc3) ReadRAM(address uint16) byte {
 		return 0xFF
 	}
 
-	if mbc.rambRtc <= 0x07 {
+	if mbc.rambRtc <= 0x07 && len(mbc.ram) > 0 {
 		physicalAddress := address & 0x1FFF
 		physicalAddress |= uint16(mbc.rambRtc) << 13
 		physicalAddress &= uint16(len(mbc.ram)) - 1
@@ -194,36 +205,19 @@ func (mbc *mbc3) latch() {
 	mbc.rtcDH = mbc.shadowRtcDH
 }
 
-func (mbc *mbc3) startRTC() {
-	if mbc.ticker != nil {
+// Tick counts the T-cycles of the seconds register, which stands still while the clock is halted
+func (mbc *mbc3) Tick() {
+	if !mbc.hasRTC || util.BitIsSet8(mbc.shadowRtcDH, 6) {
 		return
 	}
 
-	mbc.ticker = time.NewTicker(time.Second)
-	go func() {
-		for {
-			select {
-			case <-mbc.ticker.C:
-				mbc.tick()
-			case <-mbc.stopTicker:
-				return
-			}
-		}
-	}()
-}
-
-func (mbc *mbc3) stopRTC() {
-	if mbc.ticker == nil {
-		return
+	if mbc.rtcCycles++; mbc.rtcCycles >= rtcCyclesPerSecond {
+		mbc.rtcCycles = 0
+		mbc.tick()
 	}
-
-	mbc.ticker.Stop()
-	mbc.stopTicker <- true
-	mbc.ticker = nil
 }
 
 func (mbc *mbc3) tick() {
-	mbc.lastUpdate = mbc.getNow()
 	overflow := false
 
 	mbc.shadowRtcS++
@@ -285,37 +279,107 @@ func (mbc *mbc3) tick() {
 	}
 }
 
-func (mbc *mbc3) Save() {
-	// If RAM is completely empty (= all zeroes) don't save
-	if util.IsEmpty(mbc.ram) {
+// advance moves the clock forward by the given number of seconds, e.g. for the time the emulator was turned off
+func (mbc *mbc3) advance(seconds int64) {
+	// Registers set to invalid values by the game don't carry over as usual, so tick until they are valid again
+	for ; seconds > 0 && (mbc.shadowRtcS >= 60 || mbc.shadowRtcM >= 60 || mbc.shadowRtcH >= 24); seconds-- {
+		mbc.tick()
+	}
+	if seconds <= 0 {
 		return
 	}
 
-	saveState := mbc.ram[:]
-	saveState = append(saveState, mbc.shadowRtcS, mbc.shadowRtcM, mbc.shadowRtcH, mbc.shadowRtcDL, mbc.shadowRtcDH)
-	saveState = append(saveState, []byte(mbc.lastUpdate.Format(time.DateTime))...)
+	days := int64(mbc.shadowRtcDL) | int64(mbc.shadowRtcDH&0x01)<<8
+	total := days*86400 + int64(mbc.shadowRtcH)*3600 + int64(mbc.shadowRtcM)*60 + int64(mbc.shadowRtcS) + seconds
 
-	err := os.WriteFile(mbc.saveGamePath, mbc.ram, 0644)
-	if err != nil {
-		log.L().Error("Error writing save file", log.Error(err))
+	days = total / 86400
+	if days > 0x1FF { // day counter overflow
+		util.SetBit(&mbc.shadowRtcDH, 7)
+		days &= 0x1FF
 	}
+
+	mbc.shadowRtcS = byte(total % 60)
+	mbc.shadowRtcM = byte(total / 60 % 60)
+	mbc.shadowRtcH = byte(total / 3600 % 24)
+	mbc.shadowRtcDL = byte(days)
+	mbc.shadowRtcDH = mbc.shadowRtcDH&0xFE | byte(days>>8)
 }
 
-func (mbc *mbc3) load() {
-	data, err := os.ReadFile(mbc.saveGamePath)
-	if err != nil {
-		if !os.IsNotExist(err) {
-			log.L().Error("Error reading save file", log.Error(err))
-		}
-		return
+func (mbc *mbc3) RAM() []byte {
+	return mbc.rtcSaveGame(mbc)
+}
+
+func (mbc *mbc3) restoreSaveGame(data []byte) {
+	mbc.restoreRTCSaveGame(data, mbc)
+}
+
+// encodeRTC writes the current and the latched registers in the layout of BGB and VBA-M, see rtcFooterSize
+func (mbc *mbc3) encodeRTC(timestamp int64) []byte {
+	if !mbc.hasRTC {
+		return nil
+	}
+
+	footer := make([]byte, rtcFooterSize)
+	registers := [10]byte{
+		mbc.shadowRtcS, mbc.shadowRtcM, mbc.shadowRtcH, mbc.shadowRtcDL, mbc.shadowRtcDH,
+		mbc.rtcS, mbc.rtcM, mbc.rtcH, mbc.rtcDL, mbc.rtcDH,
+	}
+	for i, register := range registers {
+		binary.LittleEndian.PutUint32(footer[4*i:], uint32(register))
+	}
+	binary.LittleEndian.PutUint64(footer[4*len(registers):], uint64(timestamp))
+	return footer
+}
+
+// decodeRTC reads the registers, masking bits the chip doesn't have. The timestamp of the short footer is 32-bit.
+func (mbc *mbc3) decodeRTC(footer []byte) (int64, bool) {
+	if !mbc.hasRTC || (len(footer) != rtcFooterSize && len(footer) != rtcFooterSizeShort) {
+		return 0, false
+	}
+
+	registers := [10]*byte{
+		&mbc.shadowRtcS, &mbc.shadowRtcM, &mbc.shadowRtcH, &mbc.shadowRtcDL, &mbc.shadowRtcDH,
+		&mbc.rtcS, &mbc.rtcM, &mbc.rtcH, &mbc.rtcDL, &mbc.rtcDH,
+	}
+	masks := [5]byte{0x3F, 0x3F, 0x1F, 0xFF, 0xC1}
+	for i, register := range registers {
+		*register = byte(binary.LittleEndian.Uint32(footer[4*i:])) & masks[i%len(masks)]
+	}
+	mbc.rtcCycles = 0
+
+	if len(footer) == rtcFooterSize {
+		return int64(binary.LittleEndian.Uint64(footer[4*len(registers):])), true
+	}
+	return int64(binary.LittleEndian.Uint32(footer[4*len(registers):])), true
+}
+
+// catchUp advances the clock unless it was halted when the save game was written
+func (mbc *mbc3) catchUp(seconds int64) {
+	if !util.BitIsSet8(mbc.shadowRtcDH, 6) {
+		mbc.advance(seconds)
 	}
+}
+
+func (mbc *mbc3) Save() {
+	mbc.persist(mbc)
+}
+
+func (mbc *mbc3) load() {
+	mbc.loadSaveGame(mbc)
+}
+
+func (mbc *mbc3) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb, mbc.rambRtc, mbc.rtcLatchHigh, mbc.ramRtcEnabled,
+		mbc.rtcS, mbc.rtcM, mbc.rtcH, mbc.rtcDL, mbc.rtcDH,
+		mbc.shadowRtcS, mbc.shadowRtcM, mbc.shadowRtcH, mbc.shadowRtcDL, mbc.shadowRtcDH,
+		mbc.rtcCycles)
+}
 
-	ramSize := len(mbc.ram)
-	mbc.ram = data[:ramSize]
-	mbc.shadowRtcS = data[ramSize]
-	mbc.shadowRtcM = data[ramSize+1]
-	mbc.shadowRtcH = data[ramSize+2]
-	mbc.shadowRtcDL = data[ramSize+3]
-	mbc.shadowRtcDH = data[ramSize+4]
-	mbc.lastUpdate, _ = time.Parse(time.DateTime, string(data[ramSize+5:]))
+func (mbc *mbc3) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb, &mbc.rambRtc, &mbc.rtcLatchHigh, &mbc.ramRtcEnabled,
+		&mbc.rtcS, &mbc.rtcM, &mbc.rtcH, &mbc.rtcDL, &mbc.rtcDH,
+		&mbc.shadowRtcS, &mbc.shadowRtcM, &mbc.shadowRtcH, &mbc.shadowRtcDL, &mbc.shadowRtcDH,
+		&mbc.rtcCycles)
 }
@@ -0,0 +1,208 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newRTCTestCore creates the core of a MBC3 cartridge with 32 KiB RAM
+func newRTCTestCore() *cartridgeCore {
+	return newTestCore(make([]byte, 0x8000), 0x8000)
+}
+
+// newRTCFooter creates a clock footer with the given current registers, latched registers and timestamp
+func newRTCFooter(current [5]byte, latched [5]byte, timestamp int64) []byte {
+	footer := make([]byte, rtcFooterSize)
+	for i, register := range append(current[:], latched[:]...) {
+		binary.LittleEndian.PutUint32(footer[4*i:], uint32(register))
+	}
+	binary.LittleEndian.PutUint64(footer[40:], uint64(timestamp))
+	return footer
+}
+
+// writeRTC enables RAM and writes the given value into an RTC register
+func writeRTC(cartridge Cartridge, register byte, value byte) {
+	cartridge.HandleBanking(0x0000, 0x0A)
+	cartridge.HandleBanking(0x4000, register)
+	cartridge.WriteRAM(0x0000, value)
+}
+
+// readRTC latches the clock and reads the given RTC register
+func readRTC(cartridge Cartridge, register byte) byte {
+	cartridge.HandleBanking(0x0000, 0x0A)
+	cartridge.HandleBanking(0x6000, 0x00)
+	cartridge.HandleBanking(0x6000, 0x01)
+	cartridge.HandleBanking(0x4000, register)
+	return cartridge.ReadRAM(0x0000)
+}
+
+func TestMbc3RAM_rtcFooter(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	writeRTC(cartridge, 0x08, 0x12)
+	writeRTC(cartridge, 0x09, 0x34)
+	writeRTC(cartridge, 0x0A, 0x05)
+	writeRTC(cartridge, 0x0B, 0x67)
+	writeRTC(cartridge, 0x0C, 0x41)
+
+	// WHEN
+	result := cartridge.RAM()
+
+	// THEN
+	require.Len(t, result, 0x8000+rtcFooterSize)
+	expected := newRTCFooter([5]byte{0x12, 0x34, 0x05, 0x67, 0x41}, [5]byte{0x12, 0x34, 0x05, 0x67, 0x41}, rtcTestTime.Unix())
+	assert.Equal(t, expected, result[0x8000:])
+}
+
+func TestMbc3RAM_noRTC(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x13, func() time.Time { return rtcTestTime })
+
+	// WHEN
+	result := cartridge.RAM()
+
+	// THEN
+	assert.Len(t, result, 0x8000)
+}
+
+func TestMbc3RestoreSaveGame_advancesClock(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+
+	saveGame := make([]byte, 0x8000)
+	saveGame[0x1234] = 0xAB
+	// day 511, 23:59:30 saved 45 seconds ago
+	footer := newRTCFooter([5]byte{30, 59, 23, 0xFF, 0x01}, [5]byte{1, 2, 3, 4, 0}, rtcTestTime.Unix()-45)
+
+	// WHEN
+	cartridge.(*mbc3).restoreSaveGame(append(saveGame, footer...))
+
+	// THEN
+	mbc := cartridge.(*mbc3)
+	assert.Equal(t, byte(0xAB), mbc.ram[0x1234])
+	assert.Equal(t, [5]byte{15, 0, 0, 0, 0x80}, // day counter overflow sets the carry bit
+		[5]byte{mbc.shadowRtcS, mbc.shadowRtcM, mbc.shadowRtcH, mbc.shadowRtcDL, mbc.shadowRtcDH})
+	assert.Equal(t, [5]byte{1, 2, 3, 4, 0}, [5]byte{mbc.rtcS, mbc.rtcM, mbc.rtcH, mbc.rtcDL, mbc.rtcDH})
+}
+
+func TestMbc3RestoreSaveGame_halted(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	footer := newRTCFooter([5]byte{10, 20, 5, 0, 0x40}, [5]byte{}, rtcTestTime.Unix()-3600)
+
+	// WHEN
+	cartridge.(*mbc3).restoreSaveGame(append(make([]byte, 0x8000), footer...))
+
+	// THEN
+	assert.Equal(t, byte(10), readRTC(cartridge, 0x08))
+	assert.Equal(t, byte(20), readRTC(cartridge, 0x09))
+	assert.Equal(t, byte(5), readRTC(cartridge, 0x0A))
+}
+
+func TestMbc3RestoreSaveGame_shortFooter(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	footer := newRTCFooter([5]byte{0, 0, 1, 0, 0}, [5]byte{}, 0)[:rtcFooterSizeShort]
+	binary.LittleEndian.PutUint32(footer[40:], uint32(rtcTestTime.Unix()-2*86400-61))
+
+	// WHEN
+	cartridge.(*mbc3).restoreSaveGame(append(make([]byte, 0x8000), footer...))
+
+	// THEN
+	assert.Equal(t, byte(1), readRTC(cartridge, 0x08))
+	assert.Equal(t, byte(1), readRTC(cartridge, 0x09))
+	assert.Equal(t, byte(1), readRTC(cartridge, 0x0A))
+	assert.Equal(t, byte(2), readRTC(cartridge, 0x0B))
+}
+
+func TestMbc3SaveLoad(t *testing.T) {
+	// GIVEN
+	now := rtcTestTime
+	getNow := func() time.Time { return now }
+
+	core := newRTCTestCore()
+	core.saveGamePath = filepath.Join(t.TempDir(), "game.sav")
+	cartridge := newTestClockCartridge(core, 0x10, getNow)
+	writeRTC(cartridge, 0x0A, 0x08)
+	cartridge.HandleBanking(0x4000, 0x00)
+	cartridge.WriteRAM(0x0000, 0x42)
+
+	// WHEN
+	cartridge.Save()
+	now = now.Add(90 * time.Minute)
+
+	reloadedCore := newRTCTestCore()
+	reloadedCore.saveGamePath = core.saveGamePath
+	reloaded := newTestClockCartridge(reloadedCore, 0x10, getNow)
+
+	// THEN
+	assert.Equal(t, byte(30), readRTC(reloaded, 0x09))
+	assert.Equal(t, byte(9), readRTC(reloaded, 0x0A))
+	reloaded.HandleBanking(0x4000, 0x00)
+	assert.Equal(t, byte(0x42), reloaded.ReadRAM(0x0000))
+}
+
+func TestMbc3Tick(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	writeRTC(cartridge, 0x08, 59)
+
+	// WHEN
+	for i := 0; i < rtcCyclesPerSecond-1; i++ {
+		cartridge.Tick()
+	}
+	before := readRTC(cartridge, 0x08)
+	cartridge.Tick()
+
+	// THEN
+	assert.Equal(t, byte(59), before)
+	assert.Equal(t, byte(0), readRTC(cartridge, 0x08))
+	assert.Equal(t, byte(1), readRTC(cartridge, 0x09))
+}
+
+func TestMbc3Tick_halted(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	writeRTC(cartridge, 0x0C, 0x40)
+
+	// WHEN
+	for i := 0; i < 2*rtcCyclesPerSecond; i++ {
+		cartridge.Tick()
+	}
+
+	// THEN
+	assert.Equal(t, byte(0), readRTC(cartridge, 0x08))
+}
+
+func TestMbc3Tick_writeSecondsResetsPrescaler(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime })
+	for i := 0; i < rtcCyclesPerSecond-1; i++ {
+		cartridge.Tick()
+	}
+
+	// WHEN
+	writeRTC(cartridge, 0x08, 10)
+	cartridge.Tick()
+
+	// THEN
+	assert.Equal(t, byte(10), readRTC(cartridge, 0x08))
+}
+
+func TestMbc3RestoreSaveGame_withoutHostClockSync(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newRTCTestCore(), 0x10, func() time.Time { return rtcTestTime },
+		WithHostClockSync(false))
+	footer := newRTCFooter([5]byte{10, 20, 5, 0, 0}, [5]byte{}, rtcTestTime.Unix()-3600)
+
+	// WHEN
+	cartridge.(*mbc3).restoreSaveGame(append(make([]byte, 0x8000), footer...))
+
+	// THEN
+	assert.Equal(t, byte(20), readRTC(cartridge, 0x09))
+	assert.Equal(t, byte(5), readRTC(cartridge, 0x0A))
+}
@@ -1,9 +1,13 @@
 package cartridge
 
 import (
-	log "go.uber.org/zap"
+	"gameboy-emulator/internal/util"
 )
 
+// MBC5 supports up to 8 MiB ROM and 128 KiB RAM. Cartridges with a rumble motor use bit 3 of the RAM bank
+// register to turn the motor on and off, so only up to 64 KiB RAM can be used with them.
+//
+// Source: https://gbdev.io/pandocs/MBC5.html
 type mbc5 struct {
 	*cartridgeCore
 
@@ -11,13 +15,39 @@ type mbc5 struct {
 	romb1      byte
 	ramb       byte
 	ramEnabled bool
+
+	hasRumble bool
+	motorOn   bool
+
+	// T-cycles counted since the last call of Rumble, in total and with the motor turned on
+	rumbleCycles  uint32
+	motorOnCycles uint32
+}
+
+// rumbleMBC5 is a MBC5 cartridge with rumble motor, which is reported through the Rumbler interface
+type rumbleMBC5 struct {
+	*mbc5
 }
 
 func newMBC5(core *cartridgeCore) Cartridge {
-	return &mbc5{
+	mbc := &mbc5{
 		cartridgeCore: core,
 		romb0:         1,
+		hasRumble:     core.header.Type >= 0x1C && core.header.Type <= 0x1E,
 	}
+	mbc.load()
+	if mbc.hasRumble {
+		return &rumbleMBC5{mbc}
+	}
+	return mbc
+}
+
+func (mbc *mbc5) Reset() {
+	mbc.romb0 = 1
+	mbc.romb1 = 0
+	mbc.ramb = 0
+	mbc.ramEnabled = false
+	mbc.motorOn = false
 }
 
 func (mbc *mbc5) ReadROM(address uint16) byte {
@@ -30,9 +60,15 @@ func (mbc *mbc5) ReadROM(address uint16) byte {
 		physicalAddress := (bankAddress | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
 		return (*mbc.rom)[physicalAddress]
 	default:
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
+		return 0xFF // outside of the ROM area
 	}
-	return 0xFF
+}
+
+func (mbc *mbc5) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(uint16(mbc.romb1)<<8|uint16(mbc.romb0)) << 14)
 }
 
 func (mbc *mbc5) HandleBanking(address uint16, data byte) {
@@ -46,37 +82,84 @@ func (mbc *mbc5) HandleBanking(address uint16, data byte) {
 	case address < 0x4000: // write to register BANK2
 		mbc.romb1 = data & 0x01 // only lowest  bit is relevant
 
-	case address < 0x6000: // change mode (uses only last bit)
-		mbc.ramb = data & 0x0F // only bit 0-3 are relevant
+	case address < 0x6000: // RAM bank, bit 3 controls the rumble motor if present
+		if mbc.hasRumble {
+			mbc.ramb = data & 0x07
+			mbc.motorOn = util.BitIsSet8(data, 3)
+		} else {
+			mbc.ramb = data & 0x0F
+		}
 	}
 }
 
+// Tick counts the T-cycles the rumble motor is turned on, see Rumble
+func (mbc *mbc5) Tick() {
+	if !mbc.hasRumble {
+		return
+	}
+
+	mbc.rumbleCycles++
+	if mbc.motorOn {
+		mbc.motorOnCycles++
+	}
+}
+
+// Rumble returns the share of T-cycles the motor was turned on since the last call, from 0 (off) to 1 (always
+// on). Games vary the strength of the rumble by turning the motor on and off quickly.
+func (mbc *rumbleMBC5) Rumble() float64 {
+	if mbc.rumbleCycles == 0 {
+		return 0
+	}
+
+	intensity := float64(mbc.motorOnCycles) / float64(mbc.rumbleCycles)
+	mbc.rumbleCycles = 0
+	mbc.motorOnCycles = 0
+	return intensity
+}
+
 func (mbc *mbc5) WriteRAM(address uint16, data byte) {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM write attempt", log.Uint16("address", address))
+		return // outside of the RAM area
 	}
 
-	// If RAM is not enabled writes are simply ignored
-	if !mbc.ramEnabled {
+	// If RAM is not enabled (or not present) writes are simply ignored
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
 		return
 	}
 
-	physicalAddress := (uint16(mbc.ramb)<<13 | address&0x1FFF) & uint16(len(mbc.ram)-1)
+	physicalAddress := (uint32(mbc.ramb)<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
 
 	mbc.ram[physicalAddress] = data
 }
 
+func (mbc *mbc5) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.ramb) & ((len(mbc.ram) - 1) >> 13)
+}
+
 func (mbc *mbc5) ReadRAM(address uint16) byte {
 	if address >= 0x2000 {
-		log.L().Panic("Invalid RAM read", log.Uint16("address", address))
+		return 0xFF // outside of the RAM area
 	}
 
-	// If RAM is not enabled reads return 0xFF
-	if !mbc.ramEnabled {
+	// If RAM is not enabled (or not present) reads return 0xFF
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
 		return 0xFF
 	}
 
-	physicalAddress := (uint16(mbc.ramb)<<13 | address&0x1FFF) & uint16(len(mbc.ram)-1)
+	physicalAddress := (uint32(mbc.ramb)<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
 
 	return mbc.ram[physicalAddress]
 }
+
+func (mbc *mbc5) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb0, mbc.romb1, mbc.ramb, mbc.ramEnabled, mbc.motorOn, mbc.rumbleCycles, mbc.motorOnCycles)
+}
+
+func (mbc *mbc5) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb0, &mbc.romb1, &mbc.ramb, &mbc.ramEnabled, &mbc.motorOn, &mbc.rumbleCycles, &mbc.motorOnCycles)
+}
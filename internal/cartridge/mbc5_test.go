@@ -0,0 +1,103 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// newRumbleTestCore creates the core of a MBC5+RUMBLE+RAM+BATTERY cartridge with 64 KiB RAM
+func newRumbleTestCore() *cartridgeCore {
+	core := newTestCore(make([]byte, 0x8000), 0x10000)
+	core.header.Type = 0x1E
+	return core
+}
+
+func TestMbc5WriteRAM_ReadRAM_lastBank(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC5(newTestCore(make([]byte, 0x8000), 0x20000))
+
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.HandleBanking(0x4000, 0x0F) // RAM bank 15
+	cartridge.WriteRAM(0x1FFF, 0xAB)
+
+	// THEN
+	assert.Equal(t, byte(0xAB), cartridge.ReadRAM(0x1FFF))
+	assert.Equal(t, byte(0xAB), cartridge.RAM()[0x1FFFF])
+}
+
+func TestMbc5HandleBanking_rumble(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC5(newRumbleTestCore())
+
+	// WHEN
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.HandleBanking(0x4000, 0x0A) // motor on, RAM bank 2
+	cartridge.WriteRAM(0x0000, 0xAB)
+
+	// THEN
+	assert.True(t, cartridge.(*rumbleMBC5).motorOn)
+	assert.Equal(t, byte(0xAB), cartridge.RAM()[0x4000])
+}
+
+func TestMbc5Rumble(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC5(newRumbleTestCore())
+	rumbler := cartridge.(Rumbler)
+
+	// WHEN
+	cartridge.HandleBanking(0x4000, 0x08) // motor on
+	for i := 0; i < 100; i++ {
+		cartridge.Tick()
+	}
+	cartridge.HandleBanking(0x4000, 0x00) // motor off
+	for i := 0; i < 300; i++ {
+		cartridge.Tick()
+	}
+	first := rumbler.Rumble()
+	second := rumbler.Rumble()
+
+	// THEN
+	assert.Equal(t, 0.25, first)
+	assert.Equal(t, 0.0, second)
+}
+
+func TestMbc5_noRumble(t *testing.T) {
+	// GIVEN
+	core := newTestCore(make([]byte, 0x8000), 0x20000)
+	core.header.Type = 0x1B
+
+	// WHEN
+	cartridge := newMBC5(core)
+
+	// THEN
+	_, ok := cartridge.(Rumbler)
+	assert.False(t, ok)
+}
+
+func TestMbc5ROMBank(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC5(newTestCore(make([]byte, 0x40000), 0)) // 16 banks
+
+	// WHEN
+	cartridge.HandleBanking(0x2000, 0x13) // bank 19 is masked to bank 3
+
+	// THEN
+	assert.Equal(t, 0, cartridge.ROMBank(0x3FFF))
+	assert.Equal(t, 3, cartridge.ROMBank(0x4000))
+	assert.Equal(t, 3, cartridge.ROMBank(0x7FFF))
+}
+
+func TestMbc5RAMBank(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC5(newTestCore(make([]byte, 0x8000), 0x8000)) // 4 banks
+
+	// WHEN
+	disabled := cartridge.RAMBank(0x0000)
+	cartridge.HandleBanking(0x0000, 0x0A) // Enable RAM
+	cartridge.HandleBanking(0x4000, 0x06) // bank 6 is masked to bank 2
+
+	// THEN
+	assert.Equal(t, -1, disabled)
+	assert.Equal(t, 2, cartridge.RAMBank(0x1FFF))
+}
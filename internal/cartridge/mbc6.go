@@ -0,0 +1,281 @@
+package cartridge
+
+import "gameboy-emulator/internal/util"
+
+const (
+	// MBC6 always comes with 32 KiB RAM and a 1 MiB Macronix MX29F008 flash chip
+	mbc6RAMSize   = 0x8000
+	mbc6FlashSize = 0x100000
+
+	// Size of the flash sectors erased at once
+	mbc6FlashSectorSize = 0x20000
+
+	// Identification of the flash chip read in ID mode
+	mbc6FlashManufacturer = 0xC2
+	mbc6FlashDevice       = 0x81
+)
+
+// States of the command protocol of the flash chip
+const (
+	flashRead       = iota
+	flashUnlocked1  // received 0xAA at 0x5555
+	flashUnlocked2  // received 0x55 at 0x2AAA, waiting for the command
+	flashEraseSetup // received 0x80, waiting for the second unlock sequence
+	flashErase1     // second sequence: received 0xAA
+	flashErase2     // second sequence: received 0x55, waiting for the erase command
+	flashProgram    // received 0xA0, the next write programs a byte
+	flashIdentify   // received 0x90, reads return the identification until reset with 0xF0
+)
+
+// MBC6 is only used by Net de Get: Minigame @ 100. It splits the switchable ROM area and the RAM area into two
+// independently switched halves each: 4000–5FFF and 6000–7FFF map 8 KiB banks of either the ROM or the flash chip,
+// A000–AFFF and B000–BFFF map 4 KiB RAM banks.
+//
+// The flash chip is written with the usual AMD command sequences (unlock by writing 0xAA to 0x5555 and 0x55 to 0x2AAA
+// of the chip) once writing is enabled with 0x01 at 1000. Its contents are appended to the RAM in the save game.
+//
+// Source: https://gbdev.io/pandocs/MBC6.html
+type mbc6 struct {
+	*cartridgeCore
+
+	flash []byte
+
+	ramEnabled        bool
+	ramBanks          [2]byte
+	romBanks          [2]byte
+	flashSelected     [2]bool // the half maps the flash chip instead of ROM
+	flashEnabled      bool
+	flashWriteEnabled bool
+	flashState        byte
+}
+
+func newMBC6(core *cartridgeCore) Cartridge {
+	mbc := &mbc6{
+		cartridgeCore: core,
+		flash:         make([]byte, mbc6FlashSize),
+		romBanks:      [2]byte{2, 3},
+	}
+	mbc.ram = make([]byte, mbc6RAMSize)
+	fill(mbc.flash, 0xFF) // an erased flash chip contains all ones
+	mbc.load()
+	return mbc
+}
+
+func (mbc *mbc6) Reset() {
+	mbc.ramEnabled = false
+	mbc.ramBanks = [2]byte{}
+	mbc.romBanks = [2]byte{2, 3}
+	mbc.flashSelected = [2]bool{}
+	mbc.flashEnabled = false
+	mbc.flashWriteEnabled = false
+	mbc.flashState = flashRead
+}
+
+func (mbc *mbc6) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		half := (address - 0x4000) >> 13
+		if mbc.flashSelected[half] {
+			return mbc.readFlash(mbc.flashAddress(half, address))
+		}
+		physicalAddress := (uint32(mbc.romBanks[half])<<13 | uint32(address&0x1FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *mbc6) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return int(address >> 13)
+	}
+
+	half := (address - 0x4000) >> 13
+	if mbc.flashSelected[half] {
+		return -1
+	}
+	return int(uint32(mbc.romBanks[half]) & (uint32(len(*mbc.rom)-1) >> 13))
+}
+
+func (mbc *mbc6) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x0400: // Enable/Disable RAM
+		mbc.ramEnabled = data&0x0F == 0x0A
+	case address < 0x0800: // RAM bank of A000–AFFF
+		mbc.ramBanks[0] = data & 0x07
+	case address < 0x0C00: // RAM bank of B000–BFFF
+		mbc.ramBanks[1] = data & 0x07
+	case address < 0x1000: // Enable/Disable flash
+		mbc.flashEnabled = util.BitIsSet8(data, 0)
+	case address == 0x1000: // Enable/Disable writing flash
+		mbc.flashWriteEnabled = util.BitIsSet8(data, 0)
+	case address < 0x2000:
+		// not connected
+	case address < 0x4000: // bank and ROM/flash selection of 4000–5FFF (2000–2FFF) and 6000–7FFF (3000–3FFF)
+		half := (address - 0x2000) >> 12
+		if address&0x0800 == 0 {
+			mbc.romBanks[half] = data & 0x7F
+		} else {
+			mbc.flashSelected[half] = data == 0x08
+		}
+	case address < 0x8000:
+		half := (address - 0x4000) >> 13
+		if mbc.flashSelected[half] && mbc.flashEnabled {
+			mbc.writeFlash(mbc.flashAddress(half, address), data)
+		}
+	}
+}
+
+func (mbc *mbc6) flashAddress(half uint16, address uint16) uint32 {
+	return (uint32(mbc.romBanks[half])<<13 | uint32(address&0x1FFF)) & (mbc6FlashSize - 1)
+}
+
+func (mbc *mbc6) readFlash(address uint32) byte {
+	if mbc.flashState == flashIdentify {
+		switch address & 0xFF {
+		case 0x00:
+			return mbc6FlashManufacturer
+		case 0x01:
+			return mbc6FlashDevice
+		}
+	}
+	return mbc.flash[address]
+}
+
+// writeFlash advances the command sequence of the flash chip. Erasing and programming complete immediately.
+func (mbc *mbc6) writeFlash(address uint32, data byte) {
+	chipAddress := address & 0x7FFF // the unlock addresses only decode the lower 15 bits
+
+	if data == 0xF0 && mbc.flashState != flashProgram { // reset to read mode
+		mbc.flashState = flashRead
+		return
+	}
+
+	switch mbc.flashState {
+	case flashRead:
+		if chipAddress == 0x5555 && data == 0xAA {
+			mbc.flashState = flashUnlocked1
+		}
+	case flashUnlocked1:
+		mbc.flashState = flashRead
+		if chipAddress == 0x2AAA && data == 0x55 {
+			mbc.flashState = flashUnlocked2
+		}
+	case flashUnlocked2:
+		mbc.flashState = flashRead
+		if chipAddress != 0x5555 {
+			return
+		}
+		switch data {
+		case 0x80:
+			mbc.flashState = flashEraseSetup
+		case 0xA0:
+			mbc.flashState = flashProgram
+		case 0x90:
+			mbc.flashState = flashIdentify
+		}
+	case flashEraseSetup:
+		mbc.flashState = flashRead
+		if chipAddress == 0x5555 && data == 0xAA {
+			mbc.flashState = flashErase1
+		}
+	case flashErase1:
+		mbc.flashState = flashRead
+		if chipAddress == 0x2AAA && data == 0x55 {
+			mbc.flashState = flashErase2
+		}
+	case flashErase2:
+		mbc.flashState = flashRead
+		if !mbc.flashWriteEnabled {
+			return
+		}
+		switch {
+		case data == 0x10 && chipAddress == 0x5555: // chip erase
+			fill(mbc.flash, 0xFF)
+		case data == 0x30: // sector erase
+			sector := address &^ (mbc6FlashSectorSize - 1)
+			fill(mbc.flash[sector:sector+mbc6FlashSectorSize], 0xFF)
+		}
+	case flashProgram:
+		mbc.flashState = flashRead
+		if mbc.flashWriteEnabled {
+			mbc.flash[address] &= data // programming can only clear bits
+		}
+	}
+}
+
+func fill(data []byte, value byte) {
+	for i := range data {
+		data[i] = value
+	}
+}
+
+func (mbc *mbc6) WriteRAM(address uint16, data byte) {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return
+	}
+	mbc.ram[mbc.physicalRAMAddress(address)] = data
+}
+
+func (mbc *mbc6) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.physicalRAMAddress(address) >> 12)
+}
+
+func (mbc *mbc6) ReadRAM(address uint16) byte {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+	return mbc.ram[mbc.physicalRAMAddress(address)]
+}
+
+func (mbc *mbc6) physicalRAMAddress(address uint16) uint32 {
+	bank := mbc.ramBanks[address>>12&0x01]
+	return (uint32(bank)<<12 | uint32(address&0x0FFF)) & uint32(len(mbc.ram)-1)
+}
+
+// RAM returns the RAM followed by the contents of the flash chip
+func (mbc *mbc6) RAM() []byte {
+	return append(mbc.cartridgeCore.RAM(), mbc.flash...)
+}
+
+func (mbc *mbc6) restoreSaveGame(data []byte) {
+	mbc.restoreRAM(data)
+	if len(data) == len(mbc.ram)+mbc6FlashSize {
+		copy(mbc.flash, data[len(mbc.ram):])
+	} else {
+		fill(mbc.flash, 0xFF)
+	}
+}
+
+func (mbc *mbc6) Save() {
+	mbc.persist(mbc)
+}
+
+func (mbc *mbc6) load() {
+	mbc.loadSaveGame(mbc)
+}
+
+func (mbc *mbc6) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.WriteBytes(mbc.flash)
+	s.Write(mbc.ramEnabled, mbc.ramBanks, mbc.romBanks, mbc.flashSelected, mbc.flashEnabled,
+		mbc.flashWriteEnabled, mbc.flashState)
+}
+
+func (mbc *mbc6) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	flash := s.ReadBytes(mbc6FlashSize)
+	s.Read(&mbc.ramEnabled, &mbc.ramBanks, &mbc.romBanks, &mbc.flashSelected, &mbc.flashEnabled,
+		&mbc.flashWriteEnabled, &mbc.flashState)
+	if s.Err() == nil && len(flash) != len(mbc.flash) {
+		s.Fail(errStateRAMSize)
+	}
+	if s.Err() == nil {
+		copy(mbc.flash, flash)
+	}
+}
@@ -0,0 +1,147 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// newFlashTestMBC6 creates a MBC6 cartridge with writable flash mapped to 4000–5FFF
+func newFlashTestMBC6() Cartridge {
+	cartridge := newMBC6(newTestCore(make([]byte, 0x20000), 0))
+	cartridge.HandleBanking(0x0C00, 0x01)
+	cartridge.HandleBanking(0x1000, 0x01)
+	cartridge.HandleBanking(0x2800, 0x08)
+	return cartridge
+}
+
+// writeFlash writes to the given address of the flash chip through 4000–5FFF
+func writeFlash(cartridge Cartridge, address uint32, data byte) {
+	cartridge.HandleBanking(0x2000, byte(address>>13))
+	cartridge.HandleBanking(0x4000|uint16(address&0x1FFF), data)
+}
+
+// readFlash reads the given address of the flash chip through 4000–5FFF
+func readFlash(cartridge Cartridge, address uint32) byte {
+	cartridge.HandleBanking(0x2000, byte(address>>13))
+	return cartridge.ReadROM(0x4000 | uint16(address&0x1FFF))
+}
+
+// sendFlashCommand unlocks the flash chip and sends the given command
+func sendFlashCommand(cartridge Cartridge, command byte) {
+	writeFlash(cartridge, 0x5555, 0xAA)
+	writeFlash(cartridge, 0x2AAA, 0x55)
+	writeFlash(cartridge, 0x5555, command)
+}
+
+func TestMbc6ReadROM_halves(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x20000)
+	rom[5*0x2000+0x10] = 0x55
+	rom[6*0x2000+0x10] = 0x66
+	cartridge := newMBC6(newTestCore(rom, 0))
+
+	// WHEN
+	cartridge.HandleBanking(0x2000, 0x05)
+	cartridge.HandleBanking(0x3000, 0x06)
+
+	// THEN
+	assert.Equal(t, byte(0x55), cartridge.ReadROM(0x4010))
+	assert.Equal(t, byte(0x66), cartridge.ReadROM(0x6010))
+}
+
+func TestMbc6RAM_halves(t *testing.T) {
+	// GIVEN
+	cartridge := newMBC6(newTestCore(make([]byte, 0x20000), 0))
+	cartridge.HandleBanking(0x0000, 0x0A)
+
+	// WHEN
+	cartridge.HandleBanking(0x0400, 0x01)
+	cartridge.HandleBanking(0x0800, 0x07)
+	cartridge.WriteRAM(0x0000, 0x11)
+	cartridge.WriteRAM(0x1FFF, 0x77)
+
+	// THEN
+	ram := cartridge.RAM()
+	assert.Equal(t, byte(0x11), ram[0x1000])
+	assert.Equal(t, byte(0x77), ram[0x7FFF])
+	assert.Equal(t, byte(0x77), cartridge.ReadRAM(0x1FFF))
+}
+
+func TestMbc6Flash_program(t *testing.T) {
+	// GIVEN
+	cartridge := newFlashTestMBC6()
+
+	// WHEN
+	sendFlashCommand(cartridge, 0xA0)
+	writeFlash(cartridge, 0x12345, 0x42)
+
+	// THEN
+	assert.Equal(t, byte(0x42), readFlash(cartridge, 0x12345))
+	assert.Equal(t, byte(0x42), cartridge.RAM()[mbc6RAMSize+0x12345])
+}
+
+func TestMbc6Flash_writeProtected(t *testing.T) {
+	// GIVEN
+	cartridge := newFlashTestMBC6()
+	cartridge.HandleBanking(0x1000, 0x00)
+
+	// WHEN
+	sendFlashCommand(cartridge, 0xA0)
+	writeFlash(cartridge, 0x12345, 0x42)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), readFlash(cartridge, 0x12345))
+}
+
+func TestMbc6Flash_eraseSector(t *testing.T) {
+	// GIVEN
+	cartridge := newFlashTestMBC6()
+	for _, address := range []uint32{0x20000, 0x3FFFF, 0x40000} {
+		sendFlashCommand(cartridge, 0xA0)
+		writeFlash(cartridge, address, 0x00)
+	}
+
+	// WHEN
+	sendFlashCommand(cartridge, 0x80)
+	writeFlash(cartridge, 0x5555, 0xAA)
+	writeFlash(cartridge, 0x2AAA, 0x55)
+	writeFlash(cartridge, 0x21234, 0x30)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), readFlash(cartridge, 0x20000))
+	assert.Equal(t, byte(0xFF), readFlash(cartridge, 0x3FFFF))
+	assert.Equal(t, byte(0x00), readFlash(cartridge, 0x40000))
+}
+
+func TestMbc6Flash_identify(t *testing.T) {
+	// GIVEN
+	cartridge := newFlashTestMBC6()
+
+	// WHEN
+	sendFlashCommand(cartridge, 0x90)
+	manufacturer := readFlash(cartridge, 0x0000)
+	device := readFlash(cartridge, 0x0001)
+	writeFlash(cartridge, 0x0000, 0xF0)
+
+	// THEN
+	assert.Equal(t, byte(mbc6FlashManufacturer), manufacturer)
+	assert.Equal(t, byte(mbc6FlashDevice), device)
+	assert.Equal(t, byte(0xFF), readFlash(cartridge, 0x0000))
+}
+
+func TestNewCartridge_mbc6(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0x20, 0x02, 0x03)
+	saveGame := make([]byte, mbc6RAMSize+mbc6FlashSize)
+	saveGame[0x10] = 0x42
+	saveGame[mbc6RAMSize+0x20] = 0x24
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, saveGame)
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &mbc6{}, cartridge)
+	assert.Equal(t, saveGame, cartridge.RAM())
+}
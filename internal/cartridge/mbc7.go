@@ -0,0 +1,324 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"gameboy-emulator/internal/util"
+)
+
+// Values of the accelerometer registers when lying flat and the change caused by tilting by 90 degrees (1g)
+const (
+	accelerometerCenter = 0x81D0
+	accelerometerRange  = 0x70
+)
+
+// Size of the 93LC56 EEPROM organized as 128 words of 16 bits
+const eepromSize = 0x100
+
+// States of the serial protocol of the EEPROM
+const (
+	eepromIdle    = iota // waiting for the start bit
+	eepromCommand        // receiving opcode and address
+	eepromData           // receiving the data to write
+	eepromRead           // sending data
+	eepromDone           // command executed, waiting for chip select going low
+)
+
+// MBC7 supports up to 2 MiB ROM and comes with a two-axis accelerometer and a 93LC56 serial EEPROM instead of RAM.
+// Both are accessed through registers in the range A000–AFFF, which is only enabled after writing 0x0A to
+// 0000–1FFF and 0x40 to 4000–5FFF.
+//
+// The EEPROM is stored as 128 little endian words in the RAM of the cartridge, so it is persisted like RAM.
+//
+// Source: https://gbdev.io/pandocs/MBC7.html
+type mbc7 struct {
+	*cartridgeCore
+
+	romb        byte
+	ramEnabled1 bool
+	ramEnabled2 bool
+
+	// Tilt set by the user and the values of the accelerometer latched from it
+	tiltX  float64
+	tiltY  float64
+	accelX uint16
+	accelY uint16
+
+	// Pins of the EEPROM: chip select, clock, data in and data out
+	cs bool
+	sk bool
+	di bool
+	do bool
+
+	eepromState    byte
+	eepromShift    uint16 // bits received in the current state
+	eepromBits     int    // number of bits received or still to send in the current state
+	eepromAddress  byte
+	eepromWriteAll bool
+	eepromEnabled  bool // erase and write commands are only executed after EWEN
+}
+
+func newMBC7(core *cartridgeCore) Cartridge {
+	m := &mbc7{
+		cartridgeCore: core,
+		romb:          1,
+		accelX:        0x8000,
+		accelY:        0x8000,
+		do:            true,
+	}
+	m.cartridgeCore.ram = make([]byte, eepromSize)
+	for i := range m.ram { // an erased EEPROM contains all ones
+		m.ram[i] = 0xFF
+	}
+	m.load()
+	return m
+}
+
+func (mbc *mbc7) Reset() {
+	mbc.romb = 1
+	mbc.ramEnabled1 = false
+	mbc.ramEnabled2 = false
+	mbc.accelX = 0x8000
+	mbc.accelY = 0x8000
+	mbc.cs = false
+	mbc.sk = false
+	mbc.do = true
+	mbc.eepromState = eepromIdle
+	mbc.eepromEnabled = false
+}
+
+// SetTilt sets the tilt of the cartridge, which is latched into the accelerometer registers by the game
+func (mbc *mbc7) SetTilt(x, y float64) {
+	mbc.tiltX = x
+	mbc.tiltY = y
+}
+
+func (mbc *mbc7) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		physicalAddress := (uint32(mbc.romb)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *mbc7) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	return mbc.bankOf(uint32(mbc.romb) << 14)
+}
+
+func (mbc *mbc7) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x2000: // first RAM enable
+		mbc.ramEnabled1 = data == 0x0A
+		if !mbc.ramEnabled1 {
+			mbc.ramEnabled2 = false
+		}
+
+	case address < 0x4000: // ROM bank
+		mbc.romb = data
+
+	case address < 0x6000: // second RAM enable, requires the first one
+		if mbc.ramEnabled1 {
+			mbc.ramEnabled2 = data == 0x40
+		}
+	}
+}
+
+func (mbc *mbc7) WriteRAM(address uint16, data byte) {
+	// Registers are only mapped to A000–AFFF and repeated every 0x100 bytes
+	if address >= 0x1000 || !mbc.ramEnabled1 || !mbc.ramEnabled2 {
+		return
+	}
+
+	switch address & 0xF0 {
+	case 0x00: // erase the latched values
+		if data == 0x55 {
+			mbc.accelX = 0x8000
+			mbc.accelY = 0x8000
+		}
+	case 0x10: // latch the accelerometer, only works after erasing
+		if data == 0xAA && mbc.accelX == 0x8000 && mbc.accelY == 0x8000 {
+			mbc.accelX = uint16(accelerometerCenter + int(mbc.tiltX*accelerometerRange))
+			mbc.accelY = uint16(accelerometerCenter + int(mbc.tiltY*accelerometerRange))
+		}
+	case 0x80:
+		mbc.writeEEPROMPins(data)
+	}
+}
+
+func (mbc *mbc7) RAMBank(address uint16) int {
+	return -1 // the EEPROM and the accelerometer are accessed through registers
+}
+
+func (mbc *mbc7) ReadRAM(address uint16) byte {
+	if address >= 0x1000 || !mbc.ramEnabled1 || !mbc.ramEnabled2 {
+		return 0xFF
+	}
+
+	switch address & 0xF0 {
+	case 0x20:
+		return byte(mbc.accelX)
+	case 0x30:
+		return byte(mbc.accelX >> 8)
+	case 0x40:
+		return byte(mbc.accelY)
+	case 0x50:
+		return byte(mbc.accelY >> 8)
+	case 0x60:
+		return 0x00
+	case 0x80:
+		var pins byte
+		if mbc.cs {
+			util.SetBit(&pins, 7)
+		}
+		if mbc.sk {
+			util.SetBit(&pins, 6)
+		}
+		if mbc.di {
+			util.SetBit(&pins, 1)
+		}
+		if mbc.do {
+			util.SetBit(&pins, 0)
+		}
+		return pins
+	}
+	return 0xFF
+}
+
+// writeEEPROMPins sets the pins of the EEPROM. Data is transferred with the rising edge of the clock while chip
+// select is high. Commands start with a one bit, followed by a 2-bit opcode and an 8-bit address.
+func (mbc *mbc7) writeEEPROMPins(data byte) {
+	cs := util.BitIsSet8(data, 7)
+	sk := util.BitIsSet8(data, 6)
+	mbc.di = util.BitIsSet8(data, 1)
+
+	risingEdge := sk && !mbc.sk
+	mbc.cs = cs
+	mbc.sk = sk
+
+	if !cs {
+		mbc.eepromState = eepromIdle
+		mbc.do = true
+		return
+	}
+	if !risingEdge {
+		return
+	}
+
+	bit := uint16(0)
+	if mbc.di {
+		bit = 1
+	}
+
+	switch mbc.eepromState {
+	case eepromIdle:
+		if bit == 1 { // start bit
+			mbc.eepromState = eepromCommand
+			mbc.eepromShift = 0
+			mbc.eepromBits = 0
+		}
+
+	case eepromCommand:
+		mbc.eepromShift = mbc.eepromShift<<1 | bit
+		if mbc.eepromBits++; mbc.eepromBits == 10 {
+			mbc.executeEEPROMCommand(byte(mbc.eepromShift>>8), byte(mbc.eepromShift))
+		}
+
+	case eepromData:
+		mbc.eepromShift = mbc.eepromShift<<1 | bit
+		if mbc.eepromBits++; mbc.eepromBits == 16 {
+			mbc.writeEEPROM(mbc.eepromShift)
+			mbc.eepromState = eepromDone
+			mbc.do = true // ready
+		}
+
+	case eepromRead:
+		mbc.do = mbc.eepromShift&0x8000 != 0
+		mbc.eepromShift <<= 1
+		if mbc.eepromBits--; mbc.eepromBits == 0 { // sequential read continues with the next word
+			mbc.eepromAddress = (mbc.eepromAddress + 1) & 0x7F
+			mbc.eepromShift = mbc.readWord(mbc.eepromAddress)
+			mbc.eepromBits = 16
+		}
+	}
+}
+
+func (mbc *mbc7) executeEEPROMCommand(opcode byte, address byte) {
+	mbc.eepromAddress = address & 0x7F
+	mbc.eepromState = eepromDone
+	mbc.eepromBits = 0
+	mbc.eepromShift = 0
+
+	switch opcode {
+	case 0x00: // extended commands, encoded in the upper bits of the address
+		switch address >> 6 {
+		case 0x00: // EWDS: disable erase and write
+			mbc.eepromEnabled = false
+		case 0x01: // WRAL: write all words
+			mbc.eepromState = eepromData
+			mbc.eepromWriteAll = true
+		case 0x02: // ERAL: erase all words
+			if mbc.eepromEnabled {
+				for i := range mbc.ram {
+					mbc.ram[i] = 0xFF
+				}
+			}
+		case 0x03: // EWEN: enable erase and write
+			mbc.eepromEnabled = true
+		}
+
+	case 0x01: // WRITE
+		mbc.eepromState = eepromData
+		mbc.eepromWriteAll = false
+
+	case 0x02: // READ, a dummy zero bit precedes the data
+		mbc.eepromState = eepromRead
+		mbc.eepromShift = mbc.readWord(mbc.eepromAddress)
+		mbc.eepromBits = 16
+		mbc.do = false
+
+	case 0x03: // ERASE
+		if mbc.eepromEnabled {
+			binary.LittleEndian.PutUint16(mbc.ram[2*int(mbc.eepromAddress):], 0xFFFF)
+		}
+	}
+}
+
+func (mbc *mbc7) writeEEPROM(value uint16) {
+	if !mbc.eepromEnabled {
+		return
+	}
+
+	if !mbc.eepromWriteAll {
+		binary.LittleEndian.PutUint16(mbc.ram[2*int(mbc.eepromAddress):], value)
+		return
+	}
+	for i := 0; i < len(mbc.ram); i += 2 {
+		binary.LittleEndian.PutUint16(mbc.ram[i:], value)
+	}
+}
+
+func (mbc *mbc7) readWord(address byte) uint16 {
+	return binary.LittleEndian.Uint16(mbc.ram[2*int(address):])
+}
+
+func (mbc *mbc7) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.romb, mbc.ramEnabled1, mbc.ramEnabled2, mbc.tiltX, mbc.tiltY, mbc.accelX, mbc.accelY,
+		mbc.cs, mbc.sk, mbc.di, mbc.do,
+		mbc.eepromState, mbc.eepromShift, mbc.eepromBits, mbc.eepromAddress, mbc.eepromWriteAll,
+		mbc.eepromEnabled)
+}
+
+func (mbc *mbc7) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.romb, &mbc.ramEnabled1, &mbc.ramEnabled2, &mbc.tiltX, &mbc.tiltY, &mbc.accelX, &mbc.accelY,
+		&mbc.cs, &mbc.sk, &mbc.di, &mbc.do,
+		&mbc.eepromState, &mbc.eepromShift, &mbc.eepromBits, &mbc.eepromAddress, &mbc.eepromWriteAll,
+		&mbc.eepromEnabled)
+}
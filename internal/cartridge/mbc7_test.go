@@ -0,0 +1,161 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+)
+
+// newTestMBC7 creates a MBC7 cartridge with the registers enabled
+func newTestMBC7(core *cartridgeCore) *mbc7 {
+	core.header.Type = 0x22
+	cartridge := newMBC7(core).(*mbc7)
+	cartridge.HandleBanking(0x0000, 0x0A)
+	cartridge.HandleBanking(0x4000, 0x40)
+	return cartridge
+}
+
+// sendEEPROMBits clocks the given bits into the EEPROM, most significant bit first
+func sendEEPROMBits(cartridge Cartridge, value uint16, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		data := byte(0x80) | byte(value>>i&1)<<1
+		cartridge.WriteRAM(0x0080, data)
+		cartridge.WriteRAM(0x0080, data|0x40)
+	}
+}
+
+// sendEEPROMCommand selects the EEPROM and sends the start bit, the opcode and the address
+func sendEEPROMCommand(cartridge Cartridge, opcode byte, address byte) {
+	cartridge.WriteRAM(0x0080, 0x00)
+	cartridge.WriteRAM(0x0080, 0x80)
+	sendEEPROMBits(cartridge, 1<<10|uint16(opcode)<<8|uint16(address), 11)
+}
+
+// receiveEEPROMBits clocks the given number of bits out of the EEPROM
+func receiveEEPROMBits(cartridge Cartridge, bits int) uint16 {
+	var value uint16
+	for i := 0; i < bits; i++ {
+		cartridge.WriteRAM(0x0080, 0x80)
+		cartridge.WriteRAM(0x0080, 0xC0)
+		value = value<<1 | uint16(cartridge.ReadRAM(0x0080)&0x01)
+	}
+	return value
+}
+
+func TestMbc7Accelerometer(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+	cartridge.SetTilt(1, -0.5)
+
+	// WHEN
+	cartridge.WriteRAM(0x0000, 0x55)
+	cartridge.WriteRAM(0x0010, 0xAA)
+
+	// THEN
+	assert.Equal(t, byte(0x40), cartridge.ReadRAM(0x0020))
+	assert.Equal(t, byte(0x82), cartridge.ReadRAM(0x0030))
+	assert.Equal(t, byte(0x98), cartridge.ReadRAM(0x0040))
+	assert.Equal(t, byte(0x81), cartridge.ReadRAM(0x0050))
+}
+
+func TestMbc7Accelerometer_latchRequiresErase(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+	cartridge.WriteRAM(0x0000, 0x55)
+	cartridge.WriteRAM(0x0010, 0xAA)
+	cartridge.SetTilt(1, 1)
+
+	// WHEN
+	cartridge.WriteRAM(0x0010, 0xAA)
+
+	// THEN
+	assert.Equal(t, byte(0xD0), cartridge.ReadRAM(0x0020))
+	assert.Equal(t, byte(0x81), cartridge.ReadRAM(0x0030))
+}
+
+func TestMbc7ReadRAM_disabled(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+
+	// WHEN
+	cartridge.HandleBanking(0x4000, 0x00)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), cartridge.ReadRAM(0x0030))
+}
+
+func TestMbc7EEPROM_writeAndRead(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+	sendEEPROMCommand(cartridge, 0x00, 0xC0) // EWEN
+
+	// WHEN
+	sendEEPROMCommand(cartridge, 0x01, 0x05) // WRITE
+	sendEEPROMBits(cartridge, 0x1234, 16)
+	sendEEPROMCommand(cartridge, 0x02, 0x05) // READ
+
+	// THEN
+	assert.Equal(t, uint16(0x1234), receiveEEPROMBits(cartridge, 16))
+	assert.Equal(t, uint16(0xFFFF), receiveEEPROMBits(cartridge, 16)) // next word
+	assert.Equal(t, []byte{0x34, 0x12}, cartridge.RAM()[10:12])
+}
+
+func TestMbc7EEPROM_writeProtected(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+
+	// WHEN
+	sendEEPROMCommand(cartridge, 0x01, 0x00) // WRITE without EWEN
+	sendEEPROMBits(cartridge, 0x0000, 16)
+
+	// THEN
+	assert.Equal(t, []byte{0xFF, 0xFF}, cartridge.RAM()[0:2])
+}
+
+func TestMbc7EEPROM_eraseAll(t *testing.T) {
+	// GIVEN
+	cartridge := newTestMBC7(newTestCore(make([]byte, 0x8000), 0))
+	sendEEPROMCommand(cartridge, 0x00, 0xC0) // EWEN
+	sendEEPROMCommand(cartridge, 0x00, 0x40) // WRAL
+	sendEEPROMBits(cartridge, 0xABCD, 16)
+
+	// WHEN
+	sendEEPROMCommand(cartridge, 0x00, 0x80) // ERAL
+
+	// THEN
+	assert.Equal(t, []byte{0xFF, 0xFF}, cartridge.RAM()[0xFE:])
+}
+
+func TestMbc7SaveLoad(t *testing.T) {
+	// GIVEN
+	core := newTestCore(make([]byte, 0x8000), 0)
+	core.saveGamePath = filepath.Join(t.TempDir(), "game.sav")
+	cartridge := newTestMBC7(core)
+	sendEEPROMCommand(cartridge, 0x00, 0xC0) // EWEN
+	sendEEPROMCommand(cartridge, 0x01, 0x7F) // WRITE
+	sendEEPROMBits(cartridge, 0xBEEF, 16)
+
+	// WHEN
+	cartridge.Save()
+	reloadedCore := newTestCore(make([]byte, 0x8000), 0)
+	reloadedCore.saveGamePath = core.saveGamePath
+	reloaded := newTestMBC7(reloadedCore)
+
+	// THEN
+	require.Len(t, reloaded.RAM(), eepromSize)
+	assert.Equal(t, []byte{0xEF, 0xBE}, reloaded.RAM()[0xFE:])
+}
+
+func TestNewCartridge_mbc7(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0x22, 0x00, 0x00)
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Implements(t, (*Tilter)(nil), cartridge)
+	assert.Len(t, cartridge.RAM(), eepromSize)
+}
@@ -0,0 +1,194 @@
+package cartridge
+
+import "gameboy-emulator/internal/util"
+
+// MMM01 is used by multi-game compilation carts with up to 8 MiB ROM and 128 KiB RAM. It starts unmapped, showing
+// the menu stored in the last 32 KiB of the ROM. The menu configures the outer bank bits of the selected game and
+// which bits the game may change itself, then sets the map enable bit. From then on, MMM01 behaves like an MBC1
+// restricted to the selected game until the next power cycle.
+//
+// Source: https://gbdev.io/pandocs/MMM01.html
+type mmm01 struct {
+	*cartridgeCore
+
+	mapped     bool
+	ramEnabled bool
+
+	romLow  byte // bits 0–4 of the ROM bank, MBC1 register BANK1
+	romMid  byte // bits 5–6 of the ROM bank, only writable while unmapped
+	romHigh byte // bits 7–8 of the ROM bank, only writable while unmapped
+	romMask byte // bits 1–4 of romLow which can't be changed once mapped
+
+	ramLow  byte // bits 0–1 of the RAM bank, MBC1 register BANK2
+	ramHigh byte // bits 2–3 of the RAM bank, only writable while unmapped
+	ramMask byte // bits of ramLow which can't be changed once mapped
+
+	mode         byte // MBC1 banking mode
+	modeWritable bool // the game may change the banking mode
+	multiplex    bool // BANK2 selects bits 5–6 of the ROM bank like on MBC1 carts with 1 MiB ROM or more
+}
+
+// Size of the menu of MMM01 carts at the end of the ROM
+const mmm01MenuSize = 0x8000
+
+// mmm01MenuHeader returns the header of the menu of MMM01 carts. Dumps of these carts start with the first game,
+// whose header names its own MBC, so they are identified by the header at the start of the last 32 KiB instead.
+func mmm01MenuHeader(rom []byte) (Header, bool) {
+	if len(rom) < 2*mmm01MenuSize {
+		return Header{}, false
+	}
+
+	header, err := ParseHeader(rom[len(rom)-mmm01MenuSize:])
+	if err != nil || header.Type < 0x0B || header.Type > 0x0D {
+		return Header{}, false
+	}
+	return header, true
+}
+
+func newMMM01(core *cartridgeCore) Cartridge {
+	mbc := &mmm01{cartridgeCore: core}
+	mbc.load()
+	return mbc
+}
+
+func (mbc *mmm01) Reset() {
+	*mbc = mmm01{cartridgeCore: mbc.cartridgeCore}
+}
+
+func (mbc *mmm01) ReadROM(address uint16) byte {
+	if address >= 0x8000 {
+		return 0xFF // outside of the ROM area
+	}
+
+	physicalAddress := (mbc.romBank(address)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+	return (*mbc.rom)[physicalAddress]
+}
+
+func (mbc *mmm01) ROMBank(address uint16) int {
+	return mbc.bankOf(mbc.romBank(address) << 14)
+}
+
+// romBank returns the ROM bank mapped to the given address. While unmapped, the last 32 KiB of the largest possible
+// ROM are mapped, which are the last 32 KiB of the actual ROM after masking.
+func (mbc *mmm01) romBank(address uint16) uint32 {
+	if !mbc.mapped {
+		if address < 0x4000 {
+			return 0x1FE
+		}
+		return 0x1FF
+	}
+
+	mask := mbc.romMask << 1
+	mid := mbc.romMid
+	if mbc.multiplex {
+		mid = mbc.ramLow
+	}
+
+	if address < 0x4000 {
+		if mbc.multiplex && mbc.mode == 0 {
+			mid = 0
+		}
+		return uint32(mbc.romHigh)<<7 | uint32(mid)<<5 | uint32(mbc.romLow&mask)
+	}
+
+	low := mbc.romLow
+	if low&^mask == 0 { // like MBC1, the bits the game can change must never be all zeroes
+		low |= 0x01
+	}
+	return uint32(mbc.romHigh)<<7 | uint32(mid)<<5 | uint32(low)
+}
+
+func (mbc *mmm01) HandleBanking(address uint16, data byte) {
+	switch {
+	case address < 0x2000: // Enable/Disable RAM, RAM bank mask and map enable
+		mbc.ramEnabled = data&0x0F == 0x0A
+		if !mbc.mapped {
+			mbc.ramMask = data >> 4 & 0x03
+			mbc.mapped = util.BitIsSet8(data, 6)
+		}
+
+	case address < 0x4000: // ROM bank
+		if mbc.mapped {
+			mask := mbc.romMask << 1
+			mbc.romLow = mbc.romLow&mask | data&0x1F&^mask
+		} else {
+			mbc.romLow = data & 0x1F
+			mbc.romMid = data >> 5 & 0x03
+		}
+
+	case address < 0x6000: // RAM bank, outer ROM and RAM bank while unmapped
+		if mbc.mapped {
+			mbc.ramLow = mbc.ramLow&mbc.ramMask | data&0x03&^mbc.ramMask
+		} else {
+			mbc.ramLow = data & 0x03
+			mbc.ramHigh = data >> 2 & 0x03
+			mbc.romHigh = data >> 4 & 0x03
+			mbc.modeWritable = util.BitIsSet8(data, 6)
+		}
+
+	case address < 0x8000: // banking mode, ROM bank mask and multiplexing while unmapped
+		if !mbc.mapped || mbc.modeWritable {
+			mbc.mode = data & 0x01
+		}
+		if !mbc.mapped {
+			mbc.romMask = data >> 2 & 0x0F
+			mbc.multiplex = util.BitIsSet8(data, 6)
+		}
+	}
+}
+
+func (mbc *mmm01) WriteRAM(address uint16, data byte) {
+	if address >= 0x2000 {
+		return // outside of the RAM area
+	}
+
+	// If RAM is not enabled (or not present) writes are simply ignored
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return
+	}
+	mbc.ram[mbc.physicalRAMAddress(address)] = data
+}
+
+func (mbc *mmm01) RAMBank(address uint16) int {
+	if address >= 0x2000 || !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return -1
+	}
+	return int(mbc.physicalRAMAddress(address) >> 13)
+}
+
+func (mbc *mmm01) ReadRAM(address uint16) byte {
+	if address >= 0x2000 {
+		return 0xFF // outside of the RAM area
+	}
+
+	// If RAM is not enabled (or not present) reads return 0xFF
+	if !mbc.ramEnabled || len(mbc.ram) == 0 {
+		return 0xFF
+	}
+	return mbc.ram[mbc.physicalRAMAddress(address)]
+}
+
+func (mbc *mmm01) physicalRAMAddress(address uint16) uint32 {
+	low := mbc.ramLow
+	switch {
+	case mbc.multiplex:
+		low = mbc.romMid
+	case mbc.mode == 0:
+		low = 0
+	}
+
+	bank := uint32(mbc.ramHigh)<<2 | uint32(low)
+	return (bank<<13 | uint32(address&0x1FFF)) & uint32(len(mbc.ram)-1)
+}
+
+func (mbc *mmm01) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.mapped, mbc.ramEnabled, mbc.romLow, mbc.romMid, mbc.romHigh, mbc.romMask,
+		mbc.ramLow, mbc.ramHigh, mbc.ramMask, mbc.mode, mbc.modeWritable, mbc.multiplex)
+}
+
+func (mbc *mmm01) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.mapped, &mbc.ramEnabled, &mbc.romLow, &mbc.romMid, &mbc.romHigh, &mbc.romMask,
+		&mbc.ramLow, &mbc.ramHigh, &mbc.ramMask, &mbc.mode, &mbc.modeWritable, &mbc.multiplex)
+}
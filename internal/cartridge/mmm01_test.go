@@ -0,0 +1,81 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// newMMM01TestROM creates a 256 KiB ROM whose 16 KiB banks start with their number
+func newMMM01TestROM() []byte {
+	rom := make([]byte, 0x40000)
+	for bank := 0; bank < len(rom)/0x4000; bank++ {
+		rom[bank*0x4000] = byte(bank)
+	}
+	return rom
+}
+
+func TestMmm01ReadROM_unmapped(t *testing.T) {
+	// GIVEN
+	cartridge := newMMM01(newTestCore(newMMM01TestROM(), 0x2000))
+
+	// WHEN
+	low := cartridge.ReadROM(0x0000)
+	high := cartridge.ReadROM(0x4000)
+
+	// THEN
+	assert.Equal(t, byte(14), low)
+	assert.Equal(t, byte(15), high)
+}
+
+func TestMmm01ReadROM_mapped(t *testing.T) {
+	// GIVEN
+	cartridge := newMMM01(newTestCore(newMMM01TestROM(), 0x2000))
+	cartridge.HandleBanking(0x2000, 0x08) // game starts at bank 8
+	cartridge.HandleBanking(0x6000, 0x0E<<2)
+	cartridge.HandleBanking(0x0000, 0x40) // map, the game can only change bits 0–1 of the ROM bank
+
+	// WHEN
+	fixed := cartridge.ReadROM(0x0000)
+	initial := cartridge.ReadROM(0x4000)
+	cartridge.HandleBanking(0x2000, 0x12)
+	switched := cartridge.ReadROM(0x4000)
+	cartridge.HandleBanking(0x0000, 0x00) // can't unmap
+	stillMapped := cartridge.ReadROM(0x4000)
+
+	// THEN
+	assert.Equal(t, byte(8), fixed)
+	assert.Equal(t, byte(9), initial) // like MBC1, bank 0 of the game can't be mapped here
+	assert.Equal(t, byte(10), switched)
+	assert.Equal(t, byte(10), stillMapped)
+}
+
+func TestMmm01Reset(t *testing.T) {
+	// GIVEN
+	cartridge := newMMM01(newTestCore(newMMM01TestROM(), 0x2000))
+	cartridge.HandleBanking(0x0000, 0x40)
+
+	// WHEN
+	cartridge.Reset()
+
+	// THEN
+	assert.Equal(t, byte(15), cartridge.ReadROM(0x4000))
+}
+
+func TestNewCartridge_mmm01(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0x01, 0x03, 0x00) // header of the first game
+	menu := rom[len(rom)-mmm01MenuSize:]
+	menu[typeAddress] = 0x0B
+	menu[romSizeAddress] = 0x03
+	menu[ramSizeAddress] = 0x02
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &mmm01{}, cartridge)
+	assert.Equal(t, byte(0x0B), cartridge.Header().Type)
+	assert.Len(t, cartridge.RAM(), 0x2000)
+}
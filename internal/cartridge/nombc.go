@@ -1,9 +1,5 @@
 package cartridge
 
-import (
-	log "go.uber.org/zap"
-)
-
 // Small games of not more than 32 KiB ROM do not require
 // a MBC chip for ROM banking. The ROM is directly mapped
 // to memory at $0000-7FFF. Optionally up to 8 KiB of RAM could
@@ -16,24 +12,43 @@ type noMBC struct {
 func newNoMBC(core *cartridgeCore) Cartridge {
 	n := &noMBC{cartridgeCore: core}
 	n.ram = make([]byte, 0x2000)
+	n.load()
 	return n
 }
 
+func (mbc *noMBC) Reset() {}
+
 func (mbc *noMBC) ReadROM(address uint16) byte {
 	if address >= 0x8000 {
-		log.L().Panic("Invalid ROM read", log.Uint16("address", address))
+		return 0xFF // outside of the ROM area
 	}
 	return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
 }
 
+func (mbc *noMBC) ROMBank(address uint16) int {
+	return mbc.bankOf(uint32(address))
+}
+
 func (mbc *noMBC) HandleBanking(_ uint16, _ byte) {
 	// no banking
 }
 
 func (mbc *noMBC) WriteRAM(address uint16, data byte) {
-	mbc.ram[address] = data
+	if int(address) < len(mbc.ram) {
+		mbc.ram[address] = data
+	}
+}
+
+func (mbc *noMBC) RAMBank(address uint16) int {
+	if int(address) >= len(mbc.ram) {
+		return -1
+	}
+	return 0
 }
 
 func (mbc *noMBC) ReadRAM(address uint16) byte {
+	if int(address) >= len(mbc.ram) {
+		return 0xFF // outside of the RAM area
+	}
 	return mbc.ram[address]
 }
@@ -8,19 +8,19 @@ import (
 func TestNoMBCReadRom_outOfBounds(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0x8000)
-	cartridge := newNoMBC(&rom)
+	cartridge := newNoMBC(newTestCore(rom, 0))
 
-	// WHEN + THEN
-	assert.PanicsWithValue(t,
-		"Invalid ROM read",
-		func() { cartridge.ReadROM(0x8000) },
-	)
+	// WHEN
+	result := cartridge.ReadROM(0x8000)
+
+	// THEN
+	assert.Equal(t, byte(0xFF), result)
 }
 
 func TestNoMBCReadRom(t *testing.T) {
 	// GIVEN
 	rom := make([]byte, 0x8000)
-	cartridge := newNoMBC(&rom)
+	cartridge := newNoMBC(newTestCore(rom, 0))
 
 	address := uint16(0x5670)
 	expectedValue := uint8(0xDE)
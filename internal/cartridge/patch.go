@@ -0,0 +1,309 @@
+package cartridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	log "go.uber.org/zap"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+)
+
+// Extensions of the patch files PatchImage looks for next to a ROM image, in order of preference
+var patchExtensions = []string{".bps", ".ups", ".ips"}
+
+var (
+	ErrBadPatch         = errors.New("invalid patch")
+	ErrPatchCRCMismatch = errors.New("patch checksum mismatch")
+)
+
+// PatchImage applies the patch sitting next to the ROM image at the given path with the same base name, e.g.
+// "game.ips" for "game.gb" or "game.zip". The ROM image is returned unchanged if there is no patch.
+func PatchImage(imagePath string, rom []byte) ([]byte, error) {
+	for _, ext := range patchExtensions {
+		path := CompanionPath(imagePath, ext)
+		patch, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		patched, err := ApplyPatch(rom, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		log.L().Info("Applied patch", log.String("patch", filepath.Base(path)))
+		return patched, nil
+	}
+	return rom, nil
+}
+
+// ApplyPatch applies an IPS, BPS or UPS patch to the given ROM image, detected by the header of the patch. The ROM
+// image itself isn't modified. The checksums of BPS and UPS patches are validated.
+func ApplyPatch(rom []byte, patch []byte) ([]byte, error) {
+	switch {
+	case bytes.HasPrefix(patch, []byte("PATCH")):
+		return applyIPS(rom, patch)
+	case bytes.HasPrefix(patch, []byte("BPS1")):
+		return applyBPS(rom, patch)
+	case bytes.HasPrefix(patch, []byte("UPS1")):
+		return applyUPS(rom, patch)
+	default:
+		return nil, fmt.Errorf("%w: unknown format", ErrBadPatch)
+	}
+}
+
+// patchReader reads the records of a patch. Reading past the end sets the error and returns zeroes.
+type patchReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *patchReader) bytes(n int) []byte {
+	if r.err != nil || n < 0 || n > len(r.data)-r.pos {
+		r.fail("patch truncated")
+		return make([]byte, max(n, 0))
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *patchReader) byte() byte {
+	return r.bytes(1)[0]
+}
+
+// bigEndian reads an unsigned big endian number of n bytes as used by IPS
+func (r *patchReader) bigEndian(n int) int {
+	value := 0
+	for _, b := range r.bytes(n) {
+		value = value<<8 | int(b)
+	}
+	return value
+}
+
+// varint reads a number in the variable length encoding of BPS and UPS
+func (r *patchReader) varint() int {
+	value, shift := 0, 1
+	for i := 0; r.err == nil; i++ {
+		if i > 8 {
+			r.fail("number too big")
+			break
+		}
+		b := r.byte()
+		value += int(b&0x7F) * shift
+		if b&0x80 != 0 {
+			break
+		}
+		shift <<= 7
+		value += shift
+	}
+	return value
+}
+
+func (r *patchReader) fail(reason string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("%w: %s", ErrBadPatch, reason)
+	}
+}
+
+// resize returns a copy of data with the given size, padded with zeroes
+func resize(data []byte, size int) []byte {
+	resized := make([]byte, size)
+	copy(resized, data)
+	return resized
+}
+
+// applyIPS applies an International Patching System patch. Records either contain the data to write or a run of a
+// single byte. The end marker may be followed by the size to truncate the image to.
+//
+// Source: https://zerosoft.zophar.net/ips.php
+func applyIPS(rom []byte, patch []byte) ([]byte, error) {
+	r := &patchReader{data: patch, pos: 5}
+	target := resize(rom, len(rom))
+
+	for r.err == nil {
+		offset := r.bigEndian(3)
+		if offset == 0x454F46 { // "EOF"
+			if len(patch)-r.pos == 3 {
+				target = resize(target, r.bigEndian(3))
+			}
+			break
+		}
+
+		size := r.bigEndian(2)
+		var data []byte
+		if size == 0 { // run length encoded
+			size = r.bigEndian(2)
+			data = bytes.Repeat([]byte{r.byte()}, size)
+		} else {
+			data = r.bytes(size)
+		}
+		if r.err != nil {
+			break
+		}
+
+		if offset+size > len(target) {
+			target = resize(target, offset+size)
+		}
+		copy(target[offset:], data)
+	}
+	return target, r.err
+}
+
+// readPatchFooter validates the checksum of a BPS or UPS patch and returns the expected checksums of the source and
+// the target
+func readPatchFooter(patch []byte) (source, target uint32, err error) {
+	if len(patch) < 4+12 {
+		return 0, 0, fmt.Errorf("%w: patch truncated", ErrBadPatch)
+	}
+
+	footer := patch[len(patch)-12:]
+	if crc32.ChecksumIEEE(patch[:len(patch)-4]) != binary.LittleEndian.Uint32(footer[8:]) {
+		return 0, 0, fmt.Errorf("%w: patch is corrupt", ErrPatchCRCMismatch)
+	}
+	return binary.LittleEndian.Uint32(footer), binary.LittleEndian.Uint32(footer[4:]), nil
+}
+
+func validateSource(rom []byte, sourceSize int, sourceCRC uint32) error {
+	if len(rom) != sourceSize || crc32.ChecksumIEEE(rom) != sourceCRC {
+		return fmt.Errorf("%w: patch is made for another ROM image", ErrPatchCRCMismatch)
+	}
+	return nil
+}
+
+func validateTarget(target []byte, targetCRC uint32) error {
+	if crc32.ChecksumIEEE(target) != targetCRC {
+		return fmt.Errorf("%w: patched ROM image", ErrPatchCRCMismatch)
+	}
+	return nil
+}
+
+// applyBPS applies a beat patch. Its actions build the target from ranges of the source, of the patch and of the
+// target written so far.
+//
+// Source: https://www.romhacking.net/documents/746/
+func applyBPS(rom []byte, patch []byte) ([]byte, error) {
+	sourceCRC, targetCRC, err := readPatchFooter(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &patchReader{data: patch[:len(patch)-12], pos: 4}
+	sourceSize := r.varint()
+	targetSize := r.varint()
+	r.bytes(r.varint()) // metadata
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := validateSource(rom, sourceSize, sourceCRC); err != nil {
+		return nil, err
+	}
+	if targetSize > 0x8000<<maxROMSizeCode {
+		return nil, fmt.Errorf("%w: target too big", ErrBadPatch)
+	}
+
+	target := make([]byte, targetSize)
+	outputOffset, sourceOffset, targetOffset := 0, 0, 0
+	for r.err == nil && r.pos < len(r.data) {
+		action := r.varint()
+		length := action>>2 + 1
+		if outputOffset+length > targetSize {
+			r.fail("write past the end of the target")
+			break
+		}
+
+		switch action & 0x03 {
+		case 0: // source read
+			if outputOffset+length > len(rom) {
+				r.fail("read past the end of the source")
+				break
+			}
+			copy(target[outputOffset:], rom[outputOffset:outputOffset+length])
+		case 1: // target read
+			copy(target[outputOffset:], r.bytes(length))
+		case 2: // source copy
+			sourceOffset += signedVarint(r.varint())
+			if sourceOffset < 0 || sourceOffset+length > len(rom) {
+				r.fail("copy outside of the source")
+				break
+			}
+			copy(target[outputOffset:], rom[sourceOffset:sourceOffset+length])
+			sourceOffset += length
+		case 3: // target copy, byte by byte as source and destination may overlap
+			targetOffset += signedVarint(r.varint())
+			if targetOffset < 0 || targetOffset >= outputOffset {
+				r.fail("copy outside of the target")
+				break
+			}
+			for i := 0; i < length; i++ {
+				target[outputOffset+i] = target[targetOffset+i]
+			}
+			targetOffset += length
+		}
+		outputOffset += length
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return target, validateTarget(target, targetCRC)
+}
+
+// signedVarint decodes the relative offsets of BPS, whose lowest bit is the sign
+func signedVarint(value int) int {
+	if value&0x01 != 0 {
+		return -(value >> 1)
+	}
+	return value >> 1
+}
+
+// applyUPS applies a Universal Patching System patch. Its records skip unchanged bytes and XOR the changed ones.
+//
+// Source: https://www.romhacking.net/documents/392/
+func applyUPS(rom []byte, patch []byte) ([]byte, error) {
+	sourceCRC, targetCRC, err := readPatchFooter(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &patchReader{data: patch[:len(patch)-12], pos: 4}
+	sourceSize := r.varint()
+	targetSize := r.varint()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := validateSource(rom, sourceSize, sourceCRC); err != nil {
+		return nil, err
+	}
+	if targetSize > 0x8000<<maxROMSizeCode {
+		return nil, fmt.Errorf("%w: target too big", ErrBadPatch)
+	}
+
+	target := resize(rom, targetSize)
+	offset := 0
+	for r.err == nil && r.pos < len(r.data) {
+		offset += r.varint()
+		for r.err == nil {
+			x := r.byte()
+			if x == 0 {
+				offset++
+				break
+			}
+			if offset < targetSize {
+				target[offset] ^= x
+			}
+			offset++
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return target, validateTarget(target, targetCRC)
+}
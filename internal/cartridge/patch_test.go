@@ -0,0 +1,196 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// encodeVarint encodes a number in the variable length encoding of BPS and UPS
+func encodeVarint(value int) []byte {
+	var encoded []byte
+	for {
+		x := byte(value & 0x7F)
+		value >>= 7
+		if value == 0 {
+			return append(encoded, x|0x80)
+		}
+		encoded = append(encoded, x)
+		value--
+	}
+}
+
+// withPatchFooter appends the checksums of source, target and the patch itself
+func withPatchFooter(patch []byte, source []byte, target []byte) []byte {
+	patch = binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(source))
+	patch = binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(target))
+	return binary.LittleEndian.AppendUint32(patch, crc32.ChecksumIEEE(patch))
+}
+
+func TestEncodeVarint(t *testing.T) {
+	for _, value := range []int{0, 1, 0x7F, 0x80, 0x4000, 0x123456} {
+		r := &patchReader{data: encodeVarint(value)}
+		assert.Equal(t, value, r.varint())
+		assert.NoError(t, r.err)
+	}
+}
+
+func TestApplyPatch_ips(t *testing.T) {
+	// GIVEN
+	rom := []byte{0, 1, 2, 3, 4, 5, 6, 7}
+	patch := []byte("PATCH")
+	patch = append(patch, 0x00, 0x00, 0x01, 0x00, 0x02, 0xAA, 0xBB)       // write 2 bytes at 1
+	patch = append(patch, 0x00, 0x00, 0x06, 0x00, 0x00, 0x00, 0x04, 0xCC) // run of 4 bytes at 6
+	patch = append(patch, []byte("EOF")...)
+
+	// WHEN
+	patched, err := ApplyPatch(rom, patch)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 0xAA, 0xBB, 3, 4, 5, 0xCC, 0xCC, 0xCC, 0xCC}, patched)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4, 5, 6, 7}, rom)
+}
+
+func TestApplyPatch_ipsTruncate(t *testing.T) {
+	// GIVEN
+	rom := []byte{0, 1, 2, 3, 4, 5, 6, 7}
+	patch := append([]byte("PATCHEOF"), 0x00, 0x00, 0x04)
+
+	// WHEN
+	patched, err := ApplyPatch(rom, patch)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 2, 3}, patched)
+}
+
+func TestApplyPatch_ipsTruncated(t *testing.T) {
+	// WHEN
+	_, err := ApplyPatch([]byte{0, 1}, []byte("PATCH\x00\x00\x01\x00\x04\xAA"))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrBadPatch)
+}
+
+func TestApplyPatch_bps(t *testing.T) {
+	// GIVEN
+	rom := []byte{0, 1, 2, 3, 4, 5, 6, 7}
+	expected := []byte{0, 1, 0xAA, 0xBB, 6, 7, 0, 1, 0xAA}
+	patch := []byte("BPS1")
+	patch = append(patch, encodeVarint(len(rom))...)
+	patch = append(patch, encodeVarint(len(expected))...)
+	patch = append(patch, encodeVarint(2)...) // metadata
+	patch = append(patch, "{}"...)
+	patch = append(patch, encodeVarint(1<<2|0)...) // source read 2 bytes
+	patch = append(patch, encodeVarint(1<<2|1)...) // target read 2 bytes
+	patch = append(patch, 0xAA, 0xBB)
+	patch = append(patch, encodeVarint(1<<2|2)...) // source copy 2 bytes from 6
+	patch = append(patch, encodeVarint(6<<1)...)
+	patch = append(patch, encodeVarint(2<<2|3)...) // target copy 3 bytes from 0
+	patch = append(patch, encodeVarint(0)...)
+	patch = withPatchFooter(patch, rom, expected)
+
+	// WHEN
+	patched, err := ApplyPatch(rom, patch)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, expected, patched)
+}
+
+func TestApplyPatch_bpsChecksums(t *testing.T) {
+	// GIVEN
+	rom := []byte{0, 1, 2, 3}
+	patch := []byte("BPS1")
+	patch = append(patch, encodeVarint(4)...)
+	patch = append(patch, encodeVarint(4)...)
+	patch = append(patch, encodeVarint(0)...)
+	patch = append(patch, encodeVarint(3<<2|0)...)
+	valid := withPatchFooter(patch, rom, rom)
+	otherSource := withPatchFooter(patch, []byte{9, 9, 9, 9}, rom)
+	otherTarget := withPatchFooter(patch, rom, []byte{9, 9, 9, 9})
+	corrupt := append([]byte{}, valid...)
+	corrupt[len(corrupt)-1] ^= 0xFF
+
+	// WHEN
+	_, errValid := ApplyPatch(rom, valid)
+	_, errSource := ApplyPatch(rom, otherSource)
+	_, errTarget := ApplyPatch(rom, otherTarget)
+	_, errCorrupt := ApplyPatch(rom, corrupt)
+
+	// THEN
+	assert.NoError(t, errValid)
+	assert.ErrorIs(t, errSource, ErrPatchCRCMismatch)
+	assert.ErrorIs(t, errTarget, ErrPatchCRCMismatch)
+	assert.ErrorIs(t, errCorrupt, ErrPatchCRCMismatch)
+}
+
+func TestApplyPatch_ups(t *testing.T) {
+	// GIVEN
+	rom := []byte{0, 1, 2, 3}
+	expected := []byte{0, 1, 0xF2, 0xF3, 0, 0, 0x42}
+	patch := []byte("UPS1")
+	patch = append(patch, encodeVarint(len(rom))...)
+	patch = append(patch, encodeVarint(len(expected))...)
+	patch = append(patch, encodeVarint(2)...) // skip 2 bytes
+	patch = append(patch, 0xF2^2, 0xF3^3, 0x00)
+	patch = append(patch, encodeVarint(1)...) // skip 1 byte after the terminator
+	patch = append(patch, 0x42, 0x00)
+	patch = withPatchFooter(patch, rom, expected)
+
+	// WHEN
+	patched, err := ApplyPatch(rom, patch)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, expected, patched)
+}
+
+func TestApplyPatch_unknownFormat(t *testing.T) {
+	// WHEN
+	_, err := ApplyPatch([]byte{0, 1}, []byte("PAT"))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrBadPatch)
+}
+
+func TestLoadCartridgeImage_patch(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	rom := newTestImage(0x00, 0x00, 0x00)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "game.gb"), rom, 0644))
+	patch := append([]byte("PATCH"), 0x00, 0x01, 0x00, 0x00, 0x01, 0x42)
+	patch = append(patch, "EOF"...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "game.ips"), patch, 0644))
+
+	// WHEN
+	cartridge, err := LoadCartridgeImage(filepath.Join(dir, "game.gb"))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x42), cartridge.ReadROM(0x0100))
+}
+
+func TestPatchImage_archive(t *testing.T) {
+	for _, name := range []string{"game.gb.gz", "game.zip", "game.gbc.gz"} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			dir := t.TempDir()
+			patch := append([]byte("PATCH"), 0x00, 0x00, 0x01, 0x00, 0x01, 0x42)
+			patch = append(patch, "EOF"...)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "game.ips"), patch, 0644))
+
+			// WHEN
+			patched, err := PatchImage(filepath.Join(dir, name), []byte{0, 1, 2})
+
+			// THEN
+			require.NoError(t, err)
+			assert.Equal(t, []byte{0, 0x42, 2}, patched)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package cartridge
+
+import "time"
+
+// The real time clocks of cartridges are driven by a 32.768 kHz crystal, but to keep them in sync with the emulation
+// they are counted in T-cycles (see Cartridge.Tick). Thus, they run in emulated time: paused, fast-forwarded and
+// rewound with the emulation.
+const rtcCyclesPerSecond = 4194304
+
+type (
+	nowProvider func() time.Time
+
+	// rtcFooter is implemented by cartridges with a real time clock, which store the clock in a footer after the RAM
+	// of their save game. Besides the clock, the footer contains the time of the host when it was written, so the
+	// clock can catch up with the time the emulator was turned off.
+	rtcFooter interface {
+		// encodeRTC returns the footer with the given UNIX timestamp of the host, nil if there is no clock
+		encodeRTC(timestamp int64) []byte
+		// decodeRTC restores the clock from the footer and returns the UNIX timestamp stored with it. If the footer
+		// has the wrong size, the clock is left untouched and false is returned.
+		decodeRTC(footer []byte) (timestamp int64, ok bool)
+		// catchUp advances the clock by the given number of seconds passed on the host
+		catchUp(seconds int64)
+	}
+)
+
+// rtcSaveGame returns the RAM followed by the clock footer of the given cartridge
+func (c *cartridgeCore) rtcSaveGame(clock rtcFooter) []byte {
+	return append(c.RAM(), clock.encodeRTC(c.getNow().Unix())...)
+}
+
+// restoreRTCSaveGame restores the RAM and, if the save game has a clock footer, the clock of the given cartridge. If
+// syncing with the host is enabled, the clock catches up with the time passed since the save game was written.
+func (c *cartridgeCore) restoreRTCSaveGame(data []byte, clock rtcFooter) {
+	c.restoreRAM(data)
+
+	timestamp, ok := clock.decodeRTC(data[min(len(c.ram), len(data)):])
+	if !ok || !c.syncRTC {
+		return
+	}
+	if elapsed := c.getNow().Unix() - timestamp; elapsed > 0 {
+		clock.catchUp(elapsed)
+	}
+}
@@ -0,0 +1,181 @@
+package cartridge
+
+import (
+	"bytes"
+	"fmt"
+	log "go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Save files contain the plain RAM like the .sav files of most other emulators, followed by the clock for
+	// cartridges with a real time clock. Save files of older versions with the extension .sgo have the same format.
+	saveGameExtension       = ".sav"
+	legacySaveGameExtension = ".sgo"
+
+	// Number of backups of the save file kept by default, see WithSaveBackups
+	defaultSaveBackups = 3
+)
+
+// WithSaveBackups sets the number of backups of the save file kept next to it. Before a cartridge writes its save
+// file for the first time, the save file of the previous session becomes the first backup (e.g. "game.sav.1") and
+// the older backups are shifted, dropping the oldest one. Defaults to 3, 0 disables backups.
+func WithSaveBackups(n int) Option {
+	return func(o *options) {
+		o.saveBackups = max(n, 0)
+	}
+}
+
+// ImportSaveGame replaces the save game of the cartridge with the contents of the given file, e.g. a .sav file
+// written by another emulator. It is written to the save file of the cartridge with its next save.
+func ImportSaveGame(cart Cartridge, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	cart.(saveGameRestorer).restoreSaveGame(data)
+	return nil
+}
+
+// ExportSaveGame writes the save game of the cartridge to the given file in the .sav format of other emulators
+func ExportSaveGame(cart Cartridge, path string) error {
+	return writeFileAtomic(path, cart.RAM())
+}
+
+// saveGamePath returns the path of the save file of the ROM image at the given path, see CompanionPath
+func saveGamePath(imagePath string) string {
+	return CompanionPath(imagePath, saveGameExtension)
+}
+
+// CompanionPath returns the path of a file belonging to the ROM image at the given path, like its save file or
+// patch. It is named after the image, or after the archive containing it, with the given extension. E.g. "game.gb",
+// "game.zip" and "game.gb.gz" share the save file "game.sav".
+func CompanionPath(imagePath string, extension string) string {
+	base := strings.TrimSuffix(imagePath, filepath.Ext(imagePath))
+	if strings.EqualFold(filepath.Ext(imagePath), ".gz") && isROMImage(base) {
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	return base + extension
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// persist writes the save game of the given cartridge to the save file, unless it is unchanged since the save file
+// was read or written. This keeps RAM which was cleared on purpose, while games which never touch their RAM don't
+// create save files.
+func (c *cartridgeCore) persist(cart interface{ RAM() []byte }) {
+	if c.saveGamePath == "" {
+		return
+	}
+	contents := c.unstamped(cart)
+	if bytes.Equal(contents, c.persisted) {
+		return
+	}
+
+	if !c.backedUp {
+		c.rotateBackups()
+		c.backedUp = true
+	}
+	if err := writeFileAtomic(c.saveGamePath, cart.RAM()); err != nil {
+		log.L().Error("Error writing save file", log.Error(err))
+		return
+	}
+	c.persisted = contents
+}
+
+// unstamped returns the save game of the given cartridge as compared by persist. The timestamp of the host in a
+// clock footer is left out, as it changes every second even if neither the RAM nor the clock did.
+func (c *cartridgeCore) unstamped(cart interface{ RAM() []byte }) []byte {
+	if clock, ok := cart.(rtcFooter); ok {
+		return append(c.RAM(), clock.encodeRTC(0)...)
+	}
+	return cart.RAM()
+}
+
+// loadSaveGame restores the save game of the given cartridge from its save file. Without a save file, the save file
+// of older versions is read instead. If both are missing, e.g. after a crash while rotating the backups, the most
+// recent backup is read.
+func (c *cartridgeCore) loadSaveGame(cart interface {
+	saveGameRestorer
+	RAM() []byte
+}) {
+	if c.saveGamePath == "" {
+		return
+	}
+
+	candidates := []string{
+		c.saveGamePath,
+		strings.TrimSuffix(c.saveGamePath, saveGameExtension) + legacySaveGameExtension,
+	}
+	if c.saveBackups > 0 {
+		candidates = append(candidates, backupPath(c.saveGamePath, 1))
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			log.L().Error("Error reading save file", log.Error(err))
+			break
+		}
+
+		if path != c.saveGamePath {
+			log.L().Info("Restored save game", log.String("path", path))
+		}
+		cart.restoreSaveGame(data)
+		break
+	}
+	c.persisted = c.unstamped(cart)
+}
+
+// rotateBackups shifts the backups of the save file and turns the save file into the first backup
+func (c *cartridgeCore) rotateBackups() {
+	if c.saveBackups == 0 {
+		return
+	}
+	if _, err := os.Stat(c.saveGamePath); err != nil {
+		return // nothing to back up
+	}
+
+	for n := c.saveBackups - 1; n >= 1; n-- {
+		err := os.Rename(backupPath(c.saveGamePath, n), backupPath(c.saveGamePath, n+1))
+		if err != nil && !os.IsNotExist(err) {
+			log.L().Error("Error rotating save file backups", log.Error(err))
+			return
+		}
+	}
+	if err := os.Rename(c.saveGamePath, backupPath(c.saveGamePath, 1)); err != nil {
+		log.L().Error("Error backing up save file", log.Error(err))
+	}
+}
+
+// writeFileAtomic writes the data to a temporary file next to the given path and replaces the file with it, so the
+// file is never left partially written
+func writeFileAtomic(path string, data []byte) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // fails once renamed
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(file.Name(), 0644)
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
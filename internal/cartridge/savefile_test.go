@@ -0,0 +1,202 @@
+package cartridge
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSaveFileTestCore creates a core backed by a save file in a temporary directory
+func newSaveFileTestCore(t *testing.T, backups int) *cartridgeCore {
+	core := newTestCore(make([]byte, 0x8000), 0x2000)
+	core.saveGamePath = filepath.Join(t.TempDir(), "game.sav")
+	core.saveBackups = backups
+	return core
+}
+
+// reloadSaveFileTestCore creates a cartridge backed by the same save file as the given core, like after a restart
+func reloadSaveFileTestCore(core *cartridgeCore) Cartridge {
+	reloaded := newTestCore(make([]byte, 0x8000), 0x2000)
+	reloaded.saveGamePath = core.saveGamePath
+	reloaded.saveBackups = core.saveBackups
+	return newMBC5(reloaded)
+}
+
+func writeTestRAM(cartridge Cartridge, data byte) {
+	cartridge.HandleBanking(0x0000, 0x0A)
+	cartridge.WriteRAM(0x0000, data)
+	cartridge.HandleBanking(0x0000, 0x00)
+}
+
+func TestSave_onlyChanged(t *testing.T) {
+	// GIVEN
+	core := newSaveFileTestCore(t, 0)
+	cartridge := newMBC5(core)
+
+	// WHEN
+	cartridge.Save()
+
+	// THEN
+	_, err := os.Stat(core.saveGamePath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSave_clearedRAM(t *testing.T) {
+	// GIVEN
+	core := newSaveFileTestCore(t, 0)
+	cartridge := newMBC5(core)
+	writeTestRAM(cartridge, 0x42)
+	cartridge.Save()
+
+	// WHEN
+	writeTestRAM(cartridge, 0x00)
+	cartridge.Save()
+
+	// THEN
+	data, err := os.ReadFile(core.saveGamePath)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, 0x2000), data)
+	assert.Equal(t, make([]byte, 0x2000), reloadSaveFileTestCore(core).RAM())
+}
+
+func TestSave_onlyTimestampChanged(t *testing.T) {
+	// GIVEN
+	now := rtcTestTime
+	getNow := func() time.Time { return now }
+	core := newSaveFileTestCore(t, 1)
+	first := newTestClockCartridge(core, 0x10, getNow)
+	writeTestRAM(first, 0x42)
+	first.Save()
+	written, err := os.ReadFile(core.saveGamePath)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	reloaded := newTestCore(make([]byte, 0x8000), 0x2000)
+	reloaded.saveGamePath = core.saveGamePath
+	reloaded.saveBackups = core.saveBackups
+	cartridge := newTestClockCartridge(reloaded, 0x10, getNow)
+
+	// WHEN
+	now = now.Add(time.Minute)
+	cartridge.Save()
+
+	// THEN
+	data, err := os.ReadFile(core.saveGamePath)
+	require.NoError(t, err)
+	assert.Equal(t, written, data)
+	_, err = os.Stat(backupPath(core.saveGamePath, 1))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSave_backups(t *testing.T) {
+	// GIVEN
+	core := newSaveFileTestCore(t, 2)
+	for session := byte(1); session <= 3; session++ {
+		cartridge := reloadSaveFileTestCore(core)
+		writeTestRAM(cartridge, session)
+		cartridge.Save()
+		writeTestRAM(cartridge, session|0x10)
+		cartridge.Save() // only the save file of the previous session is backed up
+	}
+
+	// WHEN
+	current, errCurrent := os.ReadFile(core.saveGamePath)
+	first, errFirst := os.ReadFile(backupPath(core.saveGamePath, 1))
+	second, errSecond := os.ReadFile(backupPath(core.saveGamePath, 2))
+	_, errThird := os.Stat(backupPath(core.saveGamePath, 3))
+
+	// THEN
+	require.NoError(t, errCurrent)
+	require.NoError(t, errFirst)
+	require.NoError(t, errSecond)
+	assert.True(t, os.IsNotExist(errThird))
+	assert.Equal(t, byte(0x13), current[0])
+	assert.Equal(t, byte(0x12), first[0])
+	assert.Equal(t, byte(0x11), second[0])
+}
+
+func TestLoad_fallbacks(t *testing.T) {
+	// GIVEN
+	core := newSaveFileTestCore(t, 1)
+	legacyPath := filepath.Join(filepath.Dir(core.saveGamePath), "game.sgo")
+	require.NoError(t, os.WriteFile(legacyPath, []byte{0x01}, 0644))
+	require.NoError(t, os.WriteFile(backupPath(core.saveGamePath, 1), []byte{0x02}, 0644))
+
+	// WHEN
+	legacy := reloadSaveFileTestCore(core).RAM()
+	require.NoError(t, os.Remove(legacyPath))
+	backup := reloadSaveFileTestCore(core).RAM()
+
+	// THEN
+	assert.Equal(t, byte(0x01), legacy[0])
+	assert.Equal(t, byte(0x02), backup[0])
+}
+
+func TestImportExportSaveGame(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	cartridge := newMBC5(newTestCore(make([]byte, 0x8000), 0x2000))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "import.sav"), []byte{0x42}, 0644))
+
+	// WHEN
+	errImport := ImportSaveGame(cartridge, filepath.Join(dir, "import.sav"))
+	errExport := ExportSaveGame(cartridge, filepath.Join(dir, "export.sav"))
+
+	// THEN
+	require.NoError(t, errImport)
+	require.NoError(t, errExport)
+	exported, err := os.ReadFile(filepath.Join(dir, "export.sav"))
+	require.NoError(t, err)
+	assert.Equal(t, cartridge.RAM(), exported)
+	assert.Equal(t, byte(0x42), exported[0])
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2) // no temporary files left behind
+}
+
+func TestImportSaveGame_shorter(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	cartridge := newMBC5(newTestCore(make([]byte, 0x8000), 0x2000))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.sav"), bytes.Repeat([]byte{0x55}, 0x2000), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.sav"), []byte{0x42}, 0644))
+	require.NoError(t, ImportSaveGame(cartridge, filepath.Join(dir, "old.sav")))
+
+	// WHEN
+	err := ImportSaveGame(cartridge, filepath.Join(dir, "new.sav"))
+
+	// THEN
+	require.NoError(t, err)
+	expected := make([]byte, 0x2000)
+	expected[0] = 0x42
+	assert.Equal(t, expected, cartridge.RAM())
+}
+
+func TestImportSaveGame_withoutFlash(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	cartridge := newMBC6(newTestCore(make([]byte, 0x20000), 0))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "old.sav"), make([]byte, len(cartridge.RAM())), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.sav"), []byte{0x42}, 0644))
+	require.NoError(t, ImportSaveGame(cartridge, filepath.Join(dir, "old.sav")))
+
+	// WHEN
+	err := ImportSaveGame(cartridge, filepath.Join(dir, "new.sav"))
+
+	// THEN
+	require.NoError(t, err)
+	expected := make([]byte, mbc6RAMSize)
+	expected[0] = 0x42
+	expected = append(expected, bytes.Repeat([]byte{0xFF}, mbc6FlashSize)...) // erased flash chip
+	assert.Equal(t, expected, cartridge.RAM())
+}
+
+func TestSaveGamePath(t *testing.T) {
+	assert.Equal(t, "dir/game.sav", saveGamePath("dir/game.gb"))
+	assert.Equal(t, "dir/game.sav", saveGamePath("dir/game.zip"))
+	assert.Equal(t, "dir/game.sav", saveGamePath("dir/game.gb.gz"))
+	assert.Equal(t, "dir/game.sav", saveGamePath("dir/game.GZ"))
+}
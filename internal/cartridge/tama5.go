@@ -0,0 +1,298 @@
+package cartridge
+
+import (
+	"encoding/binary"
+	"gameboy-emulator/internal/util"
+	"time"
+)
+
+// Size of the RAM of TAMA5, which is only accessed through its registers
+const tama5RAMSize = 0x20
+
+// Registers of TAMA5 selected by writing their index to A001
+const (
+	tama5BankLow   = 0x0
+	tama5BankHigh  = 0x1
+	tama5WriteLow  = 0x4
+	tama5WriteHigh = 0x5
+	tama5AddrHigh  = 0x6 // bit 0 is bit 4 of the RAM address, bits 1–3 select the command
+	tama5AddrLow   = 0x7 // writing it executes the command
+	tama5Ready     = 0xA
+	tama5ReadLow   = 0xC
+	tama5ReadHigh  = 0xD
+)
+
+// Commands of TAMA5 selected by bits 1–3 of register tama5AddrHigh
+const (
+	tama5WriteRAMCommand = 0x0
+	tama5ReadRAMCommand  = 0x1
+	tama5WriteRTCCommand = 0x2
+	tama5ReadRTCCommand  = 0x3
+	tama5LatchCommand    = 0x4
+)
+
+// Addresses of the nibbles of the clock. The time is stored as BCD digits, lowest digit first, and is only updated
+// by tama5LatchCommand. The alarm is stored the same way in the second half.
+const (
+	tama5Seconds = 0x00
+	tama5Minutes = 0x02
+	tama5Hours   = 0x04
+	tama5Weekday = 0x06 // single digit, 0 is Sunday
+	tama5Day     = 0x07
+	tama5Month   = 0x09
+	tama5Year    = 0x0B // years since 2000
+
+	tama5AlarmPage    = 0x10
+	tama5AlarmMinutes = 0x12
+	tama5AlarmHours   = 0x14
+	tama5AlarmEnabled = 0x1D // bit 0
+	tama5AlarmFired   = 0x1E // bit 0, cleared by writing to it
+)
+
+// The clock counts the seconds since the start of the year 2000, which is the first year of the year register
+var tama5Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+// Size of the clock footer appended to the save game: a 64-bit UNIX timestamp, the time of the clock as 64-bit
+// seconds since 2000 and the 16 nibbles of the alarm
+const tama5FooterSize = 32
+
+// TAMA5 by Bandai is only used by Game de Hakken!! Tamagotchi Osucchi to Mesucchi. Instead of mapping ROM bank and
+// RAM directly, it is controlled through 4-bit registers: writing to A001 selects a register, A000 reads or writes its
+// value. The 32 bytes of RAM are written and read nibble-wise through these registers as well.
+//
+// The real time clock with alarm is accessed nibble-wise with commands as well. Like the clock of HuC3, the current
+// time is latched into the registers before reading them, and the clock runs in emulated time (see Tick). Writing a
+// digit of the time sets the clock to the time in the registers. The alarm fires once the clock passes the minute
+// of the day of the alarm while it is enabled.
+//
+// Source: https://gbdev.io/pandocs/TAMA5.html
+type tama5 struct {
+	*cartridgeCore
+
+	register  byte // selected register
+	registers [0x10]byte
+	value     byte // result of the last read command
+
+	rtc       [0x20]byte // nibbles, see tama5Seconds
+	clock     int64      // seconds since tama5Epoch
+	rtcCycles uint32     // T-cycles since the last increment of the clock
+}
+
+func newTAMA5(core *cartridgeCore) Cartridge {
+	mbc := &tama5{cartridgeCore: core}
+	mbc.ram = make([]byte, tama5RAMSize)
+	mbc.load()
+	return mbc
+}
+
+func (mbc *tama5) Reset() {
+	mbc.register = 0
+	mbc.registers = [0x10]byte{}
+	mbc.value = 0
+}
+
+func (mbc *tama5) ReadROM(address uint16) byte {
+	switch {
+	case address < 0x4000:
+		return (*mbc.rom)[address&uint16(len(*mbc.rom)-1)]
+	case address < 0x8000:
+		bank := mbc.registers[tama5BankHigh]&0x01<<4 | mbc.registers[tama5BankLow]
+		physicalAddress := (uint32(bank)<<14 | uint32(address&0x3FFF)) & uint32(len(*mbc.rom)-1)
+		return (*mbc.rom)[physicalAddress]
+	default:
+		return 0xFF // outside of the ROM area
+	}
+}
+
+func (mbc *tama5) ROMBank(address uint16) int {
+	if address < 0x4000 {
+		return 0
+	}
+	bank := mbc.registers[tama5BankHigh]&0x01<<4 | mbc.registers[tama5BankLow]
+	return mbc.bankOf(uint32(bank) << 14)
+}
+
+// HandleBanking does nothing, all registers of TAMA5 are in the RAM area
+func (mbc *tama5) HandleBanking(uint16, byte) {}
+
+func (mbc *tama5) WriteRAM(address uint16, data byte) {
+	switch address {
+	case 0x0001: // select register
+		mbc.register = data & 0x0F
+	case 0x0000: // write register
+		mbc.registers[mbc.register] = data & 0x0F
+		if mbc.register == tama5AddrLow {
+			mbc.execute()
+		}
+	}
+}
+
+func (mbc *tama5) RAMBank(address uint16) int {
+	return -1 // the RAM is accessed through registers
+}
+
+func (mbc *tama5) ReadRAM(address uint16) byte {
+	if address != 0x0000 {
+		return 0xFF
+	}
+
+	switch mbc.register {
+	case tama5Ready:
+		return 0xF1
+	case tama5ReadLow:
+		return 0xF0 | mbc.value&0x0F
+	case tama5ReadHigh:
+		return 0xF0 | mbc.value>>4
+	default:
+		return 0xF0
+	}
+}
+
+func (mbc *tama5) ramAddress() byte {
+	return (mbc.registers[tama5AddrHigh]&0x01<<4 | mbc.registers[tama5AddrLow]) & (tama5RAMSize - 1)
+}
+
+// execute runs the command selected by the upper address register
+func (mbc *tama5) execute() {
+	address := mbc.ramAddress()
+
+	switch mbc.registers[tama5AddrHigh] >> 1 {
+	case tama5WriteRAMCommand:
+		mbc.ram[address] = mbc.registers[tama5WriteHigh]<<4 | mbc.registers[tama5WriteLow]
+	case tama5ReadRAMCommand: // the value is read through tama5ReadLow and tama5ReadHigh
+		mbc.value = mbc.ram[address]
+	case tama5WriteRTCCommand:
+		mbc.writeRTC(address, mbc.registers[tama5WriteLow])
+	case tama5ReadRTCCommand:
+		mbc.value = mbc.rtc[address]
+	case tama5LatchCommand:
+		mbc.latch()
+	}
+}
+
+// writeRTC writes a nibble of the clock. Writing a digit of the time sets the clock.
+func (mbc *tama5) writeRTC(address byte, nibble byte) {
+	switch {
+	case address == tama5AlarmFired:
+		mbc.rtc[address] = 0
+	case address >= tama5AlarmPage:
+		mbc.rtc[address] = nibble
+	default:
+		mbc.rtc[address] = nibble
+		mbc.clock = mbc.registerTime()
+		if address <= tama5Seconds+1 {
+			mbc.rtcCycles = 0 // writing the seconds resets the prescaler
+		}
+	}
+}
+
+// latch copies the current time into the registers of the clock
+func (mbc *tama5) latch() {
+	t := time.Unix(tama5Epoch+mbc.clock, 0).UTC()
+	mbc.writeBCD(tama5Seconds, t.Second())
+	mbc.writeBCD(tama5Minutes, t.Minute())
+	mbc.writeBCD(tama5Hours, t.Hour())
+	mbc.rtc[tama5Weekday] = byte(t.Weekday())
+	mbc.writeBCD(tama5Day, t.Day())
+	mbc.writeBCD(tama5Month, int(t.Month()))
+	mbc.writeBCD(tama5Year, t.Year()%100)
+}
+
+// registerTime returns the time in the registers of the clock as seconds since tama5Epoch. Invalid dates are
+// normalized, e.g. month 13 is January of the following year.
+func (mbc *tama5) registerTime() int64 {
+	t := time.Date(2000+mbc.readBCD(tama5Year), time.Month(mbc.readBCD(tama5Month)), mbc.readBCD(tama5Day),
+		mbc.readBCD(tama5Hours), mbc.readBCD(tama5Minutes), mbc.readBCD(tama5Seconds), 0, time.UTC)
+	return t.Unix() - tama5Epoch
+}
+
+func (mbc *tama5) writeBCD(address byte, value int) {
+	mbc.rtc[address] = byte(value % 10)
+	mbc.rtc[address+1] = byte(value / 10 % 10)
+}
+
+func (mbc *tama5) readBCD(address byte) int {
+	return int(mbc.rtc[address+1])*10 + int(mbc.rtc[address])
+}
+
+// Tick counts the T-cycles of the running second
+func (mbc *tama5) Tick() {
+	if mbc.rtcCycles++; mbc.rtcCycles >= rtcCyclesPerSecond {
+		mbc.rtcCycles = 0
+		mbc.advance(1)
+	}
+}
+
+// advance moves the clock forward by the given number of seconds, e.g. for the time the emulator was turned off,
+// and fires the alarm if its time was passed
+func (mbc *tama5) advance(seconds int64) {
+	before := mbc.clock
+	mbc.clock += seconds
+
+	if mbc.rtc[tama5AlarmEnabled]&0x01 == 0 {
+		return
+	}
+
+	const secondsPerDay = 24 * 60 * 60
+	alarmTime := int64(mbc.readBCD(tama5AlarmHours)*3600 + mbc.readBCD(tama5AlarmMinutes)*60)
+	alarm := before - before%secondsPerDay + alarmTime
+	if alarm <= before {
+		alarm += secondsPerDay
+	}
+	if alarm <= mbc.clock {
+		mbc.rtc[tama5AlarmFired] = 0x01
+	}
+}
+
+func (mbc *tama5) RAM() []byte {
+	return mbc.rtcSaveGame(mbc)
+}
+
+func (mbc *tama5) restoreSaveGame(data []byte) {
+	mbc.restoreRTCSaveGame(data, mbc)
+}
+
+// encodeRTC writes the clock and the alarm, see tama5FooterSize
+func (mbc *tama5) encodeRTC(timestamp int64) []byte {
+	footer := make([]byte, tama5FooterSize)
+	binary.LittleEndian.PutUint64(footer[0:], uint64(timestamp))
+	binary.LittleEndian.PutUint64(footer[8:], uint64(mbc.clock))
+	copy(footer[16:], mbc.rtc[tama5AlarmPage:])
+	return footer
+}
+
+func (mbc *tama5) decodeRTC(footer []byte) (int64, bool) {
+	if len(footer) != tama5FooterSize {
+		return 0, false
+	}
+
+	mbc.clock = int64(binary.LittleEndian.Uint64(footer[8:]))
+	for i, nibble := range footer[16:] {
+		mbc.rtc[tama5AlarmPage+i] = nibble & 0x0F
+	}
+	mbc.rtcCycles = 0
+	return int64(binary.LittleEndian.Uint64(footer[0:])), true
+}
+
+// catchUp advances the clock like advance, so an alarm passed in the meantime fires
+func (mbc *tama5) catchUp(seconds int64) {
+	mbc.advance(seconds)
+}
+
+func (mbc *tama5) Save() {
+	mbc.persist(mbc)
+}
+
+func (mbc *tama5) load() {
+	mbc.loadSaveGame(mbc)
+}
+
+func (mbc *tama5) SaveState(s *util.StateWriter) {
+	mbc.cartridgeCore.SaveState(s)
+	s.Write(mbc.register, mbc.registers, mbc.value, mbc.rtc[:], mbc.clock, mbc.rtcCycles)
+}
+
+func (mbc *tama5) LoadState(s *util.StateReader) {
+	mbc.cartridgeCore.LoadState(s)
+	s.Read(&mbc.register, &mbc.registers, &mbc.value, mbc.rtc[:], &mbc.clock, &mbc.rtcCycles)
+}
@@ -0,0 +1,201 @@
+package cartridge
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTAMA5 writes the given value into a register of TAMA5
+func writeTAMA5(cartridge Cartridge, register byte, value byte) {
+	cartridge.WriteRAM(0x0001, register)
+	cartridge.WriteRAM(0x0000, value)
+}
+
+// tama5Command executes a command of TAMA5 on the given address of the RAM or clock and returns the value read
+func tama5Command(cartridge Cartridge, command byte, address byte, value byte) byte {
+	writeTAMA5(cartridge, tama5WriteLow, value&0x0F)
+	writeTAMA5(cartridge, tama5WriteHigh, value>>4)
+	writeTAMA5(cartridge, tama5AddrHigh, command<<1|address>>4)
+	writeTAMA5(cartridge, tama5AddrLow, address&0x0F)
+	cartridge.WriteRAM(0x0001, tama5ReadLow)
+	return cartridge.ReadRAM(0x0000) & 0x0F
+}
+
+// setTAMA5Digits writes the BCD digits of the given value into the clock, lowest digit first
+func setTAMA5Digits(cartridge Cartridge, address byte, value int) {
+	tama5Command(cartridge, tama5WriteRTCCommand, address, byte(value%10))
+	tama5Command(cartridge, tama5WriteRTCCommand, address+1, byte(value/10))
+}
+
+// setTAMA5Time sets the clock to the given time through commands
+func setTAMA5Time(cartridge Cartridge, t time.Time) {
+	setTAMA5Digits(cartridge, tama5Year, t.Year()%100)
+	setTAMA5Digits(cartridge, tama5Month, int(t.Month()))
+	setTAMA5Digits(cartridge, tama5Day, t.Day())
+	setTAMA5Digits(cartridge, tama5Hours, t.Hour())
+	setTAMA5Digits(cartridge, tama5Minutes, t.Minute())
+	setTAMA5Digits(cartridge, tama5Seconds, t.Second())
+}
+
+// readTAMA5Time latches the clock and reads the time through commands
+func readTAMA5Time(cartridge Cartridge) time.Time {
+	tama5Command(cartridge, tama5LatchCommand, 0, 0)
+	digits := func(address byte) int {
+		return int(tama5Command(cartridge, tama5ReadRTCCommand, address+1, 0))*10 +
+			int(tama5Command(cartridge, tama5ReadRTCCommand, address, 0))
+	}
+	return time.Date(2000+digits(tama5Year), time.Month(digits(tama5Month)), digits(tama5Day),
+		digits(tama5Hours), digits(tama5Minutes), digits(tama5Seconds), 0, time.UTC)
+}
+
+func TestTama5ReadROM_bank(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x80000)
+	rom[0x13*0x4000] = 0x42
+	cartridge := newTestClockCartridge(newTestCore(rom, 0), 0xFD, time.Now)
+
+	// WHEN
+	writeTAMA5(cartridge, tama5BankLow, 0x03)
+	writeTAMA5(cartridge, tama5BankHigh, 0x01)
+
+	// THEN
+	assert.Equal(t, byte(0x42), cartridge.ReadROM(0x4000))
+}
+
+func TestTama5RAM(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0), 0xFD, time.Now)
+
+	// WHEN
+	writeTAMA5(cartridge, tama5WriteLow, 0x0B)
+	writeTAMA5(cartridge, tama5WriteHigh, 0x0A)
+	writeTAMA5(cartridge, tama5AddrHigh, 0x01) // write to 0x1X
+	writeTAMA5(cartridge, tama5AddrLow, 0x05)
+
+	writeTAMA5(cartridge, tama5AddrHigh, 0x03) // read from 0x1X
+	writeTAMA5(cartridge, tama5AddrLow, 0x05)
+	cartridge.WriteRAM(0x0001, tama5ReadLow)
+	low := cartridge.ReadRAM(0x0000)
+	cartridge.WriteRAM(0x0001, tama5ReadHigh)
+	high := cartridge.ReadRAM(0x0000)
+
+	// THEN
+	assert.Equal(t, byte(0xFB), low)
+	assert.Equal(t, byte(0xFA), high)
+	assert.Equal(t, byte(0xAB), cartridge.RAM()[0x15])
+}
+
+func TestTama5ReadRAM_ready(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0), 0xFD, time.Now)
+
+	// WHEN
+	cartridge.WriteRAM(0x0001, tama5Ready)
+
+	// THEN
+	assert.Equal(t, byte(0xF1), cartridge.ReadRAM(0x0000))
+}
+
+func TestNewCartridge_tama5(t *testing.T) {
+	// GIVEN
+	rom := newTestImage(0xFD, 0x04, 0x00)
+
+	// WHEN
+	cartridge, err := NewCartridge(rom, []byte{0x42})
+
+	// THEN
+	require.NoError(t, err)
+	assert.IsType(t, &tama5{}, cartridge)
+	assert.Len(t, cartridge.RAM(), tama5RAMSize+tama5FooterSize)
+	assert.Equal(t, byte(0x42), cartridge.RAM()[0])
+}
+
+func TestTama5Clock_setAndRead(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0), 0xFD, time.Now)
+	setTAMA5Time(cartridge, time.Date(2024, time.February, 29, 23, 59, 58, 0, time.UTC))
+
+	// WHEN
+	for i := 0; i < 3*rtcCyclesPerSecond; i++ {
+		cartridge.Tick()
+	}
+	result := readTAMA5Time(cartridge)
+	weekday := tama5Command(cartridge, tama5ReadRTCCommand, tama5Weekday, 0)
+
+	// THEN
+	assert.Equal(t, time.Date(2024, time.March, 1, 0, 0, 1, 0, time.UTC), result)
+	assert.Equal(t, byte(time.Friday), weekday)
+}
+
+func TestTama5Clock_latch(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0), 0xFD, time.Now)
+	setTAMA5Time(cartridge, time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC))
+
+	// WHEN
+	for i := 0; i < 5*rtcCyclesPerSecond; i++ {
+		cartridge.Tick()
+	}
+	notLatched := tama5Command(cartridge, tama5ReadRTCCommand, tama5Seconds, 0)
+	tama5Command(cartridge, tama5LatchCommand, 0, 0)
+	latched := tama5Command(cartridge, tama5ReadRTCCommand, tama5Seconds, 0)
+
+	// THEN
+	assert.Equal(t, byte(0), notLatched)
+	assert.Equal(t, byte(5), latched)
+}
+
+func TestTama5Clock_alarm(t *testing.T) {
+	// GIVEN
+	cartridge := newTestClockCartridge(newTestCore(make([]byte, 0x8000), 0), 0xFD, time.Now)
+	setTAMA5Time(cartridge, time.Date(2024, time.June, 1, 6, 59, 59, 0, time.UTC))
+	setTAMA5Digits(cartridge, tama5AlarmHours, 7)
+	setTAMA5Digits(cartridge, tama5AlarmMinutes, 0)
+	tama5Command(cartridge, tama5WriteRTCCommand, tama5AlarmEnabled, 0x01)
+
+	// WHEN
+	before := tama5Command(cartridge, tama5ReadRTCCommand, tama5AlarmFired, 0)
+	for i := 0; i < rtcCyclesPerSecond; i++ {
+		cartridge.Tick()
+	}
+	fired := tama5Command(cartridge, tama5ReadRTCCommand, tama5AlarmFired, 0)
+	tama5Command(cartridge, tama5WriteRTCCommand, tama5AlarmFired, 0)
+	acknowledged := tama5Command(cartridge, tama5ReadRTCCommand, tama5AlarmFired, 0)
+
+	// THEN
+	assert.Equal(t, byte(0), before)
+	assert.Equal(t, byte(1), fired)
+	assert.Equal(t, byte(0), acknowledged)
+}
+
+func TestTama5SaveLoad(t *testing.T) {
+	// GIVEN
+	now := rtcTestTime
+	getNow := func() time.Time { return now }
+
+	core := newTestCore(make([]byte, 0x8000), 0)
+	core.saveGamePath = filepath.Join(t.TempDir(), "game.sav")
+	cartridge := newTestClockCartridge(core, 0xFD, getNow)
+	setTAMA5Time(cartridge, time.Date(2024, time.June, 1, 7, 30, 0, 0, time.UTC))
+	setTAMA5Digits(cartridge, tama5AlarmHours, 8)
+	tama5Command(cartridge, tama5WriteRTCCommand, tama5AlarmEnabled, 0x01)
+	tama5Command(cartridge, tama5WriteRAMCommand, 0x00, 0x42)
+
+	// WHEN
+	cartridge.Save()
+	now = now.Add(2 * time.Hour)
+
+	reloadedCore := newTestCore(make([]byte, 0x8000), 0)
+	reloadedCore.saveGamePath = core.saveGamePath
+	reloaded := newTestClockCartridge(reloadedCore, 0xFD, getNow)
+
+	// THEN
+	// The clock ran while the emulator was turned off and the alarm fired meanwhile
+	assert.Equal(t, time.Date(2024, time.June, 1, 9, 30, 0, 0, time.UTC), readTAMA5Time(reloaded))
+	assert.Equal(t, byte(1), tama5Command(reloaded, tama5ReadRTCCommand, tama5AlarmFired, 0))
+	require.Len(t, reloaded.RAM(), tama5RAMSize+tama5FooterSize)
+	assert.Equal(t, byte(0x42), reloaded.RAM()[0])
+}
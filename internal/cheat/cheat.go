@@ -0,0 +1,114 @@
+// Package cheat decodes Game Genie and GameShark codes and keeps the cheats of a game.
+//
+// Game Genie codes substitute a byte read from the cartridge ROM, optionally only if the original byte matches a
+// compare byte (which keeps the code from hitting other ROM banks). GameShark codes write a byte to RAM, repeated at
+// the start of each VBlank.
+//
+// Source: https://gbdev.gg8.se/wiki/articles/Game_Genie and https://gbdev.gg8.se/wiki/articles/GameShark
+package cheat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kind is the device a cheat code is made for
+type Kind byte
+
+const (
+	GameGenie Kind = iota
+	GameShark
+)
+
+var ErrMalformedCode = errors.New("malformed cheat code")
+
+// Code is a decoded cheat code
+type Code struct {
+	Text        string // the code as entered, in upper case
+	Description string
+	Enabled     bool
+
+	Kind    Kind
+	Address uint16
+	Value   byte
+
+	// Game Genie codes with 9 digits only substitute the ROM byte if it equals Compare
+	Compare    byte
+	HasCompare bool
+}
+
+// Parse decodes a Game Genie code ("ABC-DEF" or "ABC-DEF-GHI", the dashes are optional) or a GameShark code
+// ("ABCDEFGH"). The code is enabled.
+func Parse(text string) (Code, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	digits := strings.ReplaceAll(text, "-", "")
+	code := Code{Text: text, Enabled: true}
+
+	values := make([]byte, len(digits))
+	for i, digit := range digits {
+		value, err := strconv.ParseUint(string(digit), 16, 4)
+		if err != nil {
+			return Code{}, fmt.Errorf("%w %q: %q is not a hex digit", ErrMalformedCode, text, digit)
+		}
+		values[i] = byte(value)
+	}
+
+	var err error
+	switch {
+	case len(digits) == 8 && !strings.Contains(text, "-"):
+		err = code.decodeGameShark(values)
+	case len(digits) == 6 || len(digits) == 9:
+		err = code.decodeGameGenie(values)
+	default:
+		err = fmt.Errorf("%w %q: neither a Game Genie nor a GameShark code", ErrMalformedCode, text)
+	}
+	if err != nil {
+		return Code{}, err
+	}
+	return code, nil
+}
+
+// decodeGameGenie decodes the digits ABCDEF(GHI): AB is the new value, FCDE the address with F inverted and GI the
+// compare byte, rotated and scrambled. H is not used by the Game Genie.
+func (c *Code) decodeGameGenie(d []byte) error {
+	c.Kind = GameGenie
+	c.Value = d[0]<<4 | d[1]
+	c.Address = uint16(d[5]^0x0F)<<12 | uint16(d[2])<<8 | uint16(d[3])<<4 | uint16(d[4])
+	if c.Address >= 0x8000 {
+		return fmt.Errorf("%w %q: address 0x%04X is outside of the ROM", ErrMalformedCode, c.Text, c.Address)
+	}
+
+	if len(d) == 9 {
+		scrambled := d[6]<<4 | d[8]
+		c.Compare = (scrambled>>2 | scrambled<<6) ^ 0xBA
+		c.HasCompare = true
+	}
+	return nil
+}
+
+// decodeGameShark decodes the digits ABCDEFGH: AB is the type (01, or 8x/9x with a RAM bank, which is ignored as the
+// currently mapped bank is written), CD the value and GHEF the address
+func (c *Code) decodeGameShark(d []byte) error {
+	c.Kind = GameShark
+	codeType := d[0]<<4 | d[1]
+	c.Value = d[2]<<4 | d[3]
+	c.Address = uint16(d[6])<<12 | uint16(d[7])<<8 | uint16(d[4])<<4 | uint16(d[5])
+
+	banked := codeType&0xE0 == 0x80 && codeType&0x0F <= 0x07 // 80–87 and 90–97
+	if codeType > 0x01 && !banked {
+		return fmt.Errorf("%w %q: unknown type %02X", ErrMalformedCode, c.Text, codeType)
+	}
+	if (c.Address < 0xA000 || c.Address >= 0xE000) && (c.Address < 0xFF80 || c.Address == 0xFFFF) {
+		return fmt.Errorf("%w %q: address 0x%04X is not in RAM", ErrMalformedCode, c.Text, c.Address)
+	}
+	return nil
+}
+
+func (k Kind) String() string {
+	if k == GameShark {
+		return "GameShark"
+	}
+	return "Game Genie"
+}
@@ -0,0 +1,59 @@
+package cheat
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse_gameGenie(t *testing.T) {
+	// WHEN
+	code, err := Parse("00a-17b-c49")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "00A-17B-C49", code.Text)
+	assert.Equal(t, GameGenie, code.Kind)
+	assert.Equal(t, uint16(0x4A17), code.Address)
+	assert.Equal(t, byte(0x00), code.Value)
+	assert.True(t, code.HasCompare)
+	assert.Equal(t, byte(0xC8), code.Compare)
+	assert.True(t, code.Enabled)
+}
+
+func TestParse_gameGenieWithoutCompare(t *testing.T) {
+	// WHEN
+	code, err := Parse("3EC-D8F")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0x0CD8), code.Address)
+	assert.Equal(t, byte(0x3E), code.Value)
+	assert.False(t, code.HasCompare)
+}
+
+func TestParse_gameShark(t *testing.T) {
+	// WHEN
+	code, err := Parse("010A38C1")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, GameShark, code.Kind)
+	assert.Equal(t, uint16(0xC138), code.Address)
+	assert.Equal(t, byte(0x0A), code.Value)
+}
+
+func TestParse_malformed(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"00A-17B-C4",  // wrong length
+		"00A-17G-C49", // not hex
+		"00A-170-C49", // address 0xFA17 outside of the ROM
+		"020A38C1",    // unknown GameShark type
+		"010A3880",    // address 0x8038 in VRAM
+		"010A-38C1",   // GameShark codes have no dashes
+	} {
+		_, err := Parse(text)
+		assert.ErrorIs(t, err, ErrMalformedCode, text)
+	}
+}
@@ -0,0 +1,168 @@
+package cheat
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FileExtension is the extension of the cheat files stored next to the ROM images
+const FileExtension = ".cht"
+
+// List holds the cheats of a game. It may be changed while the emulation is running.
+type List struct {
+	mutex sync.Mutex
+	codes []Code
+
+	// enabled codes, replaced as a whole on each change, as reading the ROM must not wait for the mutex
+	active atomic.Pointer[activeCodes]
+}
+
+type activeCodes struct {
+	rom map[uint16][]Code // Game Genie codes by address
+	ram []Code            // GameShark codes
+}
+
+func NewList() *List {
+	l := &List{}
+	l.update()
+	return l
+}
+
+// Add decodes the given code and adds it enabled, see Parse
+func (l *List) Add(text string, description string) error {
+	code, err := Parse(text)
+	if err != nil {
+		return err
+	}
+	code.Description = description
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.codes = append(l.codes, code)
+	l.update()
+	return nil
+}
+
+// Remove removes the code with the given index, see Codes
+func (l *List) Remove(index int) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if index >= 0 && index < len(l.codes) {
+		l.codes = append(l.codes[:index], l.codes[index+1:]...)
+		l.update()
+	}
+}
+
+// SetEnabled enables or disables the code with the given index, see Codes
+func (l *List) SetEnabled(index int, enabled bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if index >= 0 && index < len(l.codes) {
+		l.codes[index].Enabled = enabled
+		l.update()
+	}
+}
+
+// Codes returns all codes in the order they were added
+func (l *List) Codes() []Code {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return append([]Code(nil), l.codes...)
+}
+
+// Active reports whether any code is enabled
+func (l *List) Active() bool {
+	active := l.active.Load()
+	return len(active.rom) > 0 || len(active.ram) > 0
+}
+
+// update rebuilds the enabled codes, the mutex has to be held
+func (l *List) update() {
+	active := &activeCodes{rom: map[uint16][]Code{}}
+	for _, code := range l.codes {
+		switch {
+		case !code.Enabled:
+		case code.Kind == GameGenie:
+			active.rom[code.Address] = append(active.rom[code.Address], code)
+		default:
+			active.ram = append(active.ram, code)
+		}
+	}
+	l.active.Store(active)
+}
+
+// PatchROM returns the byte read from the given ROM address with the enabled Game Genie codes applied
+func (l *List) PatchROM(address uint16, value byte) byte {
+	active := l.active.Load()
+	if len(active.rom) == 0 {
+		return value
+	}
+
+	for _, code := range active.rom[address] {
+		if !code.HasCompare || code.Compare == value {
+			return code.Value
+		}
+	}
+	return value
+}
+
+// RAMWrites returns the enabled GameShark codes to be written to RAM at each VBlank
+func (l *List) RAMWrites() []Code {
+	return l.active.Load().ram
+}
+
+// Load reads a cheat file. Each line holds "on" or "off", the code and an optional description. Empty lines and
+// lines starting with "#" are ignored. A missing file is an empty list.
+func Load(path string) (*List, error) {
+	l := NewList()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) < 2 || (fields[0] != "on" && fields[0] != "off") {
+			return nil, fmt.Errorf("%s:%d: %w: expected \"on|off <code> [description]\"", path, line, ErrMalformedCode)
+		}
+
+		code, err := Parse(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		code.Enabled = fields[0] == "on"
+		code.Description = strings.Join(fields[2:], " ")
+		l.codes = append(l.codes, code)
+	}
+	l.update()
+	return l, nil
+}
+
+// Save writes the cheats to a cheat file, see Load
+func (l *List) Save(path string) error {
+	var buffer bytes.Buffer
+	buffer.WriteString("# on|off <Game Genie or GameShark code> [description]\n")
+	for _, code := range l.Codes() {
+		state := "off"
+		if code.Enabled {
+			state = "on"
+		}
+		fmt.Fprintln(&buffer, strings.TrimSpace(state+" "+code.Text+" "+code.Description))
+	}
+	return os.WriteFile(path, buffer.Bytes(), 0644)
+}
@@ -0,0 +1,106 @@
+package cheat
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestList_PatchROM(t *testing.T) {
+	// GIVEN
+	cheats := NewList()
+	require.NoError(t, cheats.Add("00A-17B-C49", "compare 0xC8"))
+	require.NoError(t, cheats.Add("3EC-D8F", "always"))
+
+	// WHEN
+	matching := cheats.PatchROM(0x4A17, 0xC8)
+	otherBank := cheats.PatchROM(0x4A17, 0x12)
+	always := cheats.PatchROM(0x0CD8, 0x12)
+	otherAddress := cheats.PatchROM(0x0CD9, 0x12)
+	cheats.SetEnabled(1, false)
+	disabled := cheats.PatchROM(0x0CD8, 0x12)
+
+	// THEN
+	assert.Equal(t, byte(0x00), matching)
+	assert.Equal(t, byte(0x12), otherBank)
+	assert.Equal(t, byte(0x3E), always)
+	assert.Equal(t, byte(0x12), otherAddress)
+	assert.Equal(t, byte(0x12), disabled)
+}
+
+func TestList_RAMWrites(t *testing.T) {
+	// GIVEN
+	cheats := NewList()
+	require.NoError(t, cheats.Add("010A38C1", ""))
+	require.NoError(t, cheats.Add("01FF39C1", ""))
+
+	// WHEN
+	cheats.Remove(0)
+
+	// THEN
+	require.Len(t, cheats.RAMWrites(), 1)
+	assert.Equal(t, uint16(0xC139), cheats.RAMWrites()[0].Address)
+	assert.Len(t, cheats.Codes(), 1)
+}
+
+func TestList_Active(t *testing.T) {
+	// GIVEN
+	cheats := NewList()
+	require.NoError(t, cheats.Add("3EC-D8F", ""))
+	require.NoError(t, cheats.Add("010A38C1", ""))
+
+	// WHEN
+	enabled := cheats.Active()
+	cheats.SetEnabled(0, false)
+	gameShark := cheats.Active()
+	cheats.SetEnabled(1, false)
+	disabled := cheats.Active()
+
+	// THEN
+	assert.True(t, enabled)
+	assert.True(t, gameShark)
+	assert.False(t, disabled)
+	assert.False(t, NewList().Active())
+}
+
+func TestList_SaveLoad(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "game"+FileExtension)
+	cheats := NewList()
+	require.NoError(t, cheats.Add("00A-17B-C49", "Infinite lives"))
+	require.NoError(t, cheats.Add("010A38C1", ""))
+	cheats.SetEnabled(0, false)
+
+	// WHEN
+	require.NoError(t, cheats.Save(path))
+	loaded, err := Load(path)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, cheats.Codes(), loaded.Codes())
+	assert.Len(t, loaded.RAMWrites(), 1)
+}
+
+func TestLoad_missingFile(t *testing.T) {
+	// WHEN
+	cheats, err := Load(filepath.Join(t.TempDir(), "missing"+FileExtension))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Empty(t, cheats.Codes())
+}
+
+func TestLoad_malformed(t *testing.T) {
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "game"+FileExtension)
+	require.NoError(t, os.WriteFile(path, []byte("# comment\n\non 010A38C1\non 00A-17\nyes 010A38C1\n"), 0644))
+
+	// WHEN
+	_, err := Load(path)
+
+	// THEN
+	assert.ErrorIs(t, err, ErrMalformedCode)
+	assert.ErrorContains(t, err, ":4:")
+}
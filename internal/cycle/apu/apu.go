@@ -411,3 +411,21 @@ func (a *APU) clearRegisters() {
 	a.WriteNR50(0x0)
 	a.WriteNR51(0x0)
 }
+
+func (a *APU) SaveState(s *util.StateWriter) {
+	a.channel1.SaveState(s)
+	a.channel2.SaveState(s)
+	a.channel3.SaveState(s)
+	a.channel4.SaveState(s)
+	a.frameSequencer.SaveState(s)
+	s.Write(a.ticks, a.panning, a.volumeLeft, a.volumeRight, a.vinLeft, a.vinRight, a.enabled)
+}
+
+func (a *APU) LoadState(s *util.StateReader) {
+	a.channel1.LoadState(s)
+	a.channel2.LoadState(s)
+	a.channel3.LoadState(s)
+	a.channel4.LoadState(s)
+	a.frameSequencer.LoadState(s)
+	s.Read(&a.ticks, &a.panning, &a.volumeLeft, &a.volumeRight, &a.vinLeft, &a.vinRight, &a.enabled)
+}
@@ -1,5 +1,7 @@
 package apu
 
+import "gameboy-emulator/internal/util"
+
 type (
 	FrameSequencer struct {
 		channels []WaveGenerator
@@ -55,3 +57,11 @@ func (f *FrameSequencer) sequencerStep() {
 
 	f.step = (f.step + 1) % 8
 }
+
+func (f *FrameSequencer) SaveState(s *util.StateWriter) {
+	s.Write(f.step, f.tick, f.enabled)
+}
+
+func (f *FrameSequencer) LoadState(s *util.StateReader) {
+	s.Read(&f.step, &f.tick, &f.enabled)
+}
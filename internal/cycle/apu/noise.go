@@ -162,3 +162,13 @@ func (n *Noise) resetFrequencyTimer() {
 
 	n.frequencyTimer = (divisor << n.clockShift) * 16
 }
+
+func (n *Noise) SaveState(s *util.StateWriter) {
+	s.Write(n.lfsr, n.lengthCounter, n.lengthEnable, n.frequencyTimer, n.clockShift, n.use7Bit, n.clockDivider, n.currentSample, n.enabled)
+	n.volumeEnvelope.SaveState(s)
+}
+
+func (n *Noise) LoadState(s *util.StateReader) {
+	s.Read(&n.lfsr, &n.lengthCounter, &n.lengthEnable, &n.frequencyTimer, &n.clockShift, &n.use7Bit, &n.clockDivider, &n.currentSample, &n.enabled)
+	n.volumeEnvelope.LoadState(s)
+}
@@ -162,3 +162,13 @@ func (sq *SquareWave) GetNRx4() byte {
 	}
 	return 0xBF
 }
+
+func (sq *SquareWave) SaveState(s *util.StateWriter) {
+	s.Write(sq.lengthCounter, sq.lengthEnable, sq.period, sq.dutyCycleIndex, sq.frequencyTimer, sq.dutyPosition, sq.ticks, sq.enabled, sq.currentSample)
+	sq.volumeEnvelope.SaveState(s)
+}
+
+func (sq *SquareWave) LoadState(s *util.StateReader) {
+	s.Read(&sq.lengthCounter, &sq.lengthEnable, &sq.period, &sq.dutyCycleIndex, &sq.frequencyTimer, &sq.dutyPosition, &sq.ticks, &sq.enabled, &sq.currentSample)
+	sq.volumeEnvelope.LoadState(s)
+}
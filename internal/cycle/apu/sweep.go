@@ -162,3 +162,13 @@ func (s *SweepableSquareWave) SetNRx4(data byte) {
 func (s *SweepableSquareWave) GetNRx4() byte {
 	return s.squareWave.GetNRx4()
 }
+
+func (s *SweepableSquareWave) SaveState(sw *util.StateWriter) {
+	sw.Write(s.pace, s.subtract, s.individualStep, s.enabled, s.shadowPeriod, s.sweepTimer)
+	s.squareWave.SaveState(sw)
+}
+
+func (s *SweepableSquareWave) LoadState(sr *util.StateReader) {
+	sr.Read(&s.pace, &s.subtract, &s.individualStep, &s.enabled, &s.shadowPeriod, &s.sweepTimer)
+	s.squareWave.LoadState(sr)
+}
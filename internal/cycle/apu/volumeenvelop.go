@@ -94,3 +94,11 @@ func (e *VolumeEnvelope) Disable() {
 func (e *VolumeEnvelope) IsEnabled() bool {
 	return e.enabled
 }
+
+func (e *VolumeEnvelope) SaveState(s *util.StateWriter) {
+	s.Write(e.initial, e.increase, e.sweepPace, e.triggeredPace, e.triggeredIncrease, e.volume, e.enabled, e.periodTimer, e.ticks)
+}
+
+func (e *VolumeEnvelope) LoadState(s *util.StateReader) {
+	s.Read(&e.initial, &e.increase, &e.sweepPace, &e.triggeredPace, &e.triggeredIncrease, &e.volume, &e.enabled, &e.periodTimer, &e.ticks)
+}
@@ -181,3 +181,13 @@ func (w *WaveOutput) GetNRx4() byte {
 func (w *WaveOutput) resetFrequencyTimer() {
 	w.frequencyTimer = (2048 - w.period) * 2
 }
+
+func (w *WaveOutput) SaveState(s *util.StateWriter) {
+	s.Write(w.lengthCounter, w.lengthEnable, w.outputLevel, w.volumeShift, w.period, w.dacOn, w.waveRAM,
+		w.frequencyTimer, w.samplePosition, w.ticks, w.enabled, w.currentSample)
+}
+
+func (w *WaveOutput) LoadState(s *util.StateReader) {
+	s.Read(&w.lengthCounter, &w.lengthEnable, &w.outputLevel, &w.volumeShift, &w.period, &w.dacOn, &w.waveRAM,
+		&w.frequencyTimer, &w.samplePosition, &w.ticks, &w.enabled, &w.currentSample)
+}
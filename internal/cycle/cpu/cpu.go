@@ -1,14 +1,18 @@
 package cpu
 
 import (
+	"errors"
 	"fmt"
 	"gameboy-emulator/internal/cycle/interrupts"
 	"gameboy-emulator/internal/cycle/memory"
+	"gameboy-emulator/internal/opcodes"
 	"gameboy-emulator/internal/util"
 	log "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+var errUndefinedInstruction = errors.New("undefined instruction in instruction register")
+
 const (
 	executing cpuState = iota
 	halted
@@ -34,7 +38,9 @@ type (
 		pc uint16 // Program counter
 		sp uint16 // Stack pointer
 
-		ir              instruction // instruction register
+		ir              func(*CPU) // instruction register
+		irOpCode        byte       // opcode of the instruction in the instruction register
+		irExtended      bool       // true if the instruction register holds a CB prefixed instruction
 		pcOfInstruction uint16
 
 		ops *util.Queue[func(*CPU)]
@@ -45,11 +51,18 @@ type (
 		ticks  byte
 		state  cpuState
 		Cycles uint64
+
+		tracer Tracer // nil unless tracing
 	}
 
-	instruction struct {
-		disassembly string
-		execute     func(*CPU)
+	// Tracer is called with the registers before each instruction is executed, CB prefixed instructions included
+	Tracer func(registers Registers)
+
+	// Registers is a snapshot of the registers of the CPU, e.g. for debuggers
+	Registers struct {
+		A, F, B, C, D, E, H, L byte
+		SP                     uint16
+		PC                     uint16 // address of the instruction executed next
 	}
 
 	cpuState byte
@@ -82,11 +95,12 @@ func (c *CPU) Reset() {
 	c.sp = 0xfffe
 	c.pc = 0x0000
 
-	c.ir = instructions[0x00] // On startup CPU has NOP loaded
+	c.loadInstruction(0x00) // On startup CPU has NOP loaded
 
 	c.ticks = 0
 	c.state = executing
 	c.pcOfInstruction = 0
+	c.Cycles = 0
 	c.ops.Clear()
 }
 
@@ -100,16 +114,20 @@ func (c *CPU) Tick() {
 		c.ticks = 0
 
 		if c.ops.Size() == 0 {
-			if c.ir.execute == nil {
+			if c.ir == nil {
 				log.L().Panic("Undefined instruction", log.String("pc", fmt.Sprintf("0x%04x", c.pcOfInstruction)))
 			}
 
 			if log.L().Level() <= zapcore.InfoLevel {
-				log.L().Info(c.ir.disassembly, log.String("dump",
+				log.L().Info(c.disassembly(), log.String("dump",
 					fmt.Sprintf("A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X PCMEM:%02X,%02X,%02X,%02X",
-						c.a, c.f, c.b, c.c, c.d, c.e, c.h, c.l, c.sp, c.pc-1, c.mmu.Read(c.pc-1), c.mmu.Read(c.pc), c.mmu.Read(c.pc+1), c.mmu.Read(c.pc+2))))
+						c.a, c.f, c.b, c.c, c.d, c.e, c.h, c.l, c.sp, c.pc-1, c.mmu.Peek(c.pc-1), c.mmu.Peek(c.pc), c.mmu.Peek(c.pc+1), c.mmu.Peek(c.pc+2))))
 			}
-			c.ir.execute(c)
+			// The NOP loaded on reset only fetches the first instruction
+			if c.tracer != nil && !c.irExtended && c.Cycles > 0 {
+				c.tracer(c.Registers())
+			}
+			c.ir(c)
 		}
 	case halted:
 		if !c.interrupts.InterruptsPending() {
@@ -129,6 +147,88 @@ func (c *CPU) Tick() {
 	c.Cycles++
 }
 
+// AtInstructionBoundary returns true if the current instruction has been completely executed and the
+// next one has not been started yet. Only then the CPU state can be saved, as the queued micro-operations
+// of an instruction in progress can't be serialized.
+func (c *CPU) AtInstructionBoundary() bool {
+	return c.ops.Size() == 0
+}
+
+// AtInstructionStart returns true if the next instruction is about to be executed, which is where debuggers stop:
+// the CPU is at an instruction boundary, isn't halted and hasn't fetched just the prefix of a CB instruction.
+func (c *CPU) AtInstructionStart() bool {
+	return c.ops.Size() == 0 && c.state == executing && !c.irExtended
+}
+
+// SetTracer sets the tracer called before each instruction, nil stops tracing
+func (c *CPU) SetTracer(tracer Tracer) {
+	c.tracer = tracer
+}
+
+// Halted returns true while the CPU waits for an interrupt after executing HALT.
+func (c *CPU) Halted() bool {
+	return c.state == halted
+}
+
+// Registers returns a snapshot of the registers. PC is the address of the instruction in the instruction register.
+func (c *CPU) Registers() Registers {
+	return Registers{
+		A: c.a, F: byte(c.f), B: c.b, C: c.c, D: c.d, E: c.e, H: c.h, L: c.l,
+		SP: c.sp,
+		PC: c.pcOfInstruction,
+	}
+}
+
+// Instruction returns the opcode and the disassembly of the instruction in the instruction register, e.g. 0xCD and
+// "CALL a16" (see opcodes.Template).
+func (c *CPU) Instruction() (opCode byte, disassembly string) {
+	return c.irOpCode, c.disassembly()
+}
+
+func (c *CPU) disassembly() string {
+	if c.irExtended {
+		return opcodes.ExtendedTemplate(c.irOpCode)
+	}
+	return opcodes.Template(c.irOpCode)
+}
+
+// SaveState writes the registers and the internal state of the CPU. Must only be called at an
+// instruction boundary (see AtInstructionBoundary).
+func (c *CPU) SaveState(s *util.StateWriter) {
+	s.Write(c.a, c.f, c.b, c.c, c.d, c.e, c.h, c.l, c.z, c.w, c.pc, c.sp,
+		c.irOpCode, c.irExtended, c.pcOfInstruction, c.ticks, c.state, c.Cycles)
+}
+
+func (c *CPU) LoadState(s *util.StateReader) {
+	s.Read(&c.a, &c.f, &c.b, &c.c, &c.d, &c.e, &c.h, &c.l, &c.z, &c.w, &c.pc, &c.sp,
+		&c.irOpCode, &c.irExtended, &c.pcOfInstruction, &c.ticks, &c.state, &c.Cycles)
+
+	if c.irExtended {
+		c.loadExtendedInstruction(c.irOpCode)
+	} else {
+		c.loadInstruction(c.irOpCode)
+	}
+
+	if c.ir == nil {
+		s.Fail(errUndefinedInstruction)
+	}
+	c.ops.Clear()
+}
+
+// loadInstruction loads the instruction with the given opcode into the instruction register
+func (c *CPU) loadInstruction(opCode byte) {
+	c.ir = instructions[opCode]
+	c.irOpCode = opCode
+	c.irExtended = false
+}
+
+// loadExtendedInstruction loads the CB prefixed instruction with the given opcode into the instruction register
+func (c *CPU) loadExtendedInstruction(opCode byte) {
+	c.ir = extendedInstructions[opCode]
+	c.irOpCode = opCode
+	c.irExtended = true
+}
+
 func (c *CPU) bc() uint16 {
 	return uint16(c.b)<<8 | uint16(c.c)
 }
@@ -159,3 +259,19 @@ func (c *CPU) writeHL(data uint16) {
 func (c *CPU) wz() uint16 {
 	return uint16(c.w)<<8 | uint16(c.z)
 }
+
+func (r Registers) AF() uint16 {
+	return uint16(r.A)<<8 | uint16(r.F)
+}
+
+func (r Registers) BC() uint16 {
+	return uint16(r.B)<<8 | uint16(r.C)
+}
+
+func (r Registers) DE() uint16 {
+	return uint16(r.D)<<8 | uint16(r.E)
+}
+
+func (r Registers) HL() uint16 {
+	return uint16(r.H)<<8 | uint16(r.L)
+}
@@ -5,267 +5,267 @@ import (
 	"gameboy-emulator/internal/util"
 )
 
-var instructions [256]instruction
+var instructions [256]func(*CPU)
 
 // Do initialization of array in init() to prevent circular dependencies.
 func init() {
-	instructions = [256]instruction{
-		{"NOP", nop},                    // 0x00
-		{"LD BC, nn", ldBCd16},          // 0x01
-		{"LD (BC), A", ldBCpA},          // 0x02
-		{"INC BC", incBC},               // 0x03
-		{"INC B", incB},                 // 0x04
-		{"DEC B", decB},                 // 0x05
-		{"LD B, n", ldBd8},              // 0x06
-		{"RLCA", rlca},                  // 0x07
-		{"LD (nn), SP", ldd16pSP},       // 0x08
-		{"ADD HL, BC", addHLBC},         // 0x09
-		{"LD A, (BC)", ldABCp},          // 0x0A
-		{"DEC BC", decBC},               // 0x0B
-		{"INC C", incC},                 // 0x0C
-		{"DEC C", decC},                 // 0x0D
-		{"LD C, n", ldCd8},              // 0x0E
-		{"RRCA", rrca},                  // 0x0F
-		{"STOP", stop},                  // 0x10
-		{"LD DE, nn", ldDEd16},          // 0x11
-		{"LD (DE), A", ldDEpA},          // 0x12
-		{"INC DE", incDE},               // 0x13
-		{"INC D", incD},                 // 0x14
-		{"DEC D", decD},                 // 0x15
-		{"LD D, n", ldDd8},              // 0x16
-		{"RLA", rla},                    // 0x17
-		{"JR e", jre},                   // 0x18
-		{"ADD HL, DE", addHLDE},         // 0x19
-		{"LD A, (DE)", ldADEp},          // 0x1A
-		{"DEC DE", decDE},               // 0x1B
-		{"INC E", incE},                 // 0x1C
-		{"DEC E", decE},                 // 0x1D
-		{"LD E, n", ldEd8},              // 0x1E
-		{"RRA", rra},                    // 0x1F
-		{"JR NZ, e", jrnze},             // 0x20
-		{"LD HL, nn", ldHLd16},          // 0x21
-		{"LD (HL+), A", ldiHLpA},        // 0x22
-		{"INC HL", incHL},               // 0x23
-		{"INC H", incH},                 // 0x24
-		{"DEC H", decH},                 // 0x25
-		{"LD H, n", ldHd8},              // 0x26
-		{"DAA", daa},                    // 0x27
-		{"JR Z, e", jrze},               // 0x28
-		{"ADD HL, HL", addHLHL},         // 0x29
-		{"LD A, (HL+)", ldiAHLp},        // 0x2A
-		{"DEC HL", decHL},               // 0x2B
-		{"INC L", incL},                 // 0x2C
-		{"DEC L", decL},                 // 0x2D
-		{"LD L, n", ldLd8},              // 0x2E
-		{"CPL", cpl},                    // 0x2F
-		{"JR NC, e", jrnce},             // 0x30
-		{"LD SP, nn", ldSPd16},          // 0x31
-		{"LD (HL-), A", lddHLpA},        // 0x32
-		{"INC SP", incSP},               // 0x33
-		{"INC (HL)", incHLp},            // 0x34
-		{"DEC (HL)", decHLp},            // 0x35
-		{"LD (HL), n", ldHLpd8},         // 0x36
-		{"SCF", scf},                    // 0x37
-		{"JR C, e", jrce},               // 0x38
-		{"ADD HL, SP", addHLSP},         // 0x39
-		{"LD A, (HL-)", lddAHLp},        // 0x3A
-		{"DEC SP", decSP},               // 0x3B
-		{"INC A", incA},                 // 0x3C
-		{"DEC A", decA},                 // 0x3D
-		{"LD A, n", ldAd8},              // 0x3E
-		{"CCF", ccf},                    // 0x3F
-		{"LD B, B", nop},                // 0x40
-		{"LD B, C", ldBC},               // 0x41
-		{"LD B, D", ldBD},               // 0x42
-		{"LD B, E", ldBE},               // 0x43
-		{"LD B, H", ldBH},               // 0x44
-		{"LD B, L", ldBL},               // 0x45
-		{"LD B, (HL)", ldBHLp},          // 0x46
-		{"LD B, A", ldBA},               // 0x47
-		{"LD C, B", ldCB},               // 0x48
-		{"LD C, C", nop},                // 0x49
-		{"LD C, D", ldCD},               // 0x4A
-		{"LD C, E", ldCE},               // 0x4B
-		{"LD C, H", ldCH},               // 0x4C
-		{"LD C, L", ldCL},               // 0x4D
-		{"LD C, (HL)", ldCHLp},          // 0x4E
-		{"LD C, A", ldCA},               // 0x4F
-		{"LD D, B", ldDB},               // 0x50
-		{"LD D, C", ldDC},               // 0x51
-		{"LD D, D", nop},                // 0x52
-		{"LD D, E", ldDE},               // 0x53
-		{"LD D, H", ldDH},               // 0x54
-		{"LD D, L", ldDL},               // 0x55
-		{"LD D, (HL)", ldDHLp},          // 0x56
-		{"LD D, A", ldDA},               // 0x57
-		{"LD E, B", ldEB},               // 0x58
-		{"LD E, C", ldEC},               // 0x59
-		{"LD E, D", ldED},               // 0x5A
-		{"LD E, E", nop},                // 0x5B
-		{"LD E, H", ldEH},               // 0x5C
-		{"LD E, L", ldEL},               // 0x5D
-		{"LD E, (HL)", ldEHLp},          // 0x5E
-		{"LD E, A", ldEA},               // 0x5F
-		{"LD H, B", ldHB},               // 0x60
-		{"LD H, C", ldHC},               // 0x61
-		{"LD H, D", ldHD},               // 0x62
-		{"LD H, E", ldHE},               // 0x63
-		{"LD H, H", nop},                // 0x64
-		{"LD H, L", ldHL},               // 0x65
-		{"LD H, (HL)", ldHHLp},          // 0x66
-		{"LD H, A", ldHA},               // 0x67
-		{"LD L, B", ldLB},               // 0x68
-		{"LD L, C", ldLC},               // 0x69
-		{"LD L, D", ldLD},               // 0x6A
-		{"LD L, E", ldLE},               // 0x6B
-		{"LD L, H", ldLH},               // 0x6C
-		{"LD L, L", nop},                // 0x6D
-		{"LD L, (HL)", ldLHLp},          // 0x6E
-		{"LD L, A", ldLA},               // 0x6F
-		{"LD (HL), B", ldHLpB},          // 0x70
-		{"LD (HL), C", ldHLpC},          // 0x71
-		{"LD (HL), D", ldHLpD},          // 0x72
-		{"LD (HL), E", ldHLpE},          // 0x73
-		{"LD (HL), H", ldHLpH},          // 0x74
-		{"LD (HL), L", ldHLpL},          // 0x75
-		{"HALT", halt},                  // 0x76
-		{"LD (HL), A", ldHLpA},          // 0x77
-		{"LD A, B", ldAB},               // 0x78
-		{"LD A, C", ldAC},               // 0x79
-		{"LD A, D", ldAD},               // 0x7A
-		{"LD A, E", ldAE},               // 0x7B
-		{"LD A, H", ldAH},               // 0x7C
-		{"LD A, L", ldAL},               // 0x7D
-		{"LD A, (HL)", ldAHLp},          // 0x7E
-		{"LD A, A", nop},                // 0x7F
-		{"ADD B", addB},                 // 0x80
-		{"ADD C", addC},                 // 0x81
-		{"ADD D", addD},                 // 0x82
-		{"ADD E", addE},                 // 0x83
-		{"ADD H", addH},                 // 0x84
-		{"ADD L", addL},                 // 0x85
-		{"ADD (HL)", addHLp},            // 0x86
-		{"ADD A", addA},                 // 0x87
-		{"ADC B", adcB},                 // 0x88
-		{"ADC C", adcC},                 // 0x89
-		{"ADC D", adcD},                 // 0x8A
-		{"ADC E", adcE},                 // 0x8B
-		{"ADC H", adcH},                 // 0x8C
-		{"ADC L", adcL},                 // 0x8D
-		{"ADC (HL)", adcHLp},            // 0x8E
-		{"ADC A", adcA},                 // 0x8F
-		{"SUB B", subB},                 // 0x90
-		{"SUB C", subC},                 // 0x91
-		{"SUB D", subD},                 // 0x92
-		{"SUB E", subE},                 // 0x93
-		{"SUB H", subH},                 // 0x94
-		{"SUB L", subL},                 // 0x95
-		{"SUB (HL)", subHLp},            // 0x96
-		{"SUB A", subA},                 // 0x97
-		{"SBC B", sbcB},                 // 0x98
-		{"SBC C", sbcC},                 // 0x99
-		{"SBC D", sbcD},                 // 0x9A
-		{"SBC E", sbcE},                 // 0x9B
-		{"SBC H", sbcH},                 // 0x9C
-		{"SBC L", sbcL},                 // 0x9D
-		{"SBC (HL)", sbcHLp},            // 0x9E
-		{"SBC A", sbcA},                 // 0x9F
-		{"AND B", andB},                 // 0xA0
-		{"AND C", andC},                 // 0xA1
-		{"AND D", andD},                 // 0xA2
-		{"AND E", andE},                 // 0xA3
-		{"AND H", andH},                 // 0xA4
-		{"AND L", andL},                 // 0xA5
-		{"AND (HL)", andHLp},            // 0xA6
-		{"AND A", andA},                 // 0xA7
-		{"XOR B", xorB},                 // 0xA8
-		{"XOR C", xorC},                 // 0xA9
-		{"XOR D", xorD},                 // 0xAA
-		{"XOR E", xorE},                 // 0xAB
-		{"XOR H", xorH},                 // 0xAC
-		{"XOR L", xorL},                 // 0xAD
-		{"XOR (HL)", xorHLp},            // 0xAE
-		{"XOR A", xorA},                 // 0xAF
-		{"OR B", orB},                   // 0xB0
-		{"OR C", orC},                   // 0xB1
-		{"OR D", orD},                   // 0xB2
-		{"OR E", orE},                   // 0xB3
-		{"OR H", orH},                   // 0xB4
-		{"OR L", orL},                   // 0xB5
-		{"OR (HL)", orHLp},              // 0xB6
-		{"OR A", orA},                   // 0xB7
-		{"CP B", cpB},                   // 0xB8
-		{"CP C", cpC},                   // 0xB9
-		{"CP D", cpD},                   // 0xBA
-		{"CP E", cpE},                   // 0xBB
-		{"CP H", cpH},                   // 0xBC
-		{"CP L", cpL},                   // 0xBD
-		{"CP (HL)", cpHLp},              // 0xBE
-		{"CP A", cpA},                   // 0xBF
-		{"RET NZ", retnz},               // 0xC0
-		{"POP BC", popBC},               // 0xC1
-		{"JP NZ, nn", jpnzd16},          // 0xC2
-		{"JP nn", jpd16},                // 0xC3
-		{"CALL NZ, nn", callnzd16},      // 0xC4
-		{"PUSH BC", pushBC},             // 0xC5
-		{"ADD n", addd8},                // 0xC6
-		{"RST 0x00", rst00},             // 0xC7
-		{"RET Z", retz},                 // 0xC8
-		{"RET", ret},                    // 0xC9
-		{"JP Z, nn", jpzd16},            // 0xCA
-		{"CB", cb},                      // 0xCB
-		{"CALL w, nn", callzd16},        // 0xCC
-		{"CALL nn", calld16},            // 0xCD
-		{"ADC n", adcd8},                // 0xCE
-		{"RST 0x08", rst08},             // 0xCF
-		{"RET NC", retnc},               // 0xD0
-		{"POP DE", popDE},               // 0xD1
-		{"JP NC, nn", jpncd16},          // 0xD2
-		{"UNDEFINED", nil},              // 0xD3
-		{"CALL NC, nn", callncd16},      // 0xD4
-		{"PUSH DE", pushDE},             // 0xD5
-		{"SUB n", subd8},                // 0xD6
-		{"RST 0x10", rst10},             // 0xD7
-		{"RET C", retc},                 // 0xD8
-		{"RETI", reti},                  // 0xD9
-		{"JP C, nn", jpcd16},            // 0xDA
-		{"UNDEFINED", nil},              // 0xDB
-		{"CALL C, nn", callcd16},        // 0xDC
-		{"UNDEFINED", nil},              // 0xDD
-		{"SBC n", sbcd8},                // 0xDE
-		{"RST 0x18", rst18},             // 0xDF
-		{"LD (0xFF00+n), A", ldff00d8A}, // 0xE0
-		{"POP HL", popHL},               // 0xE1
-		{"LD (0xFF00+C), A", ldff00CA},  // 0xE2
-		{"UNDEFINED", nil},              // 0xE3
-		{"UNDEFINED", nil},              // 0xE4
-		{"PUSH HL", pushHL},             // 0xE5
-		{"AND n", andd8},                // 0xE6
-		{"RST 0x20", rst20},             // 0xE7
-		{"ADD SP, e", addSPr8},          // 0xE8
-		{"JP HL", jpHL},                 // 0xE9
-		{"LD (nn), A", ldd16pA},         // 0xEA
-		{"UNDEFINED", nil},              // 0xEB
-		{"UNDEFINED", nil},              // 0xEC
-		{"UNDEFINED", nil},              // 0xED
-		{"XOR n", xord8},                // 0xEE
-		{"RST 0x28", rst28},             // 0xEF
-		{"LD A, (0xFF00+n)", ldAff00d8}, // 0xF0
-		{"POP AF", popAF},               // 0xF1
-		{"LD A, (0xFF00+C)", ldAff00C},  // 0xF2
-		{"DI", di},                      // 0xF3
-		{"UNDEFINED", nil},              // 0xF4
-		{"PUSH AF", pushAF},             // 0xF5
-		{"OR n", ord8},                  // 0xF6
-		{"RST 0x30", rst30},             // 0xF7
-		{"LD HL, SP+e", ldHLSPr8},       // 0xF8
-		{"LD SP, HL", ldSPHL},           // 0xF9
-		{"LD A, (nn)", ldAd16p},         // 0xFA
-		{"EI", ei},                      // 0xFB
-		{"UNDEFINED", nil},              // 0xFC
-		{"UNDEFINED", nil},              // 0xFD
-		{"CP n", cpd8},                  // 0xFE
-		{"RST 0x38", rst38},             // 0xFF
+	instructions = [256]func(*CPU){
+		nop,       // 0x00: NOP
+		ldBCd16,   // 0x01: LD BC, n16
+		ldBCpA,    // 0x02: LD (BC), A
+		incBC,     // 0x03: INC BC
+		incB,      // 0x04: INC B
+		decB,      // 0x05: DEC B
+		ldBd8,     // 0x06: LD B, n8
+		rlca,      // 0x07: RLCA
+		ldd16pSP,  // 0x08: LD (a16), SP
+		addHLBC,   // 0x09: ADD HL, BC
+		ldABCp,    // 0x0A: LD A, (BC)
+		decBC,     // 0x0B: DEC BC
+		incC,      // 0x0C: INC C
+		decC,      // 0x0D: DEC C
+		ldCd8,     // 0x0E: LD C, n8
+		rrca,      // 0x0F: RRCA
+		stop,      // 0x10: STOP
+		ldDEd16,   // 0x11: LD DE, n16
+		ldDEpA,    // 0x12: LD (DE), A
+		incDE,     // 0x13: INC DE
+		incD,      // 0x14: INC D
+		decD,      // 0x15: DEC D
+		ldDd8,     // 0x16: LD D, n8
+		rla,       // 0x17: RLA
+		jre,       // 0x18: JR e8
+		addHLDE,   // 0x19: ADD HL, DE
+		ldADEp,    // 0x1A: LD A, (DE)
+		decDE,     // 0x1B: DEC DE
+		incE,      // 0x1C: INC E
+		decE,      // 0x1D: DEC E
+		ldEd8,     // 0x1E: LD E, n8
+		rra,       // 0x1F: RRA
+		jrnze,     // 0x20: JR NZ, e8
+		ldHLd16,   // 0x21: LD HL, n16
+		ldiHLpA,   // 0x22: LD (HL+), A
+		incHL,     // 0x23: INC HL
+		incH,      // 0x24: INC H
+		decH,      // 0x25: DEC H
+		ldHd8,     // 0x26: LD H, n8
+		daa,       // 0x27: DAA
+		jrze,      // 0x28: JR Z, e8
+		addHLHL,   // 0x29: ADD HL, HL
+		ldiAHLp,   // 0x2A: LD A, (HL+)
+		decHL,     // 0x2B: DEC HL
+		incL,      // 0x2C: INC L
+		decL,      // 0x2D: DEC L
+		ldLd8,     // 0x2E: LD L, n8
+		cpl,       // 0x2F: CPL
+		jrnce,     // 0x30: JR NC, e8
+		ldSPd16,   // 0x31: LD SP, n16
+		lddHLpA,   // 0x32: LD (HL-), A
+		incSP,     // 0x33: INC SP
+		incHLp,    // 0x34: INC (HL)
+		decHLp,    // 0x35: DEC (HL)
+		ldHLpd8,   // 0x36: LD (HL), n8
+		scf,       // 0x37: SCF
+		jrce,      // 0x38: JR C, e8
+		addHLSP,   // 0x39: ADD HL, SP
+		lddAHLp,   // 0x3A: LD A, (HL-)
+		decSP,     // 0x3B: DEC SP
+		incA,      // 0x3C: INC A
+		decA,      // 0x3D: DEC A
+		ldAd8,     // 0x3E: LD A, n8
+		ccf,       // 0x3F: CCF
+		nop,       // 0x40: LD B, B
+		ldBC,      // 0x41: LD B, C
+		ldBD,      // 0x42: LD B, D
+		ldBE,      // 0x43: LD B, E
+		ldBH,      // 0x44: LD B, H
+		ldBL,      // 0x45: LD B, L
+		ldBHLp,    // 0x46: LD B, (HL)
+		ldBA,      // 0x47: LD B, A
+		ldCB,      // 0x48: LD C, B
+		nop,       // 0x49: LD C, C
+		ldCD,      // 0x4A: LD C, D
+		ldCE,      // 0x4B: LD C, E
+		ldCH,      // 0x4C: LD C, H
+		ldCL,      // 0x4D: LD C, L
+		ldCHLp,    // 0x4E: LD C, (HL)
+		ldCA,      // 0x4F: LD C, A
+		ldDB,      // 0x50: LD D, B
+		ldDC,      // 0x51: LD D, C
+		nop,       // 0x52: LD D, D
+		ldDE,      // 0x53: LD D, E
+		ldDH,      // 0x54: LD D, H
+		ldDL,      // 0x55: LD D, L
+		ldDHLp,    // 0x56: LD D, (HL)
+		ldDA,      // 0x57: LD D, A
+		ldEB,      // 0x58: LD E, B
+		ldEC,      // 0x59: LD E, C
+		ldED,      // 0x5A: LD E, D
+		nop,       // 0x5B: LD E, E
+		ldEH,      // 0x5C: LD E, H
+		ldEL,      // 0x5D: LD E, L
+		ldEHLp,    // 0x5E: LD E, (HL)
+		ldEA,      // 0x5F: LD E, A
+		ldHB,      // 0x60: LD H, B
+		ldHC,      // 0x61: LD H, C
+		ldHD,      // 0x62: LD H, D
+		ldHE,      // 0x63: LD H, E
+		nop,       // 0x64: LD H, H
+		ldHL,      // 0x65: LD H, L
+		ldHHLp,    // 0x66: LD H, (HL)
+		ldHA,      // 0x67: LD H, A
+		ldLB,      // 0x68: LD L, B
+		ldLC,      // 0x69: LD L, C
+		ldLD,      // 0x6A: LD L, D
+		ldLE,      // 0x6B: LD L, E
+		ldLH,      // 0x6C: LD L, H
+		nop,       // 0x6D: LD L, L
+		ldLHLp,    // 0x6E: LD L, (HL)
+		ldLA,      // 0x6F: LD L, A
+		ldHLpB,    // 0x70: LD (HL), B
+		ldHLpC,    // 0x71: LD (HL), C
+		ldHLpD,    // 0x72: LD (HL), D
+		ldHLpE,    // 0x73: LD (HL), E
+		ldHLpH,    // 0x74: LD (HL), H
+		ldHLpL,    // 0x75: LD (HL), L
+		halt,      // 0x76: HALT
+		ldHLpA,    // 0x77: LD (HL), A
+		ldAB,      // 0x78: LD A, B
+		ldAC,      // 0x79: LD A, C
+		ldAD,      // 0x7A: LD A, D
+		ldAE,      // 0x7B: LD A, E
+		ldAH,      // 0x7C: LD A, H
+		ldAL,      // 0x7D: LD A, L
+		ldAHLp,    // 0x7E: LD A, (HL)
+		nop,       // 0x7F: LD A, A
+		addB,      // 0x80: ADD A, B
+		addC,      // 0x81: ADD A, C
+		addD,      // 0x82: ADD A, D
+		addE,      // 0x83: ADD A, E
+		addH,      // 0x84: ADD A, H
+		addL,      // 0x85: ADD A, L
+		addHLp,    // 0x86: ADD A, (HL)
+		addA,      // 0x87: ADD A, A
+		adcB,      // 0x88: ADC A, B
+		adcC,      // 0x89: ADC A, C
+		adcD,      // 0x8A: ADC A, D
+		adcE,      // 0x8B: ADC A, E
+		adcH,      // 0x8C: ADC A, H
+		adcL,      // 0x8D: ADC A, L
+		adcHLp,    // 0x8E: ADC A, (HL)
+		adcA,      // 0x8F: ADC A, A
+		subB,      // 0x90: SUB B
+		subC,      // 0x91: SUB C
+		subD,      // 0x92: SUB D
+		subE,      // 0x93: SUB E
+		subH,      // 0x94: SUB H
+		subL,      // 0x95: SUB L
+		subHLp,    // 0x96: SUB (HL)
+		subA,      // 0x97: SUB A
+		sbcB,      // 0x98: SBC A, B
+		sbcC,      // 0x99: SBC A, C
+		sbcD,      // 0x9A: SBC A, D
+		sbcE,      // 0x9B: SBC A, E
+		sbcH,      // 0x9C: SBC A, H
+		sbcL,      // 0x9D: SBC A, L
+		sbcHLp,    // 0x9E: SBC A, (HL)
+		sbcA,      // 0x9F: SBC A, A
+		andB,      // 0xA0: AND B
+		andC,      // 0xA1: AND C
+		andD,      // 0xA2: AND D
+		andE,      // 0xA3: AND E
+		andH,      // 0xA4: AND H
+		andL,      // 0xA5: AND L
+		andHLp,    // 0xA6: AND (HL)
+		andA,      // 0xA7: AND A
+		xorB,      // 0xA8: XOR B
+		xorC,      // 0xA9: XOR C
+		xorD,      // 0xAA: XOR D
+		xorE,      // 0xAB: XOR E
+		xorH,      // 0xAC: XOR H
+		xorL,      // 0xAD: XOR L
+		xorHLp,    // 0xAE: XOR (HL)
+		xorA,      // 0xAF: XOR A
+		orB,       // 0xB0: OR B
+		orC,       // 0xB1: OR C
+		orD,       // 0xB2: OR D
+		orE,       // 0xB3: OR E
+		orH,       // 0xB4: OR H
+		orL,       // 0xB5: OR L
+		orHLp,     // 0xB6: OR (HL)
+		orA,       // 0xB7: OR A
+		cpB,       // 0xB8: CP B
+		cpC,       // 0xB9: CP C
+		cpD,       // 0xBA: CP D
+		cpE,       // 0xBB: CP E
+		cpH,       // 0xBC: CP H
+		cpL,       // 0xBD: CP L
+		cpHLp,     // 0xBE: CP (HL)
+		cpA,       // 0xBF: CP A
+		retnz,     // 0xC0: RET NZ
+		popBC,     // 0xC1: POP BC
+		jpnzd16,   // 0xC2: JP NZ, a16
+		jpd16,     // 0xC3: JP a16
+		callnzd16, // 0xC4: CALL NZ, a16
+		pushBC,    // 0xC5: PUSH BC
+		addd8,     // 0xC6: ADD A, n8
+		rst00,     // 0xC7: RST $00
+		retz,      // 0xC8: RET Z
+		ret,       // 0xC9: RET
+		jpzd16,    // 0xCA: JP Z, a16
+		cb,        // 0xCB: PREFIX CB
+		callzd16,  // 0xCC: CALL Z, a16
+		calld16,   // 0xCD: CALL a16
+		adcd8,     // 0xCE: ADC A, n8
+		rst08,     // 0xCF: RST $08
+		retnc,     // 0xD0: RET NC
+		popDE,     // 0xD1: POP DE
+		jpncd16,   // 0xD2: JP NC, a16
+		nil,       // 0xD3: undefined
+		callncd16, // 0xD4: CALL NC, a16
+		pushDE,    // 0xD5: PUSH DE
+		subd8,     // 0xD6: SUB n8
+		rst10,     // 0xD7: RST $10
+		retc,      // 0xD8: RET C
+		reti,      // 0xD9: RETI
+		jpcd16,    // 0xDA: JP C, a16
+		nil,       // 0xDB: undefined
+		callcd16,  // 0xDC: CALL C, a16
+		nil,       // 0xDD: undefined
+		sbcd8,     // 0xDE: SBC A, n8
+		rst18,     // 0xDF: RST $18
+		ldff00d8A, // 0xE0: LDH (a8), A
+		popHL,     // 0xE1: POP HL
+		ldff00CA,  // 0xE2: LD ($FF00+C), A
+		nil,       // 0xE3: undefined
+		nil,       // 0xE4: undefined
+		pushHL,    // 0xE5: PUSH HL
+		andd8,     // 0xE6: AND n8
+		rst20,     // 0xE7: RST $20
+		addSPr8,   // 0xE8: ADD SP, s8
+		jpHL,      // 0xE9: JP HL
+		ldd16pA,   // 0xEA: LD (a16), A
+		nil,       // 0xEB: undefined
+		nil,       // 0xEC: undefined
+		nil,       // 0xED: undefined
+		xord8,     // 0xEE: XOR n8
+		rst28,     // 0xEF: RST $28
+		ldAff00d8, // 0xF0: LDH A, (a8)
+		popAF,     // 0xF1: POP AF
+		ldAff00C,  // 0xF2: LD A, ($FF00+C)
+		di,        // 0xF3: DI
+		nil,       // 0xF4: undefined
+		pushAF,    // 0xF5: PUSH AF
+		ord8,      // 0xF6: OR n8
+		rst30,     // 0xF7: RST $30
+		ldHLSPr8,  // 0xF8: LD HL, SP+s8
+		ldSPHL,    // 0xF9: LD SP, HL
+		ldAd16p,   // 0xFA: LD A, (a16)
+		ei,        // 0xFB: EI
+		nil,       // 0xFC: undefined
+		nil,       // 0xFD: undefined
+		cpd8,      // 0xFE: CP n8
+		rst38,     // 0xFF: RST $38
 	}
 }
 
@@ -1082,7 +1082,7 @@ func halt(c *CPU) {
 	if !c.interrupts.MasterEnabled() && c.interrupts.InterruptsPending() {
 		c.ops.Push(func(c *CPU) {
 			opcode := c.mmu.Read(c.pc)
-			c.ir = instructions[opcode]
+			c.loadInstruction(opcode)
 			// Do not increment PC - HALT BUG
 		})
 	} else {
@@ -1540,7 +1540,7 @@ func cb(c *CPU) {
 	c.ops.Push(func(c *CPU) {
 		opCode := c.mmu.Read(c.pc)
 		c.pcOfInstruction = c.pc
-		c.ir = extendedInstructions[opCode]
+		c.loadExtendedInstruction(opCode)
 		c.pc++
 	})
 }
@@ -2366,7 +2366,7 @@ func fetchCycle(c *CPU, doInFetchCycle ...func(*CPU)) {
 		beforeInstrFetch(c)
 		c.pcOfInstruction = c.pc
 		opCode := c.mmu.Read(c.pc)
-		c.ir = instructions[opCode]
+		c.loadInstruction(opCode)
 		c.pc++
 
 		if c.interrupts.MustHandleInterrupt() {
@@ -2415,7 +2415,7 @@ func enqueueInterruptRoutine(c *CPU, t interrupts.InterruptType, afterInstrFetch
 	c.ops.Push(func(c *CPU) {
 		c.pcOfInstruction = c.pc
 		opCode := c.mmu.Read(c.pc)
-		c.ir = instructions[opCode]
+		c.loadInstruction(opCode)
 		c.pc++
 		afterInstrFetch(c)
 	})
@@ -0,0 +1,137 @@
+package emulation
+
+import (
+	"errors"
+	"fmt"
+	"gameboy-emulator/internal/cycle/cpu"
+	"strconv"
+	"strings"
+)
+
+var ErrMalformedCondition = errors.New("malformed breakpoint condition")
+
+// comparisonOperators are checked in this order, so two-character operators win over their prefixes
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// registerValues reads the registers which may be compared in conditions. 8-bit registers can only be compared with
+// 8-bit values.
+var registerValues = map[string]struct {
+	wide bool
+	read func(r cpu.Registers) uint16
+}{
+	"a":  {false, func(r cpu.Registers) uint16 { return uint16(r.A) }},
+	"f":  {false, func(r cpu.Registers) uint16 { return uint16(r.F) }},
+	"b":  {false, func(r cpu.Registers) uint16 { return uint16(r.B) }},
+	"c":  {false, func(r cpu.Registers) uint16 { return uint16(r.C) }},
+	"d":  {false, func(r cpu.Registers) uint16 { return uint16(r.D) }},
+	"e":  {false, func(r cpu.Registers) uint16 { return uint16(r.E) }},
+	"h":  {false, func(r cpu.Registers) uint16 { return uint16(r.H) }},
+	"l":  {false, func(r cpu.Registers) uint16 { return uint16(r.L) }},
+	"af": {true, cpu.Registers.AF},
+	"bc": {true, cpu.Registers.BC},
+	"de": {true, cpu.Registers.DE},
+	"hl": {true, cpu.Registers.HL},
+	"sp": {true, func(r cpu.Registers) uint16 { return r.SP }},
+	"pc": {true, func(r cpu.Registers) uint16 { return r.PC }},
+}
+
+type (
+	// Condition of a breakpoint, which holds if all of its comparisons hold. An empty condition always holds.
+	Condition []Comparison
+
+	// Comparison of a register with a value, e.g. "hl != 0xC000"
+	Comparison struct {
+		Register string // lower case name of an 8-bit or 16-bit register, e.g. "a" or "hl"
+		Operator string // one of ==, !=, <, <=, >, >=
+		Value    uint16
+	}
+)
+
+// ParseCondition parses comparisons of registers with values joined by "&&", e.g. "a == 0x12 && hl != $C000".
+// Values are decimal or hexadecimal with the prefix "0x" or "$". An empty text is the condition which always holds.
+func ParseCondition(text string) (Condition, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	var condition Condition
+	for _, part := range strings.Split(text, "&&") {
+		comparison, err := parseComparison(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		condition = append(condition, comparison)
+	}
+	return condition, nil
+}
+
+func parseComparison(text string) (Comparison, error) {
+	for _, operator := range comparisonOperators {
+		register, value, found := strings.Cut(text, operator)
+		if !found {
+			continue
+		}
+
+		register = strings.ToLower(strings.TrimSpace(register))
+		reg, ok := registerValues[register]
+		if !ok {
+			return Comparison{}, fmt.Errorf("%w: unknown register %q", ErrMalformedCondition, register)
+		}
+
+		bits := 8
+		if reg.wide {
+			bits = 16
+		}
+		parsed, err := parseValue(strings.TrimSpace(value), bits)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("%w: %w", ErrMalformedCondition, err)
+		}
+		return Comparison{Register: register, Operator: operator, Value: parsed}, nil
+	}
+	return Comparison{}, fmt.Errorf("%w: no comparison in %q", ErrMalformedCondition, text)
+}
+
+func parseValue(text string, bits int) (uint16, error) {
+	if hex, ok := strings.CutPrefix(text, "$"); ok {
+		text = "0x" + hex
+	}
+	value, err := strconv.ParseUint(text, 0, bits)
+	return uint16(value), err
+}
+
+// Holds returns true if all comparisons hold for the given registers
+func (c Condition) Holds(registers cpu.Registers) bool {
+	for _, comparison := range c {
+		if !comparison.holds(registers) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) String() string {
+	parts := make([]string, len(c))
+	for i, comparison := range c {
+		parts[i] = fmt.Sprintf("%s %s 0x%X", comparison.Register, comparison.Operator, comparison.Value)
+	}
+	return strings.Join(parts, " && ")
+}
+
+func (c Comparison) holds(registers cpu.Registers) bool {
+	value := registerValues[c.Register].read(registers)
+	switch c.Operator {
+	case "==":
+		return value == c.Value
+	case "!=":
+		return value != c.Value
+	case "<":
+		return value < c.Value
+	case "<=":
+		return value <= c.Value
+	case ">":
+		return value > c.Value
+	case ">=":
+		return value >= c.Value
+	}
+	return false
+}
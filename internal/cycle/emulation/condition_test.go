@@ -0,0 +1,41 @@
+package emulation
+
+import (
+	"gameboy-emulator/internal/cycle/cpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseCondition(t *testing.T) {
+	// GIVEN
+	registers := cpu.Registers{A: 0x12, H: 0xC0, L: 0x01, SP: 0xFFF0}
+
+	// WHEN
+	condition, err := ParseCondition("a == 0x12 && HL >= $C000 && sp<65535")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "a == 0x12 && hl >= 0xC000 && sp < 0xFFFF", condition.String())
+	assert.True(t, condition.Holds(registers))
+	assert.False(t, condition.Holds(cpu.Registers{A: 0x12}))
+}
+
+func TestParseCondition_empty(t *testing.T) {
+	// WHEN
+	condition, err := ParseCondition(" ")
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, condition.Holds(cpu.Registers{}))
+}
+
+func TestParseCondition_malformed(t *testing.T) {
+	for _, text := range []string{"x == 1", "a == 0x100", "a 5", "a == 1 &&", "hl != C000"} {
+		// WHEN
+		_, err := ParseCondition(text)
+
+		// THEN
+		assert.ErrorIs(t, err, ErrMalformedCondition, text)
+	}
+}
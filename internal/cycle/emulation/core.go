@@ -1,7 +1,11 @@
 package emulation
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"gameboy-emulator/internal/cartridge"
+	"gameboy-emulator/internal/cheat"
 	"gameboy-emulator/internal/cycle/apu"
 	"gameboy-emulator/internal/cycle/cpu"
 	"gameboy-emulator/internal/cycle/gpu"
@@ -9,6 +13,29 @@ import (
 	"gameboy-emulator/internal/cycle/joypad"
 	"gameboy-emulator/internal/cycle/memory"
 	"gameboy-emulator/internal/cycle/timer"
+	"gameboy-emulator/internal/util"
+	"io"
+	"sync"
+)
+
+// CyclesPerFrame is the number of T-cycles the PPU needs to draw one complete frame (154 lines of 456 cycles)
+const CyclesPerFrame = 70224
+
+// Save states start with this magic followed by the version of the format. Increase the version
+// whenever the layout of the state of any component changes.
+const (
+	stateMagic   = "GOMEBOY-STATE"
+	stateVersion = uint16(7)
+)
+
+var (
+	ErrInvalidState         = errors.New("not a save state")
+	ErrStateVersion         = errors.New("unsupported save state version")
+	ErrStateCartridgeAbsent = errors.New("save state requires an inserted cartridge")
+	ErrStateCartridge       = errors.New("save state was taken with another cartridge")
+	ErrNotAtBoundary        = errors.New("state can only be saved between two instructions")
+
+	errInvalidFrameTicks = errors.New("invalid frame position in state")
 )
 
 // Core of the Gameboy emulation. Holds all components and exposes
@@ -21,8 +48,33 @@ type Core struct {
 	memory     *memory.Memory
 	cpu        *cpu.CPU
 	apu        *apu.APU
+
+	// Joypad input is latched and only applied at the start of a frame, so it doesn't depend on the timing of
+	// the host. A cleared bit means the key is pressed (see joypad.Joypad.SetState).
+	inputMutex sync.Mutex
+	input      byte
+	tiltX      float64
+	tiltY      float64
+	frameTicks int
+
+	recording     *Movie
+	playback      *Movie
+	playbackFrame int
+
+	rumbleHandler RumbleHandler
+
+	cheats    *cheat.List
+	cheatPath string // cheat file next to the ROM image, empty for cartridges created from memory
+
+	debugger *Debugger    // nil until attached, see Debugger
+	trace    *traceWriter // nil unless tracing, see StartTrace
 }
 
+// RumbleHandler receives the state of the rumble motor at the end of every frame, which is the start of VBlank or,
+// while the LCD is off, every CyclesPerFrame T-cycles. The intensity is the share of the frame the motor was turned
+// on, from 0 (off) to 1.
+type RumbleHandler func(on bool, intensity float64)
+
 func NewCore(
 	interrupts *interrupts.Interrupts,
 	joypad *joypad.Joypad,
@@ -32,7 +84,7 @@ func NewCore(
 	cpu *cpu.CPU,
 	apu *apu.APU,
 ) *Core {
-	return &Core{
+	c := &Core{
 		interrupts: interrupts,
 		joypad:     joypad,
 		timer:      timer,
@@ -40,7 +92,23 @@ func NewCore(
 		memory:     memory,
 		cpu:        cpu,
 		apu:        apu,
+		input:      0xFF,
 	}
+	c.SetCheats(cheat.NewList())
+	ppu.GetDisplay().SetVBlankHandler(c.reportRumble)
+	return c
+}
+
+// New creates a core with all components wired up, which runs the given boot ROM at power-on.
+func New(bootRom *[0x100]byte) *Core {
+	a := apu.New()
+	i := interrupts.New()
+	j := joypad.New(i)
+	t := timer.New(i)
+	p := gpu.NewPPU(i)
+	m := memory.New(i, t, p, j, a, bootRom)
+	c := cpu.New(m, i)
+	return NewCore(i, j, t, p, m, c, a)
 }
 
 func (e *Core) Reset() {
@@ -51,39 +119,409 @@ func (e *Core) Reset() {
 	e.memory.Reset()
 	e.cpu.Reset()
 	e.apu.Reset()
+	e.frameTicks = 0
+}
+
+// PowerCycle turns the machine off and on again. Unlike Reset, this includes the banking registers of the
+// cartridge (the save game is kept).
+func (e *Core) PowerCycle() {
+	e.Reset()
+	if cart := e.memory.GetGameCartridge(); cart != nil {
+		cart.Reset()
+	}
 }
 
 func (e *Core) SetScreenHandler(handler func([144][160]byte)) {
 	e.ppu.GetDisplay().RegisterFrameOutputHandler(handler)
 }
 
-func (e *Core) InsertCartridge(pathToCartridgeImage string) {
-	e.memory.InsertGameCartridge(cartridge.LoadCartridgeImage(pathToCartridgeImage))
+// GetScreen returns a copy of the current screen contents. Must not be called while a driver is ticking the
+// core concurrently, use SetScreenHandler instead.
+func (e *Core) GetScreen() [144][160]byte {
+	return e.ppu.GetDisplay().GetScreen()
+}
+
+// Registers returns the registers of the CPU. Must not be called while a driver is ticking the core concurrently.
+func (e *Core) Registers() cpu.Registers {
+	return e.cpu.Registers()
+}
+
+// PeekMemory reads the byte at the given address like the CPU does, but without side effects. Must not be called
+// while a driver is ticking the core concurrently.
+func (e *Core) PeekMemory(address uint16) byte {
+	return e.memory.Peek(address)
+}
+
+// SetSerialHandler registers a handler receiving every byte the game sends over the link cable.
+func (e *Core) SetSerialHandler(handler func(data byte)) {
+	e.memory.SetSerialOutputHandler(handler)
+}
+
+// SetRumbleHandler registers a handler receiving the state of the rumble motor of the inserted cartridge after every
+// frame. It isn't called for cartridges without rumble motor.
+func (e *Core) SetRumbleHandler(handler RumbleHandler) {
+	e.rumbleHandler = handler
+}
+
+// InsertCartridge inserts the cartridge from the ROM image at the given path. If the image can't be loaded,
+// the previously inserted cartridge is kept.
+func (e *Core) InsertCartridge(pathToCartridgeImage string, opts ...cartridge.Option) error {
+	cart, err := cartridge.LoadCartridgeImage(pathToCartridgeImage, opts...)
+	if err != nil {
+		return err
+	}
+
+	cheatPath := cartridge.CompanionPath(pathToCartridgeImage, cheat.FileExtension)
+	cheats, err := cheat.Load(cheatPath)
+	if err != nil {
+		return err
+	}
+
+	e.memory.InsertGameCartridge(cart)
+	e.SetCheats(cheats)
+	e.cheatPath = cheatPath
+	return nil
+}
+
+// InsertCartridgeImage inserts a cartridge created from a ROM image in memory. Its RAM is initialized with the
+// given save game, which may be nil. The save game isn't written to disk, use GetSaveGame to persist it.
+func (e *Core) InsertCartridgeImage(rom []byte, ram []byte, opts ...cartridge.Option) error {
+	cart, err := cartridge.NewCartridge(rom, ram, opts...)
+	if err != nil {
+		return err
+	}
+	e.memory.InsertGameCartridge(cart)
+	e.SetCheats(cheat.NewList())
+	e.cheatPath = ""
+	return nil
+}
+
+// SetCheats replaces the cheats of the inserted cartridge. InsertCartridge loads the cheats from the cheat file
+// next to the ROM image (see cheat.Load), InsertCartridgeImage starts without cheats.
+func (e *Core) SetCheats(cheats *cheat.List) {
+	e.cheats = cheats
+	e.memory.SetCheats(cheats)
+}
+
+// GetCheats returns the cheats of the inserted cartridge. They may be changed while the emulation is running.
+func (e *Core) GetCheats() *cheat.List {
+	return e.cheats
+}
+
+// SaveCheats writes the cheats to the cheat file next to the ROM image. Nothing is written for cartridges created
+// from memory.
+func (e *Core) SaveCheats() error {
+	if e.cheatPath == "" {
+		return nil
+	}
+	return e.cheats.Save(e.cheatPath)
+}
+
+// GetSaveGame returns the contents of the cartridge RAM, or nil if no cartridge is inserted.
+func (e *Core) GetSaveGame() []byte {
+	if cart := e.memory.GetGameCartridge(); cart != nil {
+		return cart.RAM()
+	}
+	return nil
+}
+
+// ImportSaveGame replaces the save game of the inserted cartridge with the contents of the given file, e.g. a .sav
+// file written by another emulator, and turns the machine off and on, so the game picks it up.
+func (e *Core) ImportSaveGame(path string) error {
+	cart := e.memory.GetGameCartridge()
+	if cart == nil {
+		return ErrNoCartridge
+	}
+	if err := cartridge.ImportSaveGame(cart, path); err != nil {
+		return err
+	}
+	e.PowerCycle()
+	return nil
+}
+
+// ExportSaveGame writes the save game of the inserted cartridge to the given file in the .sav format of other
+// emulators.
+func (e *Core) ExportSaveGame(path string) error {
+	cart := e.memory.GetGameCartridge()
+	if cart == nil {
+		return ErrNoCartridge
+	}
+	return cartridge.ExportSaveGame(cart, path)
+}
+
+// GetCartridgeHeader returns the header of the inserted cartridge. The second result is false if no cartridge
+// is inserted.
+func (e *Core) GetCartridgeHeader() (cartridge.Header, bool) {
+	if cart := e.memory.GetGameCartridge(); cart != nil {
+		return cart.Header(), true
+	}
+	return cartridge.Header{}, false
 }
 
 func (e *Core) Tick() (left byte, right byte, play bool) {
+	if e.frameTicks == 0 {
+		e.applyInput()
+	}
+	e.frameTicks = (e.frameTicks + 1) % CyclesPerFrame
+
 	e.cpu.Tick()
 	e.timer.Tick()
 	e.ppu.Tick()
 	left, right, play = e.apu.Tick()
 	e.memory.Tick()
+
+	if e.frameTicks == 0 && !e.ppu.GetDisplay().IsEnabled() {
+		// without LCD there is no VBlank, so the frame ends after CyclesPerFrame T-cycles
+		e.reportRumble()
+	}
+	if e.debugger != nil {
+		e.debugger.afterTick()
+	}
 	return
 }
 
+// reportRumble passes the state of the rumble motor during the frame just completed to the rumble handler
+func (e *Core) reportRumble() {
+	if e.rumbleHandler == nil {
+		return
+	}
+
+	if rumbler, ok := e.memory.GetGameCartridge().(cartridge.Rumbler); ok {
+		intensity := rumbler.Rumble()
+		e.rumbleHandler(intensity > 0, intensity)
+	}
+}
+
+// RunFrame runs the emulation until the next VBlank, or for CyclesPerFrame T-cycles if the LCD is off, and then
+// completes the instruction in progress, so a snapshot can be taken (see SaveState). It returns a copy of the
+// completed frame and the audio samples produced meanwhile, interleaved as left and right channel.
+//
+// Must not be called while a driver is ticking the core concurrently.
+func (e *Core) RunFrame() (screen [144][160]byte, audio []byte) {
+	display := e.ppu.GetDisplay()
+	display.FrameCompleted() // only frames completed from now on count
+
+	audio = make([]byte, 0, 2*(apu.SamplingRate/59+1))
+	for ticks := 1; ; ticks++ {
+		if left, right, play := e.Tick(); play {
+			audio = append(audio, left, right)
+		}
+
+		if display.FrameCompleted() || (ticks >= CyclesPerFrame && !display.IsEnabled()) {
+			break
+		}
+	}
+
+	screen = display.GetScreen()
+	for !e.AtInstructionBoundary() {
+		if left, right, play := e.Tick(); play {
+			audio = append(audio, left, right)
+		}
+	}
+	return screen, audio
+}
+
 func (e *Core) SaveGame() {
 	if e.memory.GetGameCartridge() != nil {
 		e.memory.GetGameCartridge().Save()
 	}
 }
 
+// KeyPressed records the press of a key, which is passed to the joypad at the start of the next frame.
+// See joypad.Joypad.KeyPressed for the key indexes.
 func (e *Core) KeyPressed(index byte) {
-	go func() {
-		e.joypad.KeyPressed(index)
-	}()
+	e.inputMutex.Lock()
+	defer e.inputMutex.Unlock()
+	util.UnsetBit8(&e.input, index)
 }
 
+// KeyReleased records the release of a key, which is passed to the joypad at the start of the next frame.
 func (e *Core) KeyReleased(index byte) {
-	go func() {
-		e.joypad.KeyReleased(index)
-	}()
+	e.inputMutex.Lock()
+	defer e.inputMutex.Unlock()
+	util.SetBit(&e.input, index)
+}
+
+// SetTilt records the tilt of the cartridge on both axes from -1 to 1, which is passed to cartridges with an
+// accelerometer at the start of the next frame. See cartridge.Tilter for the directions. Tilting isn't recorded in
+// movies.
+func (e *Core) SetTilt(x, y float64) {
+	e.inputMutex.Lock()
+	defer e.inputMutex.Unlock()
+	e.tiltX = max(-1, min(1, x))
+	e.tiltY = max(-1, min(1, y))
+}
+
+// applyInput passes the input for the frame just starting to the joypad. While a movie is played back, the
+// recorded input replaces the input of the user.
+func (e *Core) applyInput() {
+	e.inputMutex.Lock()
+	input := e.input
+	tiltX, tiltY := e.tiltX, e.tiltY
+	e.inputMutex.Unlock()
+
+	if tilter, ok := e.memory.GetGameCartridge().(cartridge.Tilter); ok {
+		tilter.SetTilt(tiltX, tiltY)
+	}
+
+	switch {
+	case e.playback != nil:
+		if e.playbackFrame < len(e.playback.Input) {
+			input = e.playback.Input[e.playbackFrame]
+			e.playbackFrame++
+		} else {
+			e.playback = nil
+		}
+	case e.recording != nil:
+		e.recording.Input = append(e.recording.Input, input)
+	}
+
+	e.joypad.SetState(input)
+}
+
+// SaveState writes a snapshot of the complete machine to the given writer. Since the micro-operations of an
+// instruction in progress can't be serialized, snapshots can only be taken between two instructions (see
+// AtInstructionBoundary), otherwise ErrNotAtBoundary is returned. RunFrame and the drivers end their frames there.
+//
+// Must not be called while a driver is ticking the core concurrently.
+func (e *Core) SaveState(w io.Writer) error {
+	if !e.AtInstructionBoundary() {
+		return ErrNotAtBoundary
+	}
+
+	s := util.NewStateWriter(w)
+	s.Write([]byte(stateMagic), stateVersion)
+
+	// the cartridge is identified by the title and the checksums of its header
+	cart := e.memory.GetGameCartridge()
+	s.Write(cart != nil)
+	if cart != nil {
+		header := cart.Header()
+		s.WriteBytes([]byte(header.Title))
+		s.Write(header.HeaderChecksum, header.GlobalChecksum)
+	}
+
+	s.Write(e.frameTicks)
+	e.cpu.SaveState(s)
+	e.memory.SaveState(s)
+	e.interrupts.SaveState(s)
+	e.timer.SaveState(s)
+	e.joypad.SaveState(s)
+	e.ppu.SaveState(s)
+	e.apu.SaveState(s)
+	if cart != nil {
+		cart.SaveState(s)
+	}
+
+	return s.Err()
+}
+
+// AtInstructionBoundary returns true if the CPU completed an instruction and hasn't started the next one yet
+func (e *Core) AtInstructionBoundary() bool {
+	return e.cpu.AtInstructionBoundary()
+}
+
+// LoadState restores a snapshot written by SaveState. The cartridge the snapshot was taken with has to be
+// inserted already, a snapshot of another cartridge fails with ErrStateCartridge. A snapshot which turns out to be
+// corrupted fails with ErrInvalidState. In both cases, the machine is left untouched.
+//
+// Must not be called while a driver is ticking the core concurrently.
+func (e *Core) LoadState(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.HasPrefix(data, []byte(stateMagic)) {
+		return ErrInvalidState
+	}
+
+	header := bytes.NewReader(data[len(stateMagic):])
+	s := util.NewStateReader(header)
+
+	var version uint16
+	s.Read(&version)
+	if s.Err() != nil {
+		return ErrInvalidState
+	}
+	if version != stateVersion {
+		return fmt.Errorf("%w: %d", ErrStateVersion, version)
+	}
+	if err := e.checkStateCartridge(s); err != nil {
+		return err
+	}
+	components := data[len(data)-header.Len():]
+
+	// The snapshot is decoded into a scratch machine first. Only the cartridge is shared, so its state is kept
+	// to be put back if the snapshot is corrupted.
+	cart := e.memory.GetGameCartridge()
+	var cartState bytes.Buffer
+	if cart != nil {
+		cart.SaveState(util.NewStateWriter(&cartState))
+	}
+	scratch := New(&[0x100]byte{})
+	scratch.memory.InsertGameCartridge(cart)
+	if err := scratch.loadComponents(util.NewStateReader(bytes.NewReader(components))); err != nil {
+		if cart != nil {
+			cart.LoadState(util.NewStateReader(&cartState))
+		}
+		return fmt.Errorf("%w: %w", ErrInvalidState, err)
+	}
+
+	if err := e.loadComponents(util.NewStateReader(bytes.NewReader(components))); err != nil {
+		e.Reset() // not expected, the same snapshot was decoded before
+		return fmt.Errorf("%w: %w", ErrInvalidState, err)
+	}
+	return nil
+}
+
+// loadComponents restores the state of all components, which follows the identity of the cartridge in a snapshot
+func (e *Core) loadComponents(s *util.StateReader) error {
+	s.Read(&e.frameTicks)
+	if e.frameTicks < 0 || e.frameTicks >= CyclesPerFrame {
+		s.Fail(errInvalidFrameTicks)
+	}
+	e.cpu.LoadState(s)
+	e.memory.LoadState(s)
+	e.interrupts.LoadState(s)
+	e.timer.LoadState(s)
+	e.joypad.LoadState(s)
+	e.ppu.LoadState(s)
+	e.apu.LoadState(s)
+	if cart := e.memory.GetGameCartridge(); cart != nil {
+		cart.LoadState(s)
+	}
+	return s.Err()
+}
+
+// checkStateCartridge reads the identity of the cartridge from a snapshot and compares it with the inserted one
+func (e *Core) checkStateCartridge(s *util.StateReader) error {
+	var present bool
+	var title []byte
+	var headerChecksum byte
+	var globalChecksum uint16
+	s.Read(&present)
+	if present {
+		title = s.ReadBytes(16)
+		s.Read(&headerChecksum, &globalChecksum)
+	}
+	if s.Err() != nil {
+		return ErrInvalidState
+	}
+
+	cart := e.memory.GetGameCartridge()
+	switch {
+	case !present && cart == nil:
+		return nil
+	case cart == nil:
+		return ErrStateCartridgeAbsent
+	case !present:
+		return ErrStateCartridge
+	}
+	header := cart.Header()
+	if header.Title != string(title) ||
+		header.HeaderChecksum != headerChecksum || header.GlobalChecksum != globalChecksum {
+		return ErrStateCartridge
+	}
+	return nil
 }
@@ -0,0 +1,388 @@
+package emulation
+
+import (
+	"bytes"
+	"gameboy-emulator/internal/cycle/apu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Boot ROM which only unmaps itself and then slides over the NOPs of the test ROM to 0x0100
+var testBootRom = [0x100]byte{
+	0x3E, 0x01, // LD A, 0x01
+	0xE0, 0x50, // LD (0xFF00+0x50), A
+}
+
+// Program of the test ROM which turns on sound, timer and LCD and then keeps on writing into VRAM.
+var testProgram = []byte{
+	0xC3, 0x50, 0x01, // 0x0100: JP 0x0150
+}
+
+var testProgramMain = []byte{
+	0x31, 0xFE, 0xFF, // 0x0150: LD SP, 0xFFFE
+	0x3E, 0x80, 0xE0, 0x26, // NR52: APU on
+	0x3E, 0x77, 0xE0, 0x24, // NR50: full volume
+	0x3E, 0xFF, 0xE0, 0x25, // NR51: all channels on both sides
+	0x3E, 0x80, 0xE0, 0x11, // NR11: 50% duty
+	0x3E, 0xF0, 0xE0, 0x12, // NR12: initial volume 15
+	0x3E, 0x00, 0xE0, 0x13, // NR13: period low
+	0x3E, 0x87, 0xE0, 0x14, // NR14: period high + trigger
+	0x3E, 0x05, 0xE0, 0x07, // TAC: timer on
+	0x3E, 0x91, 0xE0, 0x40, // LCDC: LCD and background on
+	0x06, 0x00, // 0x0177: LD B, 0x00
+	0x21, 0x00, 0x80, // 0x0179: LD HL, 0x8000
+	0x04,       // 0x017C: INC B
+	0x78,       // 0x017D: LD A, B
+	0xCB, 0x37, // 0x017E: SWAP A
+	0x22,       // 0x0180: LD (HL+), A
+	0xE0, 0x43, // 0x0181: LD (0xFF00+0x43), A
+	0x7C,       // 0x0183: LD A, H
+	0xFE, 0x98, // 0x0184: CP 0x98
+	0x20, 0xF4, // 0x0186: JR NZ, 0x017C
+	0x18, 0xEF, // 0x0188: JR 0x0179
+}
+
+func newTestCore(t *testing.T) *Core {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], testProgram)
+	copy(rom[0x150:], testProgramMain)
+
+	romPath := filepath.Join(t.TempDir(), "test.gb")
+	require.NoError(t, os.WriteFile(romPath, rom, 0644))
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridge(romPath))
+	return core
+}
+
+// runCycles ticks the core the given number of times and returns all produced audio samples
+func runCycles(core *Core, cycles int) []byte {
+	var samples []byte
+	for n := 0; n < cycles; n++ {
+		if left, right, play := core.Tick(); play {
+			samples = append(samples, left, right)
+		}
+	}
+	return samples
+}
+
+// finishInstruction ticks the core until the instruction in progress is completed, so a snapshot can be taken
+func finishInstruction(core *Core) {
+	for !core.AtInstructionBoundary() {
+		core.Tick()
+	}
+}
+
+func TestCore_SaveStateLoadState_resumesIdentically(t *testing.T) {
+	// GIVEN
+	original := newTestCore(t)
+	runCycles(original, 5*CyclesPerFrame+1234) // stop somewhere in the middle of a frame
+	finishInstruction(original)
+
+	var state bytes.Buffer
+	require.NoError(t, original.SaveState(&state))
+
+	restored := newTestCore(t)
+	runCycles(restored, 777) // bring the second core into a different state
+
+	// WHEN
+	require.NoError(t, restored.LoadState(bytes.NewReader(state.Bytes())))
+
+	originalSamples := runCycles(original, 3*CyclesPerFrame)
+	restoredSamples := runCycles(restored, 3*CyclesPerFrame)
+	finishInstruction(original)
+	finishInstruction(restored)
+
+	// THEN
+	assert.NotEmpty(t, originalSamples)
+	assert.Equal(t, originalSamples, restoredSamples)
+
+	var originalState, restoredState bytes.Buffer
+	require.NoError(t, original.SaveState(&originalState))
+	require.NoError(t, restored.SaveState(&restoredState))
+	assert.Equal(t, originalState.Bytes(), restoredState.Bytes())
+}
+
+func TestCore_SaveState_inInstruction(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	for core.AtInstructionBoundary() {
+		core.Tick()
+	}
+	cycles := core.cpu.Cycles
+
+	// WHEN
+	var state bytes.Buffer
+	err := core.SaveState(&state)
+
+	// THEN
+	assert.ErrorIs(t, err, ErrNotAtBoundary)
+	assert.Equal(t, cycles, core.cpu.Cycles) // the emulation isn't advanced
+}
+
+func TestCore_LoadState_otherCartridge(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	runCycles(core, CyclesPerFrame)
+	finishInstruction(core)
+	var state bytes.Buffer
+	require.NoError(t, core.SaveState(&state))
+
+	rom := make([]byte, 0x8000)
+	copy(rom[0x134:], "OTHER GAME")
+	other := New(&testBootRom)
+	require.NoError(t, other.InsertCartridgeImage(rom, nil))
+	runCycles(other, 1000)
+	finishInstruction(other)
+	var before bytes.Buffer
+	require.NoError(t, other.SaveState(&before))
+
+	// WHEN
+	err := other.LoadState(bytes.NewReader(state.Bytes()))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrStateCartridge)
+	var after bytes.Buffer
+	require.NoError(t, other.SaveState(&after))
+	assert.Equal(t, before.Bytes(), after.Bytes()) // the machine is left untouched
+}
+
+func TestCore_LoadState_invalidData(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+
+	// WHEN
+	err := core.LoadState(bytes.NewReader([]byte("definitely not a save state")))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestCore_LoadState_truncatedData(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	runCycles(core, CyclesPerFrame)
+	finishInstruction(core)
+
+	var state bytes.Buffer
+	require.NoError(t, core.SaveState(&state))
+	runCycles(core, 1000)
+	finishInstruction(core)
+	var before bytes.Buffer
+	require.NoError(t, core.SaveState(&before))
+
+	for _, length := range []int{state.Len() / 2, state.Len() - 1} {
+		// WHEN
+		err := core.LoadState(bytes.NewReader(state.Bytes()[:length]))
+
+		// THEN
+		assert.ErrorIs(t, err, ErrInvalidState)
+		var after bytes.Buffer
+		require.NoError(t, core.SaveState(&after))
+		assert.Equal(t, before.Bytes(), after.Bytes()) // the machine is left untouched
+	}
+}
+
+func TestCore_LoadState_otherRAMSize(t *testing.T) {
+	// GIVEN
+	newCore := func(ramSizeCode byte) *Core {
+		rom := make([]byte, 0x8000)
+		copy(rom[0x100:], testProgram)
+		copy(rom[0x150:], testProgramMain)
+		rom[0x147] = 0x03 // MBC1+RAM+BATTERY
+		rom[0x149] = ramSizeCode
+		bootRom := testBootRom
+		core := New(&bootRom)
+		require.NoError(t, core.InsertCartridgeImage(rom, nil))
+		runCycles(core, CyclesPerFrame)
+		finishInstruction(core)
+		return core
+	}
+
+	var state bytes.Buffer
+	require.NoError(t, newCore(0x02).SaveState(&state)) // 8 KiB
+	core := newCore(0x03)                               // 32 KiB
+	var before bytes.Buffer
+	require.NoError(t, core.SaveState(&before))
+
+	// WHEN
+	err := core.LoadState(bytes.NewReader(state.Bytes()))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrInvalidState)
+	var after bytes.Buffer
+	require.NoError(t, core.SaveState(&after))
+	assert.Equal(t, before.Bytes(), after.Bytes())
+}
+
+func TestCore_RunFrame_endsAtVBlank(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	core.RunFrame() // LCD is turned on during the first frame
+	cycles := core.cpu.Cycles
+
+	// WHEN
+	screen, audio := core.RunFrame()
+
+	// THEN
+	assert.InDelta(t, CyclesPerFrame/4, core.cpu.Cycles-cycles, 6) // frames end at the next instruction boundary
+	assert.True(t, core.AtInstructionBoundary())
+	assert.Equal(t, byte(144), core.memory.Read(0xFF44)) // LY
+	assert.Equal(t, core.GetScreen(), screen)
+	assert.InDelta(t, 2*apu.SamplingRate/60, len(audio), 10)
+}
+
+func TestCore_RunFrame_lcdOff(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{0x18, 0xFE}) // JR -2
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+
+	// WHEN
+	core.RunFrame()
+	core.RunFrame()
+
+	// THEN
+	assert.Equal(t, uint64(2*CyclesPerFrame/4), core.cpu.Cycles)
+}
+
+func TestCore_SetRumbleHandler(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	rom[0x147] = 0x1C // MBC5+RUMBLE
+	copy(rom[0x100:], []byte{
+		0x3E, 0x08, // LD A, 0x08
+		0xEA, 0x00, 0x40, // LD (0x4000), A: motor on
+		0x18, 0xFE, // JR -2
+	})
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+
+	var intensities []float64
+	core.SetRumbleHandler(func(on bool, intensity float64) {
+		assert.True(t, on)
+		intensities = append(intensities, intensity)
+	})
+
+	// WHEN
+	for i := 0; i < 2*CyclesPerFrame; i++ {
+		core.Tick()
+	}
+
+	// THEN
+	require.Len(t, intensities, 2)
+	assert.InDelta(t, 1.0, intensities[0], 0.05) // the motor is turned on shortly after power-on
+	assert.Equal(t, 1.0, intensities[1])
+}
+
+func TestCore_SetRumbleHandler_atVBlank(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	rom[0x147] = 0x1C // MBC5+RUMBLE
+	copy(rom[0x100:], []byte{
+		0x3E, 0x80, 0xE0, 0x40, // LCDC: LCD on
+		0x3E, 0x08, // LD A, 0x08
+		0xEA, 0x00, 0x40, // LD (0x4000), A: motor on
+		0x18, 0xFE, // JR -2
+	})
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+
+	var lines []byte
+	core.SetRumbleHandler(func(on bool, intensity float64) {
+		lines = append(lines, core.memory.Read(0xFF44))
+	})
+
+	// WHEN
+	core.RunFrame()
+	core.RunFrame()
+
+	// THEN
+	assert.Equal(t, []byte{144, 144}, lines) // reported when VBlank is entered
+}
+
+func TestCore_ImportExportSaveGame(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	rom := make([]byte, 0x8000)
+	rom[0x147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x149] = 0x02 // 8 KiB RAM
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "import.sav"), []byte{0x42}, 0644))
+
+	// WHEN
+	errMissing := core.ImportSaveGame(filepath.Join(dir, "import.sav"))
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+	errImport := core.ImportSaveGame(filepath.Join(dir, "import.sav"))
+	errExport := core.ExportSaveGame(filepath.Join(dir, "export.sav"))
+
+	// THEN
+	assert.ErrorIs(t, errMissing, ErrNoCartridge)
+	require.NoError(t, errImport)
+	require.NoError(t, errExport)
+	exported, err := os.ReadFile(filepath.Join(dir, "export.sav"))
+	require.NoError(t, err)
+	assert.Len(t, exported, 0x2000)
+	assert.Equal(t, byte(0x42), exported[0])
+}
+
+func TestCore_cheats(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{
+		0x3E, 0x80, // LD A, 0x80
+		0xE0, 0x40, // LD (0xFF00+0x40), A: LCD on
+		0x3E, 0x11, // 0x0104: LD A, 0x11
+		0xEA, 0x00, 0xC0, // LD (0xC000), A
+		0x18, 0xFE, // JR -2
+	})
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+	require.NoError(t, core.GetCheats().Add("221-05F", "LD A, 0x22")) // 0x0105: 0x11 -> 0x22
+	require.NoError(t, core.GetCheats().Add("014201C0", ""))          // 0xC001 = 0x42
+
+	// WHEN
+	for i := 0; i < 2*CyclesPerFrame; i++ {
+		core.Tick()
+	}
+
+	// THEN
+	assert.Equal(t, byte(0x22), core.memory.Read(0xC000))
+	assert.Equal(t, byte(0x42), core.memory.Read(0xC001))
+}
+
+func TestCore_InsertCartridge_cheatFile(t *testing.T) {
+	// GIVEN
+	dir := t.TempDir()
+	rom := make([]byte, 0x8000)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "game.gb"), rom, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "game.cht"), []byte("off 014201C0 Lives\n"), 0644))
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+
+	// WHEN
+	require.NoError(t, core.InsertCartridge(filepath.Join(dir, "game.gb")))
+	core.GetCheats().SetEnabled(0, true)
+	require.NoError(t, core.SaveCheats())
+
+	// THEN
+	data, err := os.ReadFile(filepath.Join(dir, "game.cht"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "on 014201C0 Lives")
+}
@@ -0,0 +1,394 @@
+package emulation
+
+import (
+	"gameboy-emulator/internal/cycle/cpu"
+	"gameboy-emulator/pkg/disasm"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// StopReason tells why the debugger stopped the emulation
+type StopReason int
+
+const (
+	StopPause      StopReason = iota // Pause was requested
+	StopStep                         // Step, StepOver, StepOut or RunTo completed
+	StopBreakpoint                   // a breakpoint was hit
+	StopWatchpoint                   // a watchpoint was hit
+)
+
+func (r StopReason) String() string {
+	switch r {
+	case StopPause:
+		return "pause"
+	case StopStep:
+		return "step"
+	case StopBreakpoint:
+		return "breakpoint"
+	case StopWatchpoint:
+		return "watchpoint"
+	}
+	return "unknown"
+}
+
+// runMode decides at which instruction the debugger stops next, besides breakpoints and pause requests
+type runMode byte
+
+const (
+	runFree  runMode = iota // only stop at breakpoints
+	runStep                 // stop at the next instruction
+	runUntil                // stop at the target address once the stack is back at the target stack pointer
+	runOut                  // stop after returning to a caller above the target stack pointer
+)
+
+type (
+	// Breakpoint stops the emulation before the instruction at its address is executed
+	Breakpoint struct {
+		ID      int
+		Address uint16
+
+		// Bank is the ROM bank which has to be mapped at the address (see cartridge.Cartridge.ROMBank), -1 for any
+		// bank. It's ignored for addresses outside of the ROM.
+		Bank int
+
+		// Condition has to hold as well, it's empty for unconditional breakpoints
+		Condition Condition
+	}
+
+	// Stop describes where the debugger stopped the emulation
+	Stop struct {
+		Reason     StopReason
+		Breakpoint int      // ID of the breakpoint hit for StopBreakpoint
+		Watch      WatchHit // access which hit a watchpoint for StopWatchpoint
+		Registers  cpu.Registers
+
+		// Bank is the ROM bank mapped at PC, -1 outside of the ROM or without cartridge
+		Bank int
+
+		// Halted is true if the CPU waits for an interrupt. PC is the address of the HALT instruction then.
+		Halted bool
+
+		// Instruction is the disassembly of the instruction at PC, e.g. "CALL $0150"
+		Instruction string
+	}
+
+	// StopHandler is called by the goroutine ticking the core whenever the debugger stops the emulation
+	StopHandler func(stop Stop)
+
+	// Debugger stops the emulation at breakpoints and steps through the program instruction by instruction. It
+	// only stops between instructions, never in the middle of one. While stopped, Core.Tick blocks until the
+	// emulation is resumed, so the goroutine ticking the core (like a driver or a loop calling RunFrame) stalls.
+	//
+	// All functions are safe for concurrent use, they are meant to be called by another goroutine than the one
+	// ticking the core.
+	Debugger struct {
+		core *Core
+
+		mutex       sync.Mutex
+		resumed     *sync.Cond
+		stopped     bool
+		current     Stop
+		opCode      byte // opcode of the instruction at PC while stopped
+		breakpoints []Breakpoint
+		nextID      int
+		stopHandler StopHandler
+
+		mode     runMode
+		targetPC uint16
+		targetSP uint16
+
+		watchpoints    atomic.Pointer[[]Watchpoint]
+		pauseRequested atomic.Bool
+		detached       atomic.Bool
+
+		// only accessed by the goroutine ticking the core
+		lastCycles     uint64
+		previousOpCode byte      // opcode of the instruction executed last
+		executingPC    uint16    // address of the instruction being executed
+		hit            *WatchHit // first watchpoint hit by the instruction being executed
+	}
+)
+
+func newDebugger(core *Core) *Debugger {
+	d := &Debugger{core: core, lastCycles: core.cpu.Cycles, executingPC: core.cpu.Registers().PC}
+	d.resumed = sync.NewCond(&d.mutex)
+	core.memory.SetWatcher((*memoryWatcher)(d))
+	return d
+}
+
+// Debugger returns the debugger of the core, which is attached with the first call. A detached debugger (see
+// Debugger.Detach) is attached again.
+//
+// The first call must not happen while a driver is ticking the core concurrently.
+func (e *Core) Debugger() *Debugger {
+	if e.debugger == nil {
+		e.debugger = newDebugger(e)
+	}
+	e.debugger.detached.Store(false)
+	return e.debugger
+}
+
+// SetStopHandler registers the handler which is called whenever the emulation is stopped
+func (d *Debugger) SetStopHandler(handler StopHandler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.stopHandler = handler
+}
+
+// AddBreakpoint adds a breakpoint at the given address, which is only hit if the given ROM bank is mapped (-1 for
+// any bank) and the given condition holds (see ParseCondition, empty for none).
+func (d *Debugger) AddBreakpoint(address uint16, bank int, condition string) (Breakpoint, error) {
+	parsed, err := ParseCondition(condition)
+	if err != nil {
+		return Breakpoint{}, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.nextID++
+	breakpoint := Breakpoint{ID: d.nextID, Address: address, Bank: bank, Condition: parsed}
+	d.breakpoints = append(d.breakpoints, breakpoint)
+	return breakpoint, nil
+}
+
+// RemoveBreakpoint removes the breakpoint with the given ID. It returns false if there is no such breakpoint.
+func (d *Debugger) RemoveBreakpoint(id int) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	index := slices.IndexFunc(d.breakpoints, func(b Breakpoint) bool { return b.ID == id })
+	if index < 0 {
+		return false
+	}
+	d.breakpoints = slices.Delete(d.breakpoints, index, index+1)
+	return true
+}
+
+// Breakpoints returns all breakpoints in the order they were added
+func (d *Debugger) Breakpoints() []Breakpoint {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return slices.Clone(d.breakpoints)
+}
+
+// Pause stops the emulation before the next instruction, or right away while the CPU is halted. The stop handler
+// is called once the emulation is stopped.
+func (d *Debugger) Pause() {
+	d.pauseRequested.Store(true)
+}
+
+// Stopped returns true while the emulation is stopped
+func (d *Debugger) Stopped() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.stopped
+}
+
+// Continue resumes the emulation until the next breakpoint is hit. Like the stepping functions, it's ignored
+// unless the emulation is stopped.
+func (d *Debugger) Continue() {
+	d.resume(runFree, 0, 0)
+}
+
+// Step executes a single instruction. If an interrupt is dispatched meanwhile, it stops at the first instruction of
+// the interrupt handler.
+func (d *Debugger) Step() {
+	d.resume(runStep, 0, 0)
+}
+
+// StepOver executes a single instruction like Step, but runs called subroutines (CALL and RST) until they return.
+func (d *Debugger) StepOver() {
+	d.mutex.Lock()
+	pc, sp, opCode := d.current.Registers.PC, d.current.Registers.SP, d.opCode
+	d.mutex.Unlock()
+
+	switch {
+	case opCode == 0xCD || opCode&0xE7 == 0xC4: // CALL nn, CALL cc, nn
+		d.resume(runUntil, pc+3, sp)
+	case opCode&0xC7 == 0xC7: // RST
+		d.resume(runUntil, pc+1, sp)
+	default:
+		d.resume(runStep, 0, 0)
+	}
+}
+
+// StepOut runs until the current subroutine returns to its caller.
+func (d *Debugger) StepOut() {
+	d.mutex.Lock()
+	sp := d.current.Registers.SP
+	d.mutex.Unlock()
+	d.resume(runOut, 0, sp)
+}
+
+// RunTo runs until the instruction at the given address is about to be executed, e.g. the line under the cursor of
+// a disassembly.
+func (d *Debugger) RunTo(address uint16) {
+	d.resume(runUntil, address, 0)
+}
+
+// ReadMemory reads the given address like the CPU does, without hitting watchpoints. Must only be called while the
+// emulation is stopped.
+func (d *Debugger) ReadMemory(address uint16) byte {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.core.memory.Peek(address)
+}
+
+// Detach removes all breakpoints and watchpoints and resumes the emulation, which then runs without being stopped until the
+// debugger is attached again (see Core.Debugger).
+func (d *Debugger) Detach() {
+	d.detached.Store(true)
+	d.pauseRequested.Store(false)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.breakpoints = nil
+	d.setWatchpoints(nil)
+	d.mode = runFree
+	d.stopped = false
+	d.resumed.Broadcast()
+}
+
+func (d *Debugger) resume(mode runMode, targetPC uint16, targetSP uint16) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.stopped {
+		return
+	}
+	d.mode, d.targetPC, d.targetSP = mode, targetPC, targetSP
+	d.stopped = false
+	d.resumed.Broadcast()
+}
+
+// afterTick is called by the core after every tick. Between instructions, it decides whether to stop and blocks
+// until the emulation is resumed.
+func (d *Debugger) afterTick() {
+	c := d.core.cpu
+	if d.detached.Load() {
+		d.hit = nil
+		return
+	}
+	if !c.AtInstructionBoundary() {
+		return
+	}
+
+	start := c.AtInstructionStart()
+	if start && c.Cycles != d.lastCycles {
+		d.lastCycles = c.Cycles
+		registers := c.Registers()
+		opCode, _ := c.Instruction()
+
+		s, stop := d.checkStop(registers, opCode)
+		d.previousOpCode, d.executingPC, d.hit = opCode, registers.PC, nil
+		if stop {
+			d.stop(s)
+			return
+		}
+	}
+
+	if d.pauseRequested.Load() && (start || c.Halted()) {
+		d.stop(Stop{Reason: StopPause})
+	}
+}
+
+// checkStop decides whether to stop before the instruction with the given opcode at the PC of the given registers
+func (d *Debugger) checkStop(registers cpu.Registers, opCode byte) (Stop, bool) {
+	if d.hit != nil {
+		return Stop{Reason: StopWatchpoint, Watch: *d.hit}, true
+	}
+
+	if watchpoint, ok := d.watchpointHit(registers.PC, WatchExecute, opCode); ok {
+		return Stop{Reason: StopWatchpoint, Watch: WatchHit{
+			Watchpoint: watchpoint.ID,
+			Access:     WatchExecute,
+			Address:    registers.PC,
+			Old:        opCode,
+			New:        opCode,
+			PC:         registers.PC,
+			ROMBank:    d.romBank(registers.PC),
+			RAMBank:    d.ramBank(registers.PC),
+		}}, true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, b := range d.breakpoints {
+		if b.Address == registers.PC && d.bankMatches(b) && b.Condition.Holds(registers) {
+			return Stop{Reason: StopBreakpoint, Breakpoint: b.ID}, true
+		}
+	}
+
+	switch d.mode {
+	case runStep:
+		return Stop{Reason: StopStep}, true
+	case runUntil:
+		return Stop{Reason: StopStep}, registers.PC == d.targetPC && registers.SP >= d.targetSP
+	case runOut:
+		return Stop{Reason: StopStep}, isReturn(d.previousOpCode) && registers.SP > d.targetSP
+	}
+	return Stop{}, false
+}
+
+func (d *Debugger) bankMatches(breakpoint Breakpoint) bool {
+	return breakpoint.Bank < 0 || breakpoint.Address >= 0x8000 || d.romBank(breakpoint.Address) == breakpoint.Bank
+}
+
+// romBank returns the ROM bank mapped at the given address, -1 outside of the ROM or without cartridge
+func (d *Debugger) romBank(address uint16) int {
+	cart := d.core.memory.GetGameCartridge()
+	if cart == nil || address >= 0x8000 {
+		return -1
+	}
+	return cart.ROMBank(address)
+}
+
+// stop completes the given stop with the state of the CPU, reports it to the stop handler and blocks until the
+// emulation is resumed
+func (d *Debugger) stop(s Stop) {
+	c := d.core.cpu
+	opCode, _ := c.Instruction()
+	s.Registers = c.Registers()
+	s.Bank = d.romBank(s.Registers.PC)
+	s.Halted = c.Halted()
+	s.Instruction = d.disassemble(s.Registers.PC, s.Bank)
+
+	d.pauseRequested.Store(false)
+
+	d.mutex.Lock()
+	d.current = s
+	d.opCode = opCode
+	d.mode = runFree
+	d.stopped = true
+	handler := d.stopHandler
+	current := d.current
+	d.mutex.Unlock()
+
+	if handler != nil {
+		handler(current)
+	}
+
+	d.mutex.Lock()
+	for d.stopped {
+		d.resumed.Wait()
+	}
+	d.mutex.Unlock()
+}
+
+// disassemble decodes the instruction at the given address with its operands
+func (d *Debugger) disassemble(address uint16, bank int) string {
+	code := make([]byte, 3)
+	for i := range code {
+		code[i] = d.core.memory.Peek(address + uint16(i))
+	}
+	instruction, _ := disasm.Decode(code, bank, address, nil)
+	return instruction.Text
+}
+
+// isReturn returns true for the opcodes of RET, RET cc and RETI
+func isReturn(opCode byte) bool {
+	return opCode == 0xC9 || opCode == 0xD9 || opCode&0xE7 == 0xC0
+}
@@ -0,0 +1,165 @@
+package emulation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Program which keeps on calling a subroutine while counting up A
+var debuggerTestProgram = []byte{
+	0x31, 0xFE, 0xFF, // 0x0100: LD SP, 0xFFFE
+	0x3E, 0x00, // 0x0103: LD A, 0x00
+	0xCD, 0x50, 0x01, // 0x0105: CALL 0x0150
+	0x3C,       // 0x0108: INC A
+	0x18, 0xFA, // 0x0109: JR 0x0105
+}
+
+var debuggerTestSubroutine = []byte{
+	0x47, // 0x0150: LD B, A
+	0x04, // 0x0151: INC B
+	0xC9, // 0x0152: RET
+}
+
+func newDebuggerTestCore(t *testing.T) *Core {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], debuggerTestProgram)
+	copy(rom[0x150:], debuggerTestSubroutine)
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+	return core
+}
+
+// runInBackground ticks the core in another goroutine until the test ends and returns the stops of its debugger
+func runInBackground(t *testing.T, core *Core) <-chan Stop {
+	debugger := core.Debugger()
+	stops := make(chan Stop, 16)
+	debugger.SetStopHandler(func(stop Stop) { stops <- stop })
+
+	var done atomic.Bool
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for !done.Load() {
+			core.Tick()
+		}
+	}()
+
+	t.Cleanup(func() {
+		done.Store(true)
+		debugger.Detach()
+		<-finished
+	})
+	return stops
+}
+
+func nextStop(t *testing.T, stops <-chan Stop) Stop {
+	select {
+	case stop := <-stops:
+		return stop
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "debugger didn't stop")
+		return Stop{}
+	}
+}
+
+func TestDebugger_breakpoint(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	debugger := core.Debugger()
+	breakpoint, err := debugger.AddBreakpoint(0x0150, -1, "")
+	require.NoError(t, err)
+
+	// WHEN
+	stops := runInBackground(t, core)
+	first := nextStop(t, stops)
+	debugger.Continue()
+	second := nextStop(t, stops)
+
+	// THEN
+	assert.Equal(t, StopBreakpoint, first.Reason)
+	assert.Equal(t, breakpoint.ID, first.Breakpoint)
+	assert.Equal(t, uint16(0x0150), first.Registers.PC)
+	assert.Equal(t, uint16(0xFFFC), first.Registers.SP)
+	assert.Equal(t, 0, first.Bank)
+	assert.Equal(t, "LD B, A", first.Instruction)
+	assert.Equal(t, byte(0), first.Registers.A)
+	assert.Equal(t, byte(1), second.Registers.A)
+	assert.True(t, debugger.Stopped())
+}
+
+func TestDebugger_conditionalBreakpoint(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	debugger := core.Debugger()
+	_, err := debugger.AddBreakpoint(0x0150, 1, "") // bank 1 is never mapped there
+	require.NoError(t, err)
+	breakpoint, err := debugger.AddBreakpoint(0x0108, -1, "a == 3 && b == 4")
+	require.NoError(t, err)
+
+	// WHEN
+	stop := nextStop(t, runInBackground(t, core))
+
+	// THEN
+	assert.Equal(t, breakpoint.ID, stop.Breakpoint)
+	assert.Equal(t, uint16(0x0108), stop.Registers.PC)
+	assert.Equal(t, byte(3), stop.Registers.A)
+}
+
+func TestDebugger_stepping(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	debugger := core.Debugger()
+	breakpoint, err := debugger.AddBreakpoint(0x0105, -1, "")
+	require.NoError(t, err)
+	stops := runInBackground(t, core)
+	nextStop(t, stops)
+	debugger.RemoveBreakpoint(breakpoint.ID)
+
+	var pcs []uint16
+	step := func(command func()) {
+		command()
+		stop := nextStop(t, stops)
+		assert.Equal(t, StopStep, stop.Reason)
+		pcs = append(pcs, stop.Registers.PC)
+	}
+
+	// WHEN
+	step(debugger.StepOver)
+	step(debugger.Step)
+	step(debugger.Step)
+	step(debugger.Step)
+	step(debugger.Step)
+	step(debugger.StepOut)
+	step(func() { debugger.RunTo(0x0152) })
+
+	// THEN
+	assert.Equal(t, []uint16{0x0108, 0x0109, 0x0105, 0x0150, 0x0151, 0x0108, 0x0152}, pcs)
+}
+
+func TestDebugger_Pause(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], []byte{0x18, 0xFE}) // JR -2
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+	debugger := core.Debugger()
+	stops := runInBackground(t, core)
+
+	// WHEN
+	debugger.Pause()
+	stop := nextStop(t, stops)
+	opCode := debugger.ReadMemory(0x0100)
+	debugger.Continue()
+
+	// THEN
+	assert.Equal(t, StopPause, stop.Reason)
+	assert.Equal(t, byte(0x18), opCode)
+	assert.Eventually(t, func() bool { return !debugger.Stopped() }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,204 @@
+package emulation
+
+import (
+	"gameboy-emulator/internal/cycle/apu"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// FrameDuration is the time the original hardware needs to draw one frame (~16.74ms, ~59.73 Hz)
+	FrameDuration = time.Duration(CyclesPerFrame * int64(time.Second) / int64(apu.GameBoyClockSpeed))
+
+	// If the emulation falls behind more than this, it does not try to catch up anymore but continues
+	// pacing from the current point in time (e.g. after the host was suspended).
+	maxLag = 10 * FrameDuration
+
+	// Time window after which FPS and speed are recalculated
+	measurementWindow = time.Second
+)
+
+type (
+	// Clock is a monotonic time source. It can be replaced for testing.
+	Clock interface {
+		Now() time.Time
+		Sleep(d time.Duration)
+	}
+
+	systemClock struct{}
+
+	// FrameEmulator emulates one frame at a time, see gameboy.Emulator
+	FrameEmulator interface {
+		// StepFrame emulates the next frame (70224 T-cycles)
+		StepFrame()
+
+		// Rewind emulates the frame of the latest snapshot of the history. It returns false if the history is
+		// exhausted.
+		Rewind() bool
+	}
+
+	// FramePacedDriver runs the emulation frame by frame (70224 T-cycles each) and sleeps after every frame
+	// until the point in time the frame would have been finished on the original hardware. Deadlines are
+	// derived from the previous deadline instead of the current time, so inaccuracies of sleeping are
+	// corrected with the next frame and don't add up.
+	FramePacedDriver struct {
+		emulator FrameEmulator
+		clock    Clock
+
+		// flags set by the UI and read by the goroutine running the emulation
+		paused    atomic.Bool
+		stopped   atomic.Bool
+		turbo     atomic.Bool
+		rewinding atomic.Bool
+
+		done chan struct{}
+
+		deadline time.Time
+
+		statsMutex  sync.Mutex
+		windowStart time.Time
+		windowCount int
+		fps         float64
+	}
+)
+
+// NewFramePacedDriver creates a driver for the given emulator. If clock is nil, the clock of the system is used.
+func NewFramePacedDriver(emulator FrameEmulator, clock Clock) *FramePacedDriver {
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &FramePacedDriver{
+		emulator: emulator,
+		clock:    clock,
+	}
+}
+
+// Run starts running the emulation in its own goroutine until Stop is called.
+func (d *FramePacedDriver) Run() {
+	d.stopped.Store(false) // Stop may have been called while not running
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+
+		d.startPacing()
+		for !d.stopped.Load() {
+
+			if d.paused.Load() {
+				d.clock.Sleep(10 * time.Millisecond)
+				d.startPacing()
+				continue
+			}
+
+			d.runFrame()
+		}
+
+		d.paused.Store(false)
+	}()
+}
+
+func (d *FramePacedDriver) ToggleTurbo() {
+	d.turbo.Store(!d.turbo.Load())
+}
+
+func (d *FramePacedDriver) TogglePause() {
+	d.paused.Store(!d.paused.Load())
+}
+
+func (d *FramePacedDriver) IsPaused() bool {
+	return d.paused.Load()
+}
+
+// Stop ends the emulation and waits until the emulator is not running anymore.
+func (d *FramePacedDriver) Stop() {
+	d.stopped.Store(true)
+	if d.done != nil {
+		<-d.done
+		d.done = nil
+	}
+}
+
+// SetRewinding starts or stops playing back the recorded history in reverse, see FrameEmulator.Rewind.
+func (d *FramePacedDriver) SetRewinding(rewinding bool) {
+	d.rewinding.Store(rewinding)
+}
+
+func (d *FramePacedDriver) IsRewinding() bool {
+	return d.rewinding.Load()
+}
+
+// FPS returns the number of frames per second measured during the last measurement window.
+func (d *FramePacedDriver) FPS() float64 {
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+	return d.fps
+}
+
+// Speed returns the measured emulation speed in percent of the speed of the original hardware.
+func (d *FramePacedDriver) Speed() float64 {
+	return d.FPS() * FrameDuration.Seconds() * 100
+}
+
+// startPacing (re)starts pacing and measuring from the current point in time
+func (d *FramePacedDriver) startPacing() {
+	now := d.clock.Now()
+	d.deadline = now
+
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+	d.windowStart = now
+	d.windowCount = 0
+}
+
+// runFrame emulates one frame, or one frame of the history while rewinding. If the history is exhausted, the
+// emulation stands still until rewinding is stopped.
+func (d *FramePacedDriver) runFrame() {
+	if d.IsRewinding() {
+		d.emulator.Rewind()
+	} else {
+		d.emulator.StepFrame()
+	}
+	d.pace()
+}
+
+// pace waits until the deadline of the current frame is reached
+func (d *FramePacedDriver) pace() {
+	// frame statistics are recorded when the frame is actually finished (including waiting)
+	defer func() { d.measure(d.clock.Now()) }()
+
+	now := d.clock.Now()
+	if d.turbo.Load() {
+		d.deadline = now
+		return
+	}
+
+	d.deadline = d.deadline.Add(FrameDuration)
+	if now.Sub(d.deadline) > maxLag {
+		d.deadline = now
+		return
+	}
+
+	if wait := d.deadline.Sub(now); wait > 0 {
+		d.clock.Sleep(wait)
+	}
+}
+
+func (d *FramePacedDriver) measure(now time.Time) {
+	d.statsMutex.Lock()
+	defer d.statsMutex.Unlock()
+
+	d.windowCount++
+	if elapsed := now.Sub(d.windowStart); elapsed >= measurementWindow {
+		d.fps = float64(d.windowCount) / elapsed.Seconds()
+		d.windowStart = now
+		d.windowCount = 0
+	}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
@@ -0,0 +1,215 @@
+package emulation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now        time.Time
+	oversleep  time.Duration // added to every sleep to simulate an inaccurate sleep of the host
+	sleepCount int
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleepCount++
+	c.now = c.now.Add(d + c.oversleep)
+}
+
+// work simulates the time the host needs to emulate a frame
+func (c *fakeClock) work(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// fakeFrameEmulator counts the frames it was asked to emulate
+type fakeFrameEmulator struct {
+	frames, rewound int
+	stepped         chan struct{} // signaled for every frame if set
+}
+
+func (e *fakeFrameEmulator) StepFrame() {
+	e.frames++
+	select {
+	case e.stepped <- struct{}{}:
+	default:
+	}
+}
+
+func (e *fakeFrameEmulator) Rewind() bool {
+	e.rewound++
+	return true
+}
+
+func TestFramePacedDriver_Run_afterStop(t *testing.T) {
+	// GIVEN
+	emulator := &fakeFrameEmulator{stepped: make(chan struct{}, 1)}
+	driver := NewFramePacedDriver(emulator, nil)
+	driver.Stop()
+
+	// WHEN
+	driver.Run()
+	defer driver.Stop()
+
+	// THEN
+	select {
+	case <-emulator.stepped:
+	case <-time.After(time.Second):
+		assert.Fail(t, "no frame emulated")
+	}
+}
+
+func TestFramePacedDriver_pacesAtOriginalSpeed(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	start := clock.Now()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+
+	// WHEN
+	for i := 0; i < 120; i++ {
+		clock.work(5 * time.Millisecond)
+		driver.pace()
+	}
+
+	// THEN
+	assert.Equal(t, start.Add(120*FrameDuration), clock.Now())
+	assert.InDelta(t, 59.7275, driver.FPS(), 0.001)
+	assert.InDelta(t, 100, driver.Speed(), 0.001)
+}
+
+func TestFramePacedDriver_correctsDrift(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	clock.oversleep = 3 * time.Millisecond
+	start := clock.Now()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+
+	// WHEN
+	for i := 0; i < 600; i++ {
+		driver.pace()
+	}
+
+	// THEN
+	// The oversleeping of every frame is compensated in the following frame and doesn't add up
+	assert.Equal(t, start.Add(600*FrameDuration+3*time.Millisecond), clock.Now())
+	assert.InDelta(t, 100, driver.Speed(), 0.1)
+}
+
+func TestFramePacedDriver_catchesUpAfterSlowFrame(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	start := clock.Now()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+
+	// WHEN
+	clock.work(40 * time.Millisecond) // more than two frames
+	driver.pace()
+	for i := 0; i < 9; i++ {
+		driver.pace()
+	}
+
+	// THEN
+	assert.Equal(t, start.Add(10*FrameDuration), clock.Now())
+	assert.Equal(t, 8, clock.sleepCount) // no waiting for the first two frames
+}
+
+func TestFramePacedDriver_resynchronizesAfterLongLag(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+
+	// WHEN
+	clock.work(time.Second) // e.g. host was suspended
+	driver.pace()
+	afterLag := clock.Now()
+	driver.pace()
+
+	// THEN
+	// The following frame is paced normally instead of running ~60 frames as fast as possible
+	assert.Equal(t, afterLag.Add(FrameDuration), clock.Now())
+}
+
+func TestFramePacedDriver_reportsSlowSpeed(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+
+	// WHEN
+	for i := 0; i < 100; i++ {
+		clock.work(20 * time.Millisecond)
+		driver.pace()
+	}
+
+	// THEN
+	assert.Equal(t, 0, clock.sleepCount)
+	assert.InDelta(t, 50, driver.FPS(), 0.001)
+	assert.InDelta(t, 83.7, driver.Speed(), 0.1)
+}
+
+func TestFramePacedDriver_turboDoesNotWait(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	driver := NewFramePacedDriver(nil, clock)
+	driver.startPacing()
+	driver.ToggleTurbo()
+
+	// WHEN
+	for i := 0; i < 1000; i++ {
+		clock.work(time.Millisecond)
+		driver.pace()
+	}
+
+	// THEN
+	assert.Equal(t, 0, clock.sleepCount)
+	assert.InDelta(t, 1000, driver.FPS(), 0.001)
+}
+
+func TestFramePacedDriver_runFrame(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	emulator := &fakeFrameEmulator{}
+	driver := NewFramePacedDriver(emulator, clock)
+	driver.startPacing()
+
+	// WHEN
+	driver.runFrame()
+	driver.runFrame()
+
+	// THEN
+	assert.Equal(t, 2, clock.sleepCount)
+	assert.Equal(t, 2, emulator.frames)
+	assert.Equal(t, 0, emulator.rewound)
+}
+
+func TestFramePacedDriver_rewind(t *testing.T) {
+	// GIVEN
+	clock := newFakeClock()
+	emulator := &fakeFrameEmulator{}
+	driver := NewFramePacedDriver(emulator, clock)
+	driver.startPacing()
+
+	// WHEN
+	driver.SetRewinding(true)
+	for i := 0; i < 3; i++ {
+		driver.runFrame()
+	}
+
+	// THEN
+	assert.True(t, driver.IsRewinding())
+	assert.Equal(t, 0, emulator.frames)
+	assert.Equal(t, 3, emulator.rewound)
+	assert.Equal(t, 3, clock.sleepCount)
+}
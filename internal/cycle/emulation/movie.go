@@ -0,0 +1,165 @@
+package emulation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gameboy-emulator/internal/util"
+	"io"
+)
+
+// Movies start with this magic followed by the version of the format
+const (
+	movieMagic   = "GOMEBOY-MOVIE"
+	movieVersion = uint16(1)
+
+	// Upper bounds protecting against corrupted movies
+	maxMovieStateSize = 1 << 20
+	maxMovieFrames    = 1 << 26
+)
+
+var (
+	ErrInvalidMovie           = errors.New("not a movie")
+	ErrMovieVersion           = errors.New("unsupported movie version")
+	ErrMovieCartridgeMismatch = errors.New("movie was recorded with another cartridge")
+	ErrNoCartridge            = errors.New("no cartridge inserted")
+	ErrCheatsActive           = errors.New("movies can't be recorded while cheats are enabled")
+)
+
+// Movie is a recording of the joypad input of every frame. Played back from the same start state, it reproduces
+// a run of the emulation exactly.
+//
+// Movies starting at power-on expect the same save game (cartridge RAM) as during recording. This includes the real
+// time clock of MBC3 cartridges, so such save games have to be loaded without syncing the clock with the host (see
+// cartridge.WithHostClockSync).
+type Movie struct {
+	// Checksums from the header of the ROM the movie was recorded with
+	HeaderChecksum byte
+	GlobalChecksum uint16
+
+	// Snapshot (see Core.SaveState) the movie starts from, nil if the movie starts at power-on
+	StartState []byte
+
+	// Joypad state of every frame, a cleared bit means the key is pressed (see joypad.Joypad.SetState)
+	Input []byte
+}
+
+// ReadMovie reads a movie written by Movie.Write.
+func ReadMovie(r io.Reader) (*Movie, error) {
+	magic := make([]byte, len(movieMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != movieMagic {
+		return nil, ErrInvalidMovie
+	}
+
+	s := util.NewStateReader(r)
+
+	var version uint16
+	s.Read(&version)
+	if s.Err() != nil {
+		return nil, ErrInvalidMovie
+	}
+	if version != movieVersion {
+		return nil, fmt.Errorf("%w: %d", ErrMovieVersion, version)
+	}
+
+	m := &Movie{}
+	var hasStartState bool
+	s.Read(&m.HeaderChecksum, &m.GlobalChecksum, &hasStartState)
+	if hasStartState {
+		m.StartState = s.ReadBytes(maxMovieStateSize)
+	}
+	m.Input = s.ReadBytes(maxMovieFrames)
+
+	if s.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMovie, s.Err())
+	}
+	return m, nil
+}
+
+// Write writes the movie to the given writer.
+func (m *Movie) Write(w io.Writer) error {
+	s := util.NewStateWriter(w)
+	s.Write([]byte(movieMagic), movieVersion)
+	s.Write(m.HeaderChecksum, m.GlobalChecksum, m.StartState != nil)
+	if m.StartState != nil {
+		s.WriteBytes(m.StartState)
+	}
+	s.WriteBytes(m.Input)
+	return s.Err()
+}
+
+// RecordMovie starts recording the joypad input of every frame into a new movie. If fromPowerOn is set, the
+// machine is turned off and on again first (the save game of the cartridge is kept), otherwise the movie
+// starts with a snapshot of the current state. The input is appended to the returned movie until StopMovie
+// is called.
+//
+// Movies don't record cheats, so recording fails with ErrCheatsActive while cheats are enabled, and cheats must not
+// be enabled until the recording is stopped.
+//
+// Must not be called while a driver is ticking the core concurrently.
+func (e *Core) RecordMovie(fromPowerOn bool) (*Movie, error) {
+	cart := e.memory.GetGameCartridge()
+	if cart == nil {
+		return nil, ErrNoCartridge
+	}
+	if e.cheats != nil && e.cheats.Active() {
+		return nil, ErrCheatsActive
+	}
+
+	header := cart.Header()
+	m := &Movie{
+		HeaderChecksum: header.HeaderChecksum,
+		GlobalChecksum: header.GlobalChecksum,
+	}
+
+	if fromPowerOn {
+		e.PowerCycle()
+	} else {
+		var state bytes.Buffer
+		if err := e.SaveState(&state); err != nil {
+			return nil, err
+		}
+		m.StartState = state.Bytes()
+	}
+
+	e.playback = nil
+	e.recording = m
+	return m, nil
+}
+
+// PlayMovie restores the start state of the movie and replaces the input of the user by the recorded input
+// until the end of the movie is reached. The cartridge the movie was recorded with has to be inserted already.
+//
+// Must not be called while a driver is ticking the core concurrently.
+func (e *Core) PlayMovie(m *Movie) error {
+	cart := e.memory.GetGameCartridge()
+	if cart == nil {
+		return ErrNoCartridge
+	}
+
+	if header := cart.Header(); header.HeaderChecksum != m.HeaderChecksum || header.GlobalChecksum != m.GlobalChecksum {
+		return ErrMovieCartridgeMismatch
+	}
+
+	if m.StartState == nil {
+		e.PowerCycle()
+	} else if err := e.LoadState(bytes.NewReader(m.StartState)); err != nil {
+		return err
+	}
+
+	e.recording = nil
+	e.playback = m
+	e.playbackFrame = 0
+	return nil
+}
+
+// IsPlayingMovie returns true as long as the input of a movie is played back.
+func (e *Core) IsPlayingMovie() bool {
+	return e.playback != nil
+}
+
+// StopMovie stops recording or playing back a movie.
+func (e *Core) StopMovie() {
+	e.recording = nil
+	e.playback = nil
+}
@@ -0,0 +1,122 @@
+package emulation
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// playInput runs the given number of frames, pressing and releasing keys in between like a user would
+func playInput(core *Core, frames int) {
+	for frame := 0; frame < frames; frame++ {
+		runCycles(core, CyclesPerFrame/3)
+		if frame%3 == 0 {
+			core.KeyPressed(byte(frame % 8))
+		}
+		runCycles(core, CyclesPerFrame/3)
+		if frame%4 == 0 {
+			core.KeyReleased(byte((frame + 5) % 8))
+		}
+		runCycles(core, CyclesPerFrame-2*(CyclesPerFrame/3))
+	}
+}
+
+func TestCore_PlayMovie_reproducesRecording(t *testing.T) {
+	for name, fromPowerOn := range map[string]bool{"power-on": true, "snapshot": false} {
+		t.Run(name, func(t *testing.T) {
+			// GIVEN
+			core := newTestCore(t)
+			runCycles(core, 3*CyclesPerFrame+4321)
+			finishInstruction(core)
+
+			movie, err := core.RecordMovie(fromPowerOn)
+			require.NoError(t, err)
+			playInput(core, 29)
+			runCycles(core, CyclesPerFrame/2) // don't compare at a frame boundary, which applies the user input again
+			core.StopMovie()
+			finishInstruction(core)
+			expected := saveState(t, core)
+
+			var file bytes.Buffer
+			require.NoError(t, movie.Write(&file))
+
+			// WHEN
+			loaded, err := ReadMovie(&file)
+			require.NoError(t, err)
+
+			player := newTestCore(t)
+			runCycles(player, 1234)
+			player.KeyPressed(7) // must be ignored during playback
+			require.NoError(t, player.PlayMovie(loaded))
+			runCycles(player, 29*CyclesPerFrame+CyclesPerFrame/2)
+			finishInstruction(player)
+
+			// THEN
+			assert.Equal(t, fromPowerOn, loaded.StartState == nil)
+			assert.NotEmpty(t, loaded.Input)
+			assert.Equal(t, movie, loaded)
+			assert.Equal(t, expected, saveState(t, player))
+		})
+	}
+}
+
+func TestCore_PlayMovie_returnsToUserInputAtEnd(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	require.NoError(t, core.PlayMovie(&Movie{Input: []byte{0xFF, 0xEF}}))
+	core.joypad.WriteRegister(0x10) // select action keys
+	core.KeyPressed(5)
+
+	// WHEN
+	runCycles(core, 2*CyclesPerFrame)
+	playing := core.IsPlayingMovie()
+	joypadDuringMovie := core.joypad.ReadRegister()
+	runCycles(core, 1)
+
+	// THEN
+	assert.True(t, playing)
+	assert.False(t, core.IsPlayingMovie())
+	assert.Equal(t, byte(0xDE), joypadDuringMovie) // A pressed by the movie
+	assert.Equal(t, byte(0xDD), core.joypad.ReadRegister())
+}
+
+func TestCore_PlayMovie_otherCartridge(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+
+	// WHEN
+	err := core.PlayMovie(&Movie{HeaderChecksum: 0x42})
+
+	// THEN
+	assert.ErrorIs(t, err, ErrMovieCartridgeMismatch)
+}
+
+func TestCore_RecordMovie_cheatsActive(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	require.NoError(t, core.GetCheats().Add("010A38C1", ""))
+
+	// WHEN
+	_, errActive := core.RecordMovie(true)
+	core.GetCheats().SetEnabled(0, false)
+	_, errDisabled := core.RecordMovie(true)
+
+	// THEN
+	assert.ErrorIs(t, errActive, ErrCheatsActive)
+	assert.NoError(t, errDisabled)
+}
+
+func TestReadMovie_invalidData(t *testing.T) {
+	// GIVEN
+	var file bytes.Buffer
+	require.NoError(t, (&Movie{Input: make([]byte, 100)}).Write(&file))
+
+	// WHEN
+	_, errInvalid := ReadMovie(bytes.NewReader([]byte("not a movie at all")))
+	_, errTruncated := ReadMovie(bytes.NewReader(file.Bytes()[:file.Len()-10]))
+
+	// THEN
+	assert.ErrorIs(t, errInvalid, ErrInvalidMovie)
+	assert.ErrorIs(t, errTruncated, ErrInvalidMovie)
+}
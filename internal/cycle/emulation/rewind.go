@@ -0,0 +1,168 @@
+package emulation
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+)
+
+// Every keyframeInterval-th snapshot of the rewind buffer is stored as full snapshot, the others as delta
+const keyframeInterval = 30
+
+type (
+	// RewindBuffer keeps the recent history of the emulation as ring buffer of snapshots, taken every
+	// interval frames. To fit a couple of minutes into a bounded amount of memory, only some snapshots are
+	// stored completely (keyframes). All others are stored as XOR delta against the latest keyframe, which
+	// consists mostly of zeros and compresses very well.
+	RewindBuffer struct {
+		interval int
+		frames   int
+
+		snapshots []rewindSnapshot
+		newest    int
+		count     int
+
+		// latest keyframe, uncompressed, new deltas are calculated against it
+		keyframe      *rewindKeyframe
+		keyframeState []byte
+		sinceKeyframe int
+	}
+
+	rewindKeyframe struct {
+		data []byte // compressed
+	}
+
+	rewindSnapshot struct {
+		keyframe *rewindKeyframe
+		delta    []byte // compressed, nil if the snapshot is the keyframe itself
+	}
+)
+
+// NewRewindBuffer creates a buffer holding at most depth snapshots, taken every interval frames. The covered
+// history is therefore depth * interval frames.
+func NewRewindBuffer(depth int, interval int) *RewindBuffer {
+	return &RewindBuffer{
+		interval:  max(interval, 1),
+		snapshots: make([]rewindSnapshot, max(depth, 1)),
+	}
+}
+
+// Len returns the number of snapshots currently held.
+func (b *RewindBuffer) Len() int {
+	return b.count
+}
+
+// Clear drops the complete history, e.g. when another cartridge is inserted.
+func (b *RewindBuffer) Clear() {
+	clear(b.snapshots)
+	b.newest = 0
+	b.count = 0
+	b.frames = 0
+	b.keyframe = nil
+	b.keyframeState = nil
+	b.sinceKeyframe = 0
+}
+
+// FrameCompleted has to be called after every emulated frame and takes a snapshot of the core every interval frames.
+func (b *RewindBuffer) FrameCompleted(core *Core) error {
+	b.frames++
+	if b.frames < b.interval {
+		return nil
+	}
+	b.frames = 0
+	return b.push(core)
+}
+
+// Rewind restores the latest snapshot and removes it from the buffer. Returns false if the buffer is empty.
+func (b *RewindBuffer) Rewind(core *Core) (bool, error) {
+	if b.count == 0 {
+		return false, nil
+	}
+
+	snapshot := b.snapshots[b.newest]
+	b.snapshots[b.newest] = rewindSnapshot{}
+	b.newest = (b.newest - 1 + len(b.snapshots)) % len(b.snapshots)
+	b.count--
+	b.frames = 0
+
+	state, err := decompress(snapshot.keyframe.data)
+	if err != nil {
+		return false, err
+	}
+
+	if snapshot.delta != nil {
+		delta, err := decompress(snapshot.delta)
+		if err != nil {
+			return false, err
+		}
+		xor(state, delta)
+	}
+
+	return true, core.LoadState(bytes.NewReader(state))
+}
+
+func (b *RewindBuffer) push(core *Core) error {
+	var buffer bytes.Buffer
+	if err := core.SaveState(&buffer); err != nil {
+		return err
+	}
+	state := buffer.Bytes()
+
+	var snapshot rewindSnapshot
+
+	// The size of the state varies slightly (e.g. number of pixels in the FIFOs), a delta is only possible for
+	// states of the same size.
+	if b.keyframe == nil || b.sinceKeyframe >= keyframeInterval || len(state) != len(b.keyframeState) {
+		data, err := compress(state)
+		if err != nil {
+			return err
+		}
+
+		b.keyframe = &rewindKeyframe{data: data}
+		b.keyframeState = state
+		b.sinceKeyframe = 0
+		snapshot.keyframe = b.keyframe
+	} else {
+		xor(state, b.keyframeState)
+		delta, err := compress(state)
+		if err != nil {
+			return err
+		}
+
+		snapshot.keyframe = b.keyframe
+		snapshot.delta = delta
+	}
+	b.sinceKeyframe++
+
+	// the oldest snapshot is overwritten if the buffer is full, keyframes are released by the garbage
+	// collector as soon as no snapshot refers to them anymore
+	b.newest = (b.newest + 1) % len(b.snapshots)
+	b.snapshots[b.newest] = snapshot
+	b.count = min(b.count+1, len(b.snapshots))
+	return nil
+}
+
+func xor(data []byte, other []byte) {
+	for i := range data {
+		data[i] ^= other[i]
+	}
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	w, err := flate.NewWriter(&buffer, flate.BestSpeed)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(data); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	return io.ReadAll(flate.NewReader(bytes.NewReader(data)))
+}
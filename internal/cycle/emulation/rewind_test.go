@@ -0,0 +1,116 @@
+package emulation
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func saveState(t *testing.T, core *Core) []byte {
+	var state bytes.Buffer
+	require.NoError(t, core.SaveState(&state))
+	return state.Bytes()
+}
+
+func TestRewindBuffer_restoresSnapshotsInReverseOrder(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	buffer := NewRewindBuffer(100, 2)
+
+	var expected [][]byte
+	for frame := 1; frame <= 2*keyframeInterval+10; frame++ {
+		runCycles(core, CyclesPerFrame)
+		finishInstruction(core)
+		require.NoError(t, buffer.FrameCompleted(core))
+		if frame%2 == 0 {
+			expected = append(expected, saveState(t, core))
+		}
+	}
+
+	// WHEN
+	var restored [][]byte
+	for {
+		ok, err := buffer.Rewind(core)
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		restored = append([][]byte{saveState(t, core)}, restored...)
+	}
+
+	// THEN
+	assert.Equal(t, expected, restored)
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestRewindBuffer_dropsOldestSnapshots(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	buffer := NewRewindBuffer(5, 1)
+
+	var expected []byte
+	for frame := 1; frame <= 12; frame++ {
+		runCycles(core, CyclesPerFrame)
+		finishInstruction(core)
+		require.NoError(t, buffer.FrameCompleted(core))
+		if frame == 8 {
+			expected = saveState(t, core)
+		}
+	}
+
+	// WHEN
+	for i := 0; i < 4; i++ {
+		ok, err := buffer.Rewind(core)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+	ok, err := buffer.Rewind(core)
+	require.NoError(t, err)
+	require.True(t, ok)
+	oldest := saveState(t, core)
+	ok, err = buffer.Rewind(core)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, expected, oldest)
+}
+
+func TestRewindBuffer_storesDeltas(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	buffer := NewRewindBuffer(10, 1)
+
+	// WHEN
+	for frame := 0; frame < 10; frame++ {
+		runCycles(core, CyclesPerFrame)
+		finishInstruction(core)
+		require.NoError(t, buffer.FrameCompleted(core))
+	}
+
+	// THEN
+	keyframe := buffer.snapshots[1]
+	require.Nil(t, keyframe.delta)
+	for _, snapshot := range buffer.snapshots[2:] {
+		assert.Same(t, keyframe.keyframe, snapshot.keyframe)
+		assert.Less(t, len(snapshot.delta), len(keyframe.keyframe.data))
+	}
+}
+
+func TestRewindBuffer_Clear(t *testing.T) {
+	// GIVEN
+	core := newTestCore(t)
+	buffer := NewRewindBuffer(10, 1)
+	runCycles(core, CyclesPerFrame)
+	finishInstruction(core)
+	require.NoError(t, buffer.FrameCompleted(core))
+
+	// WHEN
+	buffer.Clear()
+
+	// THEN
+	ok, err := buffer.Rewind(core)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
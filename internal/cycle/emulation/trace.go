@@ -0,0 +1,102 @@
+package emulation
+
+import (
+	"bufio"
+	"fmt"
+	"gameboy-emulator/internal/cycle/cpu"
+	"io"
+)
+
+type (
+	// TraceOptions select the instructions written to a trace
+	TraceOptions struct {
+		// SkipBootROM starts the trace with the first instruction after the boot ROM was unmapped, which is what
+		// the reference logs of Gameboy Doctor start with
+		SkipBootROM bool
+
+		// Start starts the trace at the first execution of the instruction at this address, -1 starts it
+		// immediately. Stop ends the trace before the instruction at this address, -1 never ends it.
+		Start, Stop int
+
+		// MaxLines ends the trace after this many instructions, 0 for no limit
+		MaxLines int
+
+		// StubLY makes LY (0xFF44) read 0x90 until StopTrace is called, as the reference logs of Gameboy Doctor
+		// expect. This changes the behavior of code waiting for a line, e.g. for VBlank.
+		StubLY bool
+	}
+
+	// traceWriter writes the state of the CPU before each instruction in the format of Gameboy Doctor
+	// (https://github.com/robert/gameboy-doctor), e.g.
+	//
+	//	A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0100 PCMEM:00,C3,13,02
+	traceWriter struct {
+		core    *Core
+		out     *bufio.Writer
+		options TraceOptions
+		started bool
+		lines   int
+		err     error
+	}
+)
+
+// StartTrace writes the state of the CPU before each instruction selected by the given options to w, replacing a
+// trace already running. The trace is buffered, StopTrace flushes it.
+func (e *Core) StartTrace(w io.Writer, options TraceOptions) {
+	e.StopTrace()
+
+	e.trace = &traceWriter{
+		core:    e,
+		out:     bufio.NewWriterSize(w, 64*1024),
+		options: options,
+		started: options.Start < 0 && !options.SkipBootROM,
+	}
+	e.memory.SetStubLY(options.StubLY)
+	e.cpu.SetTracer(e.trace.write)
+}
+
+// StopTrace ends the trace and flushes it. It returns the first error writing the trace, if any.
+func (e *Core) StopTrace() error {
+	if e.trace == nil {
+		return nil
+	}
+
+	e.cpu.SetTracer(nil)
+	e.memory.SetStubLY(false)
+	trace := e.trace
+	e.trace = nil
+	if err := trace.out.Flush(); trace.err == nil {
+		trace.err = err
+	}
+	return trace.err
+}
+
+func (t *traceWriter) write(r cpu.Registers) {
+	if !t.started {
+		if t.options.SkipBootROM && t.core.memory.BootROMMapped() ||
+			t.options.Start >= 0 && int(r.PC) != t.options.Start {
+			return
+		}
+		t.started = true
+	}
+
+	if int(r.PC) == t.options.Stop || t.err != nil {
+		t.end()
+		return
+	}
+
+	m := t.core.memory
+	_, t.err = fmt.Fprintf(t.out,
+		"A:%02X F:%02X B:%02X C:%02X D:%02X E:%02X H:%02X L:%02X SP:%04X PC:%04X PCMEM:%02X,%02X,%02X,%02X\n",
+		r.A, r.F, r.B, r.C, r.D, r.E, r.H, r.L, r.SP, r.PC,
+		m.Peek(r.PC), m.Peek(r.PC+1), m.Peek(r.PC+2), m.Peek(r.PC+3))
+
+	if t.lines++; t.lines == t.options.MaxLines {
+		t.end()
+	}
+}
+
+// end stops writing the trace, which stays buffered until StopTrace is called
+func (t *traceWriter) end() {
+	t.core.cpu.SetTracer(nil)
+}
@@ -0,0 +1,82 @@
+package emulation
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCore_StartTrace_skipBootROM(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	var trace bytes.Buffer
+	core.StartTrace(&trace, TraceOptions{SkipBootROM: true, Start: -1, Stop: -1, MaxLines: 2})
+
+	// WHEN
+	for range CyclesPerFrame {
+		core.Tick()
+	}
+	err := core.StopTrace()
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t,
+		"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0004 PCMEM:00,00,00,00\n"+
+			"A:01 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0005 PCMEM:00,00,00,00\n",
+		trace.String())
+}
+
+func TestCore_StartTrace_startAndStop(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	var trace bytes.Buffer
+	core.StartTrace(&trace, TraceOptions{Start: 0x0105, Stop: 0x0108})
+
+	// WHEN
+	for range CyclesPerFrame {
+		core.Tick()
+	}
+	err := core.StopTrace()
+
+	// THEN
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(trace.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "A:00 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFE PC:0105 PCMEM:CD,50,01,3C", lines[0])
+	assert.Equal(t, "A:00 F:B0 B:00 C:13 D:00 E:D8 H:01 L:4D SP:FFFC PC:0150 PCMEM:47,04,C9,00", lines[1])
+	assert.Contains(t, lines[3], "PC:0152 ")
+}
+
+func TestCore_StartTrace_stubLY(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	for range CyclesPerFrame / 2 {
+		core.Tick()
+	}
+	line := core.PeekMemory(0xFF44)
+
+	// WHEN
+	core.StartTrace(io.Discard, TraceOptions{Start: -1, Stop: -1, StubLY: true})
+	stubbed := core.PeekMemory(0xFF44)
+	err := core.StopTrace()
+
+	// THEN
+	require.NoError(t, err)
+	assert.NotEqual(t, byte(0x90), line)
+	assert.Equal(t, byte(0x90), stubbed)
+	assert.Equal(t, line, core.PeekMemory(0xFF44))
+}
+
+func TestCore_StopTrace_notTracing(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+
+	// WHEN
+	err := core.StopTrace()
+
+	// THEN
+	assert.NoError(t, err)
+}
@@ -0,0 +1,171 @@
+package emulation
+
+import (
+	"errors"
+	"gameboy-emulator/internal/cycle/memory"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidWatchpoint = errors.New("invalid watchpoint")
+
+// WatchAccess is a set of the kinds of memory accesses a watchpoint watches
+type WatchAccess byte
+
+const (
+	WatchRead WatchAccess = 1 << iota
+	WatchWrite
+	WatchExecute
+)
+
+func (a WatchAccess) String() string {
+	var kinds []string
+	for _, kind := range []struct {
+		access WatchAccess
+		name   string
+	}{{WatchRead, "read"}, {WatchWrite, "write"}, {WatchExecute, "execute"}} {
+		if a&kind.access != 0 {
+			kinds = append(kinds, kind.name)
+		}
+	}
+	return strings.Join(kinds, "/")
+}
+
+type (
+	// Watchpoint stops the emulation after an instruction accessed an address of its range. Execute watchpoints
+	// stop before the instruction is executed, like breakpoints.
+	Watchpoint struct {
+		ID         int
+		Start, End uint16 // range of watched addresses, both bounds inclusive
+		Access     WatchAccess
+
+		// Value has to be read, written or executed (the opcode) to hit the watchpoint, -1 for any value
+		Value int
+	}
+
+	// WatchHit describes the access which hit a watchpoint
+	WatchHit struct {
+		Watchpoint int // ID of the watchpoint
+		Access     WatchAccess
+		Address    uint16
+		Old, New   byte // value before and after a write, both are the value read or executed otherwise
+
+		// PC is the address of the instruction which accessed the memory, ROMBank is the ROM bank mapped there
+		PC      uint16
+		ROMBank int
+
+		// RAMBank is the cartridge RAM bank of accesses to 0xA000-0xBFFF (see cartridge.Cartridge.RAMBank), -1
+		// for other addresses
+		RAMBank int
+	}
+
+	// memoryWatcher reports memory accesses to the watchpoints of the debugger
+	memoryWatcher Debugger
+)
+
+// AddWatchpoint adds a watchpoint for the given kinds of accesses to the addresses from start to end (both bounds
+// inclusive), which is only hit if the given value (0-255) is accessed, -1 for any value. Breakpoints and
+// watchpoints share their IDs.
+func (d *Debugger) AddWatchpoint(start uint16, end uint16, access WatchAccess, value int) (Watchpoint, error) {
+	if end < start || access == 0 || access&^(WatchRead|WatchWrite|WatchExecute) != 0 || value < -1 || value > 0xFF {
+		return Watchpoint{}, ErrInvalidWatchpoint
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.nextID++
+	watchpoint := Watchpoint{ID: d.nextID, Start: start, End: end, Access: access, Value: value}
+	d.setWatchpoints(append(slices.Clone(d.Watchpoints()), watchpoint))
+	return watchpoint, nil
+}
+
+// RemoveWatchpoint removes the watchpoint with the given ID. It returns false if there is no such watchpoint.
+func (d *Debugger) RemoveWatchpoint(id int) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	watchpoints := d.Watchpoints()
+	index := slices.IndexFunc(watchpoints, func(w Watchpoint) bool { return w.ID == id })
+	if index < 0 {
+		return false
+	}
+	d.setWatchpoints(slices.Delete(slices.Clone(watchpoints), index, index+1))
+	return true
+}
+
+// Watchpoints returns all watchpoints in the order they were added. The result must not be modified.
+func (d *Debugger) Watchpoints() []Watchpoint {
+	if watchpoints := d.watchpoints.Load(); watchpoints != nil {
+		return *watchpoints
+	}
+	return nil
+}
+
+// setWatchpoints replaces the watchpoints, which are read by the goroutine ticking the core without locking
+func (d *Debugger) setWatchpoints(watchpoints []Watchpoint) {
+	d.watchpoints.Store(&watchpoints)
+}
+
+// watchpointHit returns the first watchpoint hit by the given access, if any
+func (d *Debugger) watchpointHit(address uint16, access WatchAccess, value byte) (Watchpoint, bool) {
+	for _, w := range d.Watchpoints() {
+		if w.Access&access != 0 && address >= w.Start && address <= w.End && (w.Value < 0 || w.Value == int(value)) {
+			return w, true
+		}
+	}
+	return Watchpoint{}, false
+}
+
+// ramBank returns the cartridge RAM bank mapped at the given address, -1 outside of the cartridge RAM
+func (d *Debugger) ramBank(address uint16) int {
+	cart := d.core.memory.GetGameCartridge()
+	if cart == nil || address < 0xA000 || address >= 0xC000 {
+		return -1
+	}
+	return cart.RAMBank(address - 0xA000)
+}
+
+func (w *memoryWatcher) Watched(address uint16, access memory.Access) bool {
+	d := (*Debugger)(w)
+	if d.detached.Load() {
+		return false
+	}
+
+	watchAccess := watchAccessOf(access)
+	for _, watchpoint := range d.Watchpoints() {
+		if watchpoint.Access&watchAccess != 0 && address >= watchpoint.Start && address <= watchpoint.End {
+			return true
+		}
+	}
+	return false
+}
+
+// Accessed records the first hit until the emulation is stopped at the next instruction
+func (w *memoryWatcher) Accessed(address uint16, access memory.Access, old byte, new byte) {
+	d := (*Debugger)(w)
+	if d.hit != nil {
+		return
+	}
+
+	watchAccess := watchAccessOf(access)
+	if watchpoint, ok := d.watchpointHit(address, watchAccess, new); ok {
+		d.hit = &WatchHit{
+			Watchpoint: watchpoint.ID,
+			Access:     watchAccess,
+			Address:    address,
+			Old:        old,
+			New:        new,
+			PC:         d.executingPC,
+			ROMBank:    d.romBank(d.executingPC),
+			RAMBank:    d.ramBank(address),
+		}
+	}
+}
+
+func watchAccessOf(access memory.Access) WatchAccess {
+	if access == memory.AccessWrite {
+		return WatchWrite
+	}
+	return WatchRead
+}
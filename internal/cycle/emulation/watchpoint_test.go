@@ -0,0 +1,103 @@
+package emulation
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDebugger_writeWatchpoint(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	rom[0x147] = 0x03 // MBC1+RAM+BATTERY
+	rom[0x149] = 0x03 // 32 KiB RAM
+	copy(rom[0x100:], []byte{
+		0x3E, 0x0A, // 0x0100: LD A, 0x0A
+		0xEA, 0x00, 0x00, // 0x0102: LD (0x0000), A: RAM on
+		0x3E, 0x42, // 0x0105: LD A, 0x42
+		0xEA, 0x23, 0xA1, // 0x0107: LD (0xA123), A
+		0x18, 0xFE, // 0x010A: JR -2
+	})
+
+	bootRom := testBootRom
+	core := New(&bootRom)
+	require.NoError(t, core.InsertCartridgeImage(rom, nil))
+	watchpoint, err := core.Debugger().AddWatchpoint(0xA000, 0xBFFF, WatchWrite, -1)
+	require.NoError(t, err)
+
+	// WHEN
+	stop := nextStop(t, runInBackground(t, core))
+
+	// THEN
+	assert.Equal(t, StopWatchpoint, stop.Reason)
+	assert.Equal(t, WatchHit{
+		Watchpoint: watchpoint.ID,
+		Access:     WatchWrite,
+		Address:    0xA123,
+		Old:        0x00,
+		New:        0x42,
+		PC:         0x0107,
+		ROMBank:    0,
+		RAMBank:    0,
+	}, stop.Watch)
+	assert.Equal(t, uint16(0x010A), stop.Registers.PC) // stopped after the instruction
+}
+
+func TestDebugger_readWatchpoint(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	debugger := core.Debugger()
+	_, err := debugger.AddWatchpoint(0xFFFC, 0xFFFC, WatchWrite, 0x09) // CALL pushes 0x08
+	require.NoError(t, err)
+	watchpoint, err := debugger.AddWatchpoint(0xFFFC, 0xFFFD, WatchRead, -1)
+	require.NoError(t, err)
+
+	// WHEN
+	stop := nextStop(t, runInBackground(t, core))
+
+	// THEN
+	assert.Equal(t, watchpoint.ID, stop.Watch.Watchpoint)
+	assert.Equal(t, WatchRead, stop.Watch.Access)
+	assert.Equal(t, uint16(0xFFFC), stop.Watch.Address)
+	assert.Equal(t, byte(0x08), stop.Watch.New)
+	assert.Equal(t, uint16(0x0152), stop.Watch.PC) // RET
+	assert.Equal(t, -1, stop.Watch.RAMBank)
+	assert.Equal(t, uint16(0x0108), stop.Registers.PC)
+}
+
+func TestDebugger_executeWatchpoint(t *testing.T) {
+	// GIVEN
+	core := newDebuggerTestCore(t)
+	debugger := core.Debugger()
+	watchpoint, err := debugger.AddWatchpoint(0x0150, 0x0152, WatchExecute, 0x04) // INC B
+	require.NoError(t, err)
+
+	// WHEN
+	stops := runInBackground(t, core)
+	first := nextStop(t, stops)
+	debugger.RemoveWatchpoint(watchpoint.ID)
+	debugger.Step()
+	second := nextStop(t, stops)
+
+	// THEN
+	assert.Equal(t, StopWatchpoint, first.Reason)
+	assert.Equal(t, uint16(0x0151), first.Registers.PC) // stopped before the instruction
+	assert.Equal(t, uint16(0x0151), first.Watch.PC)
+	assert.Equal(t, StopStep, second.Reason)
+	assert.Empty(t, debugger.Watchpoints())
+}
+
+func TestDebugger_AddWatchpoint_invalid(t *testing.T) {
+	// GIVEN
+	debugger := newDebuggerTestCore(t).Debugger()
+
+	// WHEN
+	_, errRange := debugger.AddWatchpoint(0xC001, 0xC000, WatchRead, -1)
+	_, errAccess := debugger.AddWatchpoint(0xC000, 0xC000, 0, -1)
+	_, errValue := debugger.AddWatchpoint(0xC000, 0xC000, WatchWrite, 0x100)
+
+	// THEN
+	assert.ErrorIs(t, errRange, ErrInvalidWatchpoint)
+	assert.ErrorIs(t, errAccess, ErrInvalidWatchpoint)
+	assert.ErrorIs(t, errValue, ErrInvalidWatchpoint)
+}
@@ -257,3 +257,38 @@ func (f *BackgroundFetcher) getTileForBackgroundOrWindow(identifier byte) tile {
 		return f.tileSet[256+signedIdentifier]
 	}
 }
+
+func (f *BackgroundFetcher) SaveState(s *util.StateWriter) {
+	// all slots of the queue are written, so the state always has the same size
+	s.Write(byte(f.pixelQueue.Size()))
+	for i := 0; i < f.pixelQueue.Capacity(); i++ {
+		p, _ := f.pixelQueue.Peek(i)
+		s.Write(p)
+	}
+
+	s.Write(f.currentTileNo, f.currentTile, f.currentRowOfTile, f.fetcherX, f.state, f.ticks, f.resetOnTileDataHigh,
+		f.dequeuedPixelCount, f.skippedPixel, f.bgPixelToSkip, f.drawingWindow, f.suspended, f.wyEqCurrentLine,
+		f.windowLineCount, f.scrollY, f.scrollX, f.windowY, f.windowX)
+}
+
+func (f *BackgroundFetcher) LoadState(s *util.StateReader) {
+	var size byte
+	s.Read(&size)
+	if int(size) > f.pixelQueue.Capacity() {
+		s.Fail(errInvalidPixelQueue)
+		return
+	}
+
+	f.pixelQueue.Clear()
+	for i := 0; i < f.pixelQueue.Capacity(); i++ {
+		var p byte
+		s.Read(&p)
+		if i < int(size) {
+			f.pixelQueue.Push(p)
+		}
+	}
+
+	s.Read(&f.currentTileNo, &f.currentTile, &f.currentRowOfTile, &f.fetcherX, &f.state, &f.ticks, &f.resetOnTileDataHigh,
+		&f.dequeuedPixelCount, &f.skippedPixel, &f.bgPixelToSkip, &f.drawingWindow, &f.suspended, &f.wyEqCurrentLine,
+		&f.windowLineCount, &f.scrollY, &f.scrollX, &f.windowY, &f.windowX)
+}
@@ -1,6 +1,9 @@
 package gpu
 
-import "fmt"
+import (
+	"fmt"
+	"gameboy-emulator/internal/util"
+)
 
 const (
 	ScreenXResolution byte = 160
@@ -15,6 +18,12 @@ type Display struct {
 	yPos        byte
 	xPos        byte
 	frameOutput func([ScreenYResolution][ScreenXResolution]byte)
+
+	// set on VBlank, cleared by FrameCompleted
+	frameCompleted bool
+
+	// called on VBlank, see SetVBlankHandler
+	vblankHandler func()
 }
 
 func NewDisplay() *Display {
@@ -32,6 +41,7 @@ func (d *Display) Reset() {
 	d.enabled = false
 	d.yPos = 0
 	d.xPos = 0
+	d.frameCompleted = false
 }
 
 func (d *Display) Enable() {
@@ -49,6 +59,11 @@ func (d *Display) Disable() {
 	go d.frameOutput(d.screen)
 }
 
+// GetScreen returns a copy of the current screen contents
+func (d *Display) GetScreen() [ScreenYResolution][ScreenXResolution]byte {
+	return d.screen
+}
+
 func (d *Display) IsEnabled() bool {
 	return d.enabled
 }
@@ -65,9 +80,26 @@ func (d *Display) HBlank() {
 
 func (d *Display) VBlank() {
 	d.yPos = 0
+	d.frameCompleted = true
+	if d.vblankHandler != nil {
+		d.vblankHandler()
+	}
 	go d.frameOutput(d.screen)
 }
 
+// SetVBlankHandler registers a handler which is called whenever VBlank is entered. Unlike the frame output handler,
+// it's called synchronously by the goroutine ticking the PPU.
+func (d *Display) SetVBlankHandler(handler func()) {
+	d.vblankHandler = handler
+}
+
+// FrameCompleted returns true if a frame was completed (VBlank was entered) since the last call.
+func (d *Display) FrameCompleted() bool {
+	completed := d.frameCompleted
+	d.frameCompleted = false
+	return completed
+}
+
 func (d *Display) RegisterFrameOutputHandler(handler func([ScreenYResolution][ScreenXResolution]byte)) {
 	d.frameOutput = handler
 	go d.frameOutput(d.screen)
@@ -84,3 +116,11 @@ func (d *Display) PrintFrame() {
 	fmt.Println()
 	fmt.Println()
 }
+
+func (d *Display) SaveState(s *util.StateWriter) {
+	s.Write(d.screen, d.enabled, d.yPos, d.xPos)
+}
+
+func (d *Display) LoadState(s *util.StateReader) {
+	s.Read(&d.screen, &d.enabled, &d.yPos, &d.xPos)
+}
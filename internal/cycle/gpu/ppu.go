@@ -58,6 +58,9 @@ func NewPPU(interruptSink interrupts.InterruptSink) *PPU {
 
 func (p *PPU) Reset() {
 
+	p.vram = [0x2000]byte{}
+	p.tileSet = [384]tile{}
+	p.control = 0
 	p.status = 0x80
 	p.currentLine = 0
 	p.currentLineCompare = 0
@@ -433,3 +436,25 @@ func (p *PPU) updateTileSet(address uint16) {
 		p.tileSet[tileIndex][rowIndex][colIndex] = (msb << 1) + lsb
 	}
 }
+
+// SaveState writes the complete state of the PPU including its fetchers and the display.
+// The tile set is not part of the state, as it is derived from VRAM.
+func (p *PPU) SaveState(s *util.StateWriter) {
+	s.Write(p.vram, p.control, p.status, p.currentLine, p.currentLineCompare, p.bgPalette, p.objPalettes,
+		p.state, p.ticks, p.xPos)
+	p.display.SaveState(s)
+	p.backgroundFetcher.SaveState(s)
+	p.spriteFetcher.SaveState(s)
+}
+
+func (p *PPU) LoadState(s *util.StateReader) {
+	s.Read(&p.vram, &p.control, &p.status, &p.currentLine, &p.currentLineCompare, &p.bgPalette, &p.objPalettes,
+		&p.state, &p.ticks, &p.xPos)
+	p.display.LoadState(s)
+	p.backgroundFetcher.LoadState(s)
+	p.spriteFetcher.LoadState(s)
+
+	for address := uint16(0); address < 0x1800; address += 2 {
+		p.updateTileSet(address)
+	}
+}
@@ -1,6 +1,7 @@
 package gpu
 
 import (
+	"errors"
 	"gameboy-emulator/internal/util"
 )
 
@@ -15,6 +16,11 @@ const (
 // current line
 const spriteBufferMaxSize = 10
 
+var (
+	errInvalidSpriteBuffer = errors.New("invalid sprite buffer size in state")
+	errInvalidPixelQueue   = errors.New("invalid pixel queue size in state")
+)
+
 type (
 	spriteFetcherState byte
 
@@ -277,3 +283,82 @@ func (f *SpriteFetcher) getTileNumber(s sprite) (tileNumber byte, tileY int) {
 func (p *SpritePixel) IsTransparent() bool {
 	return p.colorId == 0x0
 }
+
+func (f *SpriteFetcher) SaveState(s *util.StateWriter) {
+	// all slots of the queue and the sprite buffer are written, so the state always has the same size
+	s.Write(byte(f.pixelQueue.Size()))
+	for i := 0; i < f.pixelQueue.Capacity(); i++ {
+		p, _ := f.pixelQueue.Peek(i)
+		s.Write(p.colorId, p.paletteIndex, p.bgPriority)
+	}
+
+	f.currentSprite.saveState(s)
+	s.Write(f.currentTileRow, f.currentTileNo, f.tileY, f.oam)
+
+	// The sprite set is derived from OAM, but entries only exist after they have been written to
+	for _, sp := range f.spriteSet {
+		s.Write(sp != nil)
+	}
+
+	s.Write(byte(len(f.spriteBuffer)))
+	for _, sp := range f.spriteBuffer[:spriteBufferMaxSize] {
+		sp.saveState(s)
+	}
+
+	s.Write(f.ticks, f.state, f.idle, f.lastFetchXPos)
+}
+
+func (f *SpriteFetcher) LoadState(s *util.StateReader) {
+	var size byte
+	s.Read(&size)
+	if int(size) > f.pixelQueue.Capacity() {
+		s.Fail(errInvalidPixelQueue)
+		return
+	}
+
+	f.pixelQueue.Clear()
+	for i := 0; i < f.pixelQueue.Capacity(); i++ {
+		var p SpritePixel
+		s.Read(&p.colorId, &p.paletteIndex, &p.bgPriority)
+		if i < int(size) {
+			f.pixelQueue.Push(p)
+		}
+	}
+
+	f.currentSprite.loadState(s)
+	s.Read(&f.currentTileRow, &f.currentTileNo, &f.tileY, &f.oam)
+
+	for i := range f.spriteSet {
+		var present bool
+		s.Read(&present)
+
+		f.spriteSet[i] = nil
+		if present {
+			for address := uint16(i * 4); address < uint16(i*4+4); address++ {
+				f.updateSpriteSet(address)
+			}
+		}
+	}
+
+	s.Read(&size)
+	if size > spriteBufferMaxSize {
+		s.Fail(errInvalidSpriteBuffer)
+		return
+	}
+
+	f.spriteBuffer = make([]sprite, spriteBufferMaxSize)
+	for i := range f.spriteBuffer {
+		f.spriteBuffer[i].loadState(s)
+	}
+	f.spriteBuffer = f.spriteBuffer[:size]
+
+	s.Read(&f.ticks, &f.state, &f.idle, &f.lastFetchXPos)
+}
+
+func (sp *sprite) saveState(s *util.StateWriter) {
+	s.Write(sp.xPos, sp.yPos, sp.yFlip, sp.xFlip, sp.tileIndex, sp.paletteIndex, sp.bgPriority)
+}
+
+func (sp *sprite) loadState(s *util.StateReader) {
+	s.Read(&sp.xPos, &sp.yPos, &sp.yFlip, &sp.xFlip, &sp.tileIndex, &sp.paletteIndex, &sp.bgPriority)
+}
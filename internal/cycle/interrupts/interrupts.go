@@ -1,5 +1,7 @@
 package interrupts
 
+import "gameboy-emulator/internal/util"
+
 const (
 	VBlank InterruptType = 1 << iota
 	LcdStat
@@ -92,3 +94,11 @@ func (i *Interrupts) InterruptsPending() bool {
 func (i *Interrupts) MustHandleInterrupt() bool {
 	return i.master && i.InterruptsPending()
 }
+
+func (i *Interrupts) SaveState(s *util.StateWriter) {
+	s.Write(i.master, i.enable, i.flags)
+}
+
+func (i *Interrupts) LoadState(s *util.StateReader) {
+	s.Read(&i.master, &i.enable, &i.flags)
+}
@@ -69,3 +69,23 @@ func (j *Joypad) KeyPressed(index byte) {
 func (j *Joypad) KeyReleased(index byte) {
 	util.SetBit(&j.state, index)
 }
+
+// SetState applies the state of all keys at once. A cleared bit means the key is pressed, bits are ordered
+// like the indexes of KeyPressed.
+func (j *Joypad) SetState(state byte) {
+	for index := byte(0); index < 8; index++ {
+		if util.BitIsSet8(state, index) {
+			j.KeyReleased(index)
+		} else {
+			j.KeyPressed(index)
+		}
+	}
+}
+
+func (j *Joypad) SaveState(s *util.StateWriter) {
+	s.Write(j.state, j.control)
+}
+
+func (j *Joypad) LoadState(s *util.StateReader) {
+	s.Read(&j.state, &j.control)
+}
@@ -3,11 +3,13 @@ package memory
 import (
 	"fmt"
 	"gameboy-emulator/internal/cartridge"
+	"gameboy-emulator/internal/cheat"
 	"gameboy-emulator/internal/cycle/apu"
 	"gameboy-emulator/internal/cycle/gpu"
 	"gameboy-emulator/internal/cycle/interrupts"
 	"gameboy-emulator/internal/cycle/joypad"
 	"gameboy-emulator/internal/cycle/timer"
+	"gameboy-emulator/internal/util"
 	log "go.uber.org/zap"
 )
 
@@ -49,10 +51,18 @@ type (
 		dmaTransferInProgress     bool
 		dmaTransferCount          int
 		ticks                     int
-		pendingWrite              func(m *Memory)
+		pendingWrite              *pendingWrite
 
 		bootFlag byte // Set to non-zero to disable boot ROM
 
+		serialOutput func(data byte) // receives every byte sent over the link cable
+
+		cheats   *cheat.List
+		lastLine byte // LY during the last tick, for detecting the start of VBlank
+
+		watcher Watcher // nil if memory accesses aren't watched
+		stubLY  bool    // LY reads 0x90 instead of the current line
+
 		interrupts *interrupts.Interrupts
 		ppu        *gpu.PPU
 		cartridge  cartridge.Cartridge
@@ -65,6 +75,30 @@ type (
 		write func(data byte)
 		read  func() byte
 	}
+
+	pendingWrite struct {
+		address uint16
+		data    byte
+	}
+
+	// Access to the memory by the CPU, see Watcher
+	Access byte
+
+	// Watcher is notified of the reads and writes of the CPU, e.g. by the watchpoints of a debugger. Accesses of
+	// DMA transfers and cheats aren't reported.
+	Watcher interface {
+		// Watched returns true if the given kind of access to the given address has to be reported
+		Watched(address uint16, access Access) bool
+
+		// Accessed reports an access to a watched address. For reads, old and new are both the value read. It's
+		// called in the middle of a T-cycle, so it must not block.
+		Accessed(address uint16, access Access, old byte, new byte)
+	}
+)
+
+const (
+	AccessRead Access = iota
+	AccessWrite
 )
 
 func New(
@@ -79,6 +113,9 @@ func New(
 		interrupts: interrupts,
 		ppu:        ppu,
 		bootRom:    *bootRom,
+		serialOutput: func(data byte) {
+			fmt.Print(string(data))
+		},
 	}
 	m.initializeIOAddressSpace(
 		timer,
@@ -107,6 +144,7 @@ func (mem *Memory) Reset() {
 	mem.dmaTransferCount = 0
 	mem.ticks = 0
 	mem.pendingWrite = nil
+	mem.lastLine = 0
 }
 
 func (mem *Memory) InsertGameCartridge(cart cartridge.Cartridge) {
@@ -117,13 +155,44 @@ func (mem *Memory) GetGameCartridge() cartridge.Cartridge {
 	return mem.cartridge
 }
 
+// SetCheats activates the given cheats, nil deactivates all cheats. Game Genie codes patch reads from the
+// cartridge ROM, GameShark codes are written to RAM at the start of each VBlank.
+func (mem *Memory) SetCheats(cheats *cheat.List) {
+	mem.cheats = cheats
+}
+
+// SetWatcher registers the watcher which is notified of the reads and writes of the CPU, nil removes it.
+func (mem *Memory) SetWatcher(watcher Watcher) {
+	mem.watcher = watcher
+}
+
+// SetStubLY makes LY read 0x90, the first line of VBlank, instead of the current line. Reference logs of CPU
+// traces are made this way, so they don't depend on the timing of the PPU.
+func (mem *Memory) SetStubLY(stub bool) {
+	mem.stubLY = stub
+}
+
+// SetSerialOutputHandler registers a handler receiving every byte sent over the link cable.
+// By default, the bytes are printed to stdout.
+func (mem *Memory) SetSerialOutputHandler(handler func(data byte)) {
+	mem.serialOutput = handler
+}
+
 func (mem *Memory) Tick() {
+	if mem.cartridgePresent() {
+		mem.cartridge.Tick()
+	}
+
+	if line := mem.ppu.GetCurrentLine(); line != mem.lastLine {
+		mem.lastLine = line
+		if line == gpu.ScreenYResolution && mem.cheats != nil {
+			mem.applyCheatWrites()
+		}
+	}
+
 	// If there is a write access pending, execute it after this method
 	if mem.pendingWrite != nil {
-		defer func() {
-			mem.pendingWrite(mem)
-			mem.pendingWrite = nil
-		}()
+		defer mem.executePendingWrite()
 	}
 
 	if !mem.dmaTransferInProgress && !mem.dmaTransferRequested {
@@ -159,10 +228,34 @@ func (mem *Memory) Write(address uint16, data byte) {
 	// due to timing issues, we can't allow a write request to take effect
 	// as soon as it is done. Only when the memory is live (during Tick()) the write access
 	// may be executed
-	mem.pendingWrite = func(m *Memory) { m.internalWrite(address, data) }
+	mem.pendingWrite = &pendingWrite{address: address, data: data}
+}
+
+// executePendingWrite executes the write requested by the CPU during the current T-cycle
+func (mem *Memory) executePendingWrite() {
+	address, data := mem.pendingWrite.address, mem.pendingWrite.data
+	mem.pendingWrite = nil
+
+	if mem.watcher == nil || !mem.watcher.Watched(address, AccessWrite) {
+		mem.internalWrite(address, data)
+		return
+	}
+
+	old := mem.internalRead(address)
+	mem.internalWrite(address, data)
+	mem.watcher.Accessed(address, AccessWrite, old, data)
 }
 
 func (mem *Memory) Read(address uint16) byte {
+	data := mem.Peek(address)
+	if mem.watcher != nil && mem.watcher.Watched(address, AccessRead) {
+		mem.watcher.Accessed(address, AccessRead, data, data)
+	}
+	return data
+}
+
+// Peek reads like Read without notifying the watcher, e.g. for inspecting the memory in a debugger
+func (mem *Memory) Peek(address uint16) byte {
 	if mem.dmaTransferInProgress &&
 		((address >= 0xFE00 && address < 0xFEA0) ||
 			(address >= mem.dmaSourceAddress && address < mem.dmaSourceAddress+uint16(gpu.OAMSize))) {
@@ -221,11 +314,13 @@ func (mem *Memory) internalRead(address uint16) byte {
 
 		// if game cartridge is inserted, read from game cartridge otherwise return 0xFF
 		// Source: https://gbdev.io/pandocs/Power_Up_Sequence.html#monochrome-models-dmg0-dmg-mgb
-		if mem.cartridgePresent() {
-			return mem.cartridge.ReadROM(address)
-		} else {
+		if !mem.cartridgePresent() {
 			return 0xFF
 		}
+		if mem.cheats != nil {
+			return mem.cheats.PatchROM(address, mem.cartridge.ReadROM(address))
+		}
+		return mem.cartridge.ReadROM(address)
 
 	case address < 0xA000: // VRAM
 		return mem.ppu.ReadVRam(address - 0x8000)
@@ -265,6 +360,13 @@ func (mem *Memory) internalRead(address uint16) byte {
 	return 0x00
 }
 
+// applyCheatWrites writes the values of the enabled GameShark codes like the GameShark does in its VBlank handler
+func (mem *Memory) applyCheatWrites() {
+	for _, code := range mem.cheats.RAMWrites() {
+		mem.internalWrite(code.Address, code.Value)
+	}
+}
+
 func (mem *Memory) handleIOWrite(address uint16, data byte) {
 	ioLogger := log.L().With(log.String("address", fmt.Sprintf("0x%04X", address)))
 
@@ -293,6 +395,13 @@ func (mem *Memory) handleIORead(address uint16) byte {
 	return register.read()
 }
 
+func (mem *Memory) readLY() byte {
+	if mem.stubLY {
+		return 0x90
+	}
+	return mem.ppu.GetCurrentLine()
+}
+
 func (mem *Memory) cartridgePresent() bool {
 	return mem.cartridge != nil
 }
@@ -301,6 +410,11 @@ func (mem *Memory) bootRomMapped() bool {
 	return mem.bootFlag == 0x00
 }
 
+// BootROMMapped returns true until the boot ROM is unmapped by writing to 0xFF50
+func (mem *Memory) BootROMMapped() bool {
+	return mem.bootRomMapped()
+}
+
 // requestDMATransfer executes a DMA transfer from ROM or RAM to OAM.
 // The given value specifies the transfer source address divided by 0x100.
 func (mem *Memory) requestDMATransfer(value byte) {
@@ -333,7 +447,7 @@ func (mem *Memory) readSc() byte {
 
 func (mem *Memory) writeSc(data byte) {
 	if data == 0x81 {
-		fmt.Print(string(mem.sb))
+		mem.serialOutput(mem.sb)
 	}
 	mem.sc = data | 0x7E
 }
@@ -415,7 +529,7 @@ func (mem *Memory) initializeIOAddressSpace(
 	mem.io[0x41] = ioRegister{"STAT", ppu.SetStatus, ppu.GetStatus}
 	mem.io[0x42] = ioRegister{"SCY", ppu.SetScrollY, ppu.GetScrollY}
 	mem.io[0x43] = ioRegister{"SCX", ppu.SetScrollX, ppu.GetScrollX}
-	mem.io[0x44] = ioRegister{"LY", func(_ byte) { /* ignore write */ }, ppu.GetCurrentLine}
+	mem.io[0x44] = ioRegister{"LY", func(_ byte) { /* ignore write */ }, mem.readLY}
 	mem.io[0x45] = ioRegister{"LYC", ppu.SetCurrentLineCompare, ppu.GetCurrentLineCompare}
 	mem.io[0x46] = ioRegister{"DMA", mem.requestDMATransfer, mem.getDMAData}
 	mem.io[0x47] = ioRegister{"BGP", ppu.SetBackgroundPalette, ppu.GetBackgroundPalette}
@@ -427,3 +541,29 @@ func (mem *Memory) initializeIOAddressSpace(
 	// Boot flag control
 	mem.io[0x50] = ioRegister{"BOOT", mem.setBootFlag, func() byte { return 0xFF }}
 }
+
+// SaveState writes the internal memory and the state of DMA transfers. The boot ROM is not part of
+// the state, as it is provided when the memory is created.
+func (mem *Memory) SaveState(s *util.StateWriter) {
+	s.Write(mem.wram, mem.hram, mem.sb, mem.sc, mem.dmaSourceAddress, mem.dmaRequestedSourceAddress,
+		mem.dmaTransferRequested, mem.dmaTransferInProgress, mem.dmaTransferCount, mem.ticks, mem.bootFlag)
+
+	s.Write(mem.pendingWrite != nil)
+	if mem.pendingWrite != nil {
+		s.Write(mem.pendingWrite.address, mem.pendingWrite.data)
+	}
+}
+
+func (mem *Memory) LoadState(s *util.StateReader) {
+	s.Read(&mem.wram, &mem.hram, &mem.sb, &mem.sc, &mem.dmaSourceAddress, &mem.dmaRequestedSourceAddress,
+		&mem.dmaTransferRequested, &mem.dmaTransferInProgress, &mem.dmaTransferCount, &mem.ticks, &mem.bootFlag)
+
+	var writePending bool
+	s.Read(&writePending)
+
+	mem.pendingWrite = nil
+	if writePending {
+		mem.pendingWrite = &pendingWrite{}
+		s.Read(&mem.pendingWrite.address, &mem.pendingWrite.data)
+	}
+}
@@ -159,3 +159,11 @@ func (t *Timer) timerTick() {
 		t.tima++
 	}
 }
+
+func (t *Timer) SaveState(s *util.StateWriter) {
+	s.Write(t.tima, t.tma, t.tac, t.systemCounter, t.high, t.selectedDIVBit, t.timerEnabled, t.timaReloadDelay, t.timaReloading)
+}
+
+func (t *Timer) LoadState(s *util.StateReader) {
+	s.Read(&t.tima, &t.tma, &t.tac, &t.systemCounter, &t.high, &t.selectedDIVBit, &t.timerEnabled, &t.timaReloadDelay, &t.timaReloading)
+}
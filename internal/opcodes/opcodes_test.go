@@ -0,0 +1,33 @@
+package opcodes
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	assert.Equal(t, "CALL a16", Template(0xCD))
+	assert.Equal(t, "LD A, ($FF00+C)", Template(0xF2))
+	assert.Empty(t, Template(0xFC))
+	assert.Equal(t, "SWAP A", ExtendedTemplate(0x37))
+	assert.Equal(t, "BIT 7, H", ExtendedTemplate(0x7C))
+	assert.Equal(t, "SET 0, B", ExtendedTemplate(0xC0))
+}
+
+func TestLength(t *testing.T) {
+	for opCode, expected := range map[byte]int{
+		0x00: 1, // NOP
+		0x10: 2, // STOP and its padding byte
+		0x18: 2, // JR e8
+		0x3E: 2, // LD A, n8
+		0xCB: 2, // PREFIX CB
+		0xCD: 3, // CALL a16
+		0xE0: 2, // LDH (a8), A
+		0xE2: 1, // LD ($FF00+C), A
+		0xF8: 2, // LD HL, SP+s8
+		0xFA: 3, // LD A, (a16)
+		0xDD: 1, // undefined
+	} {
+		assert.Equal(t, expected, Length(opCode), "opcode 0x%02X", opCode)
+	}
+}
@@ -56,8 +56,13 @@ func (e *Emulator) SetScreenHandler(handler func([144][160]byte)) {
 	e.gpu.SetScreenHandler(handler)
 }
 
-func (e *Emulator) InsertCartridge(pathToCartridgeImage string) {
-	e.memory.InsertGameCartridge(cartridge.LoadCartridgeImage(pathToCartridgeImage))
+func (e *Emulator) InsertCartridge(pathToCartridgeImage string) error {
+	cart, err := cartridge.LoadCartridgeImage(pathToCartridgeImage)
+	if err != nil {
+		return err
+	}
+	e.memory.InsertGameCartridge(cart)
+	return nil
 }
 
 func (e *Emulator) Run() {
@@ -76,6 +81,7 @@ func (e *Emulator) Run() {
 			stepCycles := e.cpu.Step()
 			e.timer.UpdateTimer(stepCycles)
 			e.gpu.UpdateDisplay(stepCycles)
+			e.memory.UpdateCartridge(stepCycles)
 			e.interrupts.HandleInterrupt()
 			time.Sleep(time.Duration(1000))
 		}
@@ -116,6 +116,16 @@ func (mem *Memory) GetGameCartridge() cartridge.Cartridge {
 	return mem.cartridge
 }
 
+// UpdateCartridge advances the hardware of the inserted cartridge by the given number of cycles
+func (mem *Memory) UpdateCartridge(stepCycles int) {
+	if !mem.cartridgePresent() {
+		return
+	}
+	for i := 0; i < stepCycles; i++ {
+		mem.cartridge.Tick()
+	}
+}
+
 func (mem *Memory) initializeIOAddressSpace(
 	timer *timer.Timer,
 	gpu *gpu.GPU,
@@ -54,6 +54,11 @@ func (q *Queue[T]) Set(index int, item T) error {
 	return nil
 }
 
+// Capacity returns the maximum number of items the Queue can hold.
+func (q *Queue[T]) Capacity() int {
+	return len(q.fifo)
+}
+
 // Size returns the current number of items in the Queue.
 func (q *Queue[T]) Size() int {
 	return q.len
@@ -0,0 +1,114 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var errStateCorrupted = errors.New("state data corrupted")
+
+// StateWriter serializes the state of emulator components into a binary stream.
+// The first error which occurs is kept and all following writes are skipped, so
+// components don't have to check for errors after every single value.
+type StateWriter struct {
+	w   io.Writer
+	err error
+}
+
+// StateReader is the counterpart of StateWriter and restores values in exactly the
+// same order they were written.
+type StateReader struct {
+	r   io.Reader
+	err error
+}
+
+func NewStateWriter(w io.Writer) *StateWriter {
+	return &StateWriter{w: w}
+}
+
+// Write writes the given values in little endian byte order. Values must either be of fixed size
+// (see encoding/binary) or of type int or uint, which are always written as 64bit values.
+func (s *StateWriter) Write(values ...any) {
+	for _, v := range values {
+		if s.err != nil {
+			return
+		}
+
+		switch value := v.(type) {
+		case int:
+			s.err = binary.Write(s.w, binary.LittleEndian, int64(value))
+		case uint:
+			s.err = binary.Write(s.w, binary.LittleEndian, uint64(value))
+		default:
+			s.err = binary.Write(s.w, binary.LittleEndian, value)
+		}
+	}
+}
+
+// WriteBytes writes a byte slice of variable length prefixed with its length.
+func (s *StateWriter) WriteBytes(data []byte) {
+	s.Write(uint32(len(data)), data)
+}
+
+// Err returns the first error which occurred while writing.
+func (s *StateWriter) Err() error {
+	return s.err
+}
+
+func NewStateReader(r io.Reader) *StateReader {
+	return &StateReader{r: r}
+}
+
+// Read reads values into the given pointers. See StateWriter.Write for the supported types.
+func (s *StateReader) Read(pointers ...any) {
+	for _, p := range pointers {
+		if s.err != nil {
+			return
+		}
+
+		switch pointer := p.(type) {
+		case *int:
+			var value int64
+			s.err = binary.Read(s.r, binary.LittleEndian, &value)
+			*pointer = int(value)
+		case *uint:
+			var value uint64
+			s.err = binary.Read(s.r, binary.LittleEndian, &value)
+			*pointer = uint(value)
+		default:
+			s.err = binary.Read(s.r, binary.LittleEndian, pointer)
+		}
+	}
+}
+
+// ReadBytes reads a byte slice which was written by StateWriter.WriteBytes. The length
+// must not exceed maxLength to protect against corrupted data.
+func (s *StateReader) ReadBytes(maxLength int) []byte {
+	var length uint32
+	s.Read(&length)
+	if s.err != nil {
+		return nil
+	}
+
+	if int(length) > maxLength {
+		s.err = errStateCorrupted
+		return nil
+	}
+
+	data := make([]byte, length)
+	s.Read(data)
+	return data
+}
+
+// Err returns the first error which occurred while reading.
+func (s *StateReader) Err() error {
+	return s.err
+}
+
+// Fail records an error detected by the caller, e.g. an invalid value, unless an error was already recorded.
+func (s *StateReader) Fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
@@ -0,0 +1,161 @@
+// Package disasm decodes Game Boy (SM83) machine code into instructions in the notation of the RGBDS assembler, with
+// parentheses for memory operands. Addresses are replaced by labels from symbol files (see LoadSymbols).
+package disasm
+
+import (
+	"errors"
+	"fmt"
+	"gameboy-emulator/internal/opcodes"
+	"strings"
+)
+
+var (
+	ErrTruncated    = errors.New("instruction is truncated")
+	ErrInvalidRange = errors.New("address range outside of the ROM bank")
+)
+
+// Instruction decoded from machine code
+type Instruction struct {
+	Address uint16
+	Bytes   []byte // opcode and operands
+	Text    string // disassembly with resolved operands, e.g. "CALL Main" or "LD A, $12"
+
+	// Target is the destination of jumps, calls and RST with a constant destination, -1 for other instructions
+	Target int
+}
+
+// Length returns the number of bytes of the instruction
+func (i Instruction) Length() int {
+	return len(i.Bytes)
+}
+
+// Template returns the disassembly of the instruction with the given opcode, with placeholders for its operands,
+// e.g. "LD A, n8". It's empty for undefined opcodes and "PREFIX CB" for the prefix of CB instructions.
+//
+// Placeholders of operands:
+//
+//	n8   8-bit immediate value
+//	n16  16-bit immediate value
+//	a8   address in the high page (0xFF00 + 8-bit operand)
+//	a16  16-bit address
+//	e8   destination of a relative jump (signed 8-bit offset)
+//	s8   signed 8-bit offset added to SP
+func Template(opCode byte) string {
+	return opcodes.Template(opCode)
+}
+
+// ExtendedTemplate returns the disassembly of the CB prefixed instruction with the given opcode, e.g. "BIT 7, H"
+func ExtendedTemplate(opCode byte) string {
+	return opcodes.ExtendedTemplate(opCode)
+}
+
+// Length returns the number of bytes of the instruction with the given opcode, including its operands. Undefined
+// opcodes have a length of 1.
+func Length(opCode byte) int {
+	return opcodes.Length(opCode)
+}
+
+// Decode decodes the instruction at the start of code, which is located at the given address in the given ROM bank
+// (-1 if unknown). Addresses are replaced by the labels of the given symbols, which may be nil. Undefined opcodes
+// are decoded as data ("DB $D3").
+func Decode(code []byte, bank int, address uint16, symbols *Symbols) (Instruction, error) {
+	if len(code) == 0 {
+		return Instruction{}, ErrTruncated
+	}
+
+	opCode := code[0]
+	length := Length(opCode)
+	if len(code) < length {
+		return Instruction{}, ErrTruncated
+	}
+
+	if address < 0x4000 {
+		bank = -1 // the ROM bank switched in at 0x4000-0x7FFF is unknown to code in bank 0
+	}
+
+	instruction := Instruction{Address: address, Bytes: code[:length], Target: -1}
+	switch template := opcodes.Template(opCode); {
+	case template == "":
+		instruction.Text = fmt.Sprintf("DB $%02X", opCode)
+	case opCode == 0xCB:
+		instruction.Text = ExtendedTemplate(code[1])
+	case opCode&0xC7 == 0xC7: // RST
+		instruction.Text = template
+		instruction.Target = int(opCode & 0x38)
+	default:
+		instruction.Text, instruction.Target = resolve(template, code[1:length], bank, address, symbols)
+	}
+	return instruction, nil
+}
+
+// resolve replaces the placeholder in the given template by the operand. It returns the destination of jumps and
+// calls, -1 for other instructions.
+func resolve(template string, operand []byte, bank int, address uint16, symbols *Symbols) (string, int) {
+	var value uint16
+	if len(operand) == 2 {
+		value = uint16(operand[1])<<8 | uint16(operand[0])
+	} else if len(operand) == 1 {
+		value = uint16(operand[0])
+	}
+
+	// relative jumps get their target below, "JP HL" has no constant one
+	target := -1
+	if (strings.HasPrefix(template, "JP") || strings.HasPrefix(template, "CALL")) && strings.Contains(template, "a16") {
+		target = int(value)
+	}
+
+	switch {
+	case strings.Contains(template, "+s8"):
+		return strings.Replace(template, "+s8", fmt.Sprintf("%+d", int8(value)), 1), target
+	case strings.Contains(template, "s8"):
+		return strings.Replace(template, "s8", fmt.Sprintf("%d", int8(value)), 1), target
+	case strings.Contains(template, "n16"):
+		return strings.Replace(template, "n16", fmt.Sprintf("$%04X", value), 1), target
+	case strings.Contains(template, "n8"):
+		return strings.Replace(template, "n8", fmt.Sprintf("$%02X", value), 1), target
+	case strings.Contains(template, "a16"):
+		return strings.Replace(template, "a16", symbols.label(bank, value), 1), target
+	case strings.Contains(template, "a8"):
+		return strings.Replace(template, "a8", symbols.label(-1, 0xFF00|value), 1), target
+	case strings.Contains(template, "e8"):
+		target = int(address + 2 + uint16(int8(value)))
+		return strings.Replace(template, "e8", symbols.label(bank, uint16(target)), 1), target
+	}
+	return template, target
+}
+
+// DisassembleROM decodes the instructions of the given ROM bank from start to end (both inclusive). Bank 0 is
+// addressed from 0x0000 to 0x3FFF, all other banks from 0x4000 to 0x7FFF like the CPU sees them. Instructions
+// crossing the end of the bank are decoded as data.
+func DisassembleROM(rom []byte, bank int, start uint16, end uint16, symbols *Symbols) ([]Instruction, error) {
+	window := uint16(0x4000)
+	if bank == 0 {
+		window = 0x0000
+	}
+	bankStart := bank * 0x4000
+	if bank < 0 || bankStart >= len(rom) || start > end || start < window || end > window+0x3FFF {
+		return nil, ErrInvalidRange
+	}
+
+	code := rom[bankStart:min(bankStart+0x4000, len(rom))]
+	var instructions []Instruction
+	for address := int(start); address <= int(end); {
+		offset := address - int(window)
+		if offset >= len(code) {
+			break
+		}
+
+		instruction, err := Decode(code[offset:], bank, uint16(address), symbols)
+		if err != nil {
+			instruction = Instruction{
+				Address: uint16(address),
+				Bytes:   code[offset : offset+1],
+				Text:    fmt.Sprintf("DB $%02X", code[offset]),
+				Target:  -1,
+			}
+		}
+		instructions = append(instructions, instruction)
+		address += instruction.Length()
+	}
+	return instructions, nil
+}
@@ -0,0 +1,131 @@
+package disasm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	for _, test := range []struct {
+		code     []byte
+		expected string
+		target   int
+	}{
+		{[]byte{0x00}, "NOP", -1},
+		{[]byte{0x3E, 0x12}, "LD A, $12", -1},
+		{[]byte{0x31, 0xFE, 0xFF}, "LD SP, $FFFE", -1},
+		{[]byte{0xEA, 0x00, 0xA0}, "LD ($A000), A", -1},
+		{[]byte{0xE0, 0x40}, "LDH ($FF40), A", -1},
+		{[]byte{0xCD, 0x50, 0x01}, "CALL $0150", 0x0150},
+		{[]byte{0xC2, 0x00, 0x40}, "JP NZ, $4000", 0x4000},
+		{[]byte{0xE9}, "JP HL", -1},
+		{[]byte{0x18, 0xFE}, "JR $0200", 0x0200},
+		{[]byte{0x38, 0x10}, "JR C, $0212", 0x0212},
+		{[]byte{0xE8, 0xFE}, "ADD SP, -2", -1},
+		{[]byte{0xF8, 0x05}, "LD HL, SP+5", -1},
+		{[]byte{0xFF}, "RST $38", 0x38},
+		{[]byte{0xCB, 0x37}, "SWAP A", -1},
+		{[]byte{0xCB, 0x7E}, "BIT 7, (HL)", -1},
+		{[]byte{0xCB, 0xC1}, "SET 0, C", -1},
+		{[]byte{0x10, 0x00}, "STOP", -1},
+		{[]byte{0xD3}, "DB $D3", -1},
+	} {
+		// WHEN
+		instruction, err := Decode(test.code, 0, 0x0200, nil)
+
+		// THEN
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, instruction.Text)
+		assert.Equal(t, test.code, instruction.Bytes, test.expected)
+		assert.Equal(t, len(test.code), instruction.Length(), test.expected)
+		assert.Equal(t, test.target, instruction.Target, test.expected)
+	}
+}
+
+func TestDecode_jumpToHL(t *testing.T) {
+	// WHEN
+	instruction, err := Decode([]byte{0xE9}, 1, 0x4000, nil)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, "JP HL", instruction.Text)
+	assert.Equal(t, -1, instruction.Target)
+}
+
+func TestDecode_truncated(t *testing.T) {
+	for _, code := range [][]byte{{}, {0xCD, 0x50}, {0xCB}} {
+		// WHEN
+		_, err := Decode(code, 0, 0x0200, nil)
+
+		// THEN
+		assert.ErrorIs(t, err, ErrTruncated)
+	}
+}
+
+func TestDecode_labels(t *testing.T) {
+	// GIVEN
+	symbols := &Symbols{}
+	symbols.Add(Symbol{Bank: 1, Address: 0x4000, Name: "BankOne"})
+	symbols.Add(Symbol{Bank: 2, Address: 0x4000, Name: "BankTwo"})
+	symbols.Add(Symbol{Bank: 0, Address: 0x0150, Name: "Main"})
+	symbols.Add(Symbol{Bank: 0, Address: 0xFF40, Name: "rLCDC"})
+
+	// WHEN
+	call, _ := Decode([]byte{0xCD, 0x50, 0x01}, 2, 0x4800, symbols)
+	jump, _ := Decode([]byte{0xC3, 0x00, 0x40}, 2, 0x4800, symbols)
+	relative, _ := Decode([]byte{0x18, 0xFE}, 1, 0x4000, symbols)
+	high, _ := Decode([]byte{0xF0, 0x40}, 2, 0x4800, symbols)
+
+	// THEN
+	assert.Equal(t, "CALL Main", call.Text)
+	assert.Equal(t, "JP BankTwo", jump.Text)
+	assert.Equal(t, "JR BankOne", relative.Text)
+	assert.Equal(t, "LDH A, (rLCDC)", high.Text)
+}
+
+func TestTemplate(t *testing.T) {
+	assert.Equal(t, "LD A, n8", Template(0x3E))
+	assert.Equal(t, "PREFIX CB", Template(0xCB))
+	assert.Empty(t, Template(0xDD))
+	assert.Equal(t, "RR (HL)", ExtendedTemplate(0x1E))
+	assert.Equal(t, "RES 3, A", ExtendedTemplate(0x9F))
+}
+
+func TestDisassembleROM(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+	copy(rom[0x4000:], []byte{0x3E, 0x01, 0xC9})
+	rom[0x7FFF] = 0xCD
+
+	// WHEN
+	instructions, err := DisassembleROM(rom, 1, 0x4000, 0x4002, nil)
+	last, errLast := DisassembleROM(rom, 1, 0x7FFF, 0x7FFF, nil)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, instructions, 2)
+	assert.Equal(t, uint16(0x4000), instructions[0].Address)
+	assert.Equal(t, "LD A, $01", instructions[0].Text)
+	assert.Equal(t, uint16(0x4002), instructions[1].Address)
+	assert.Equal(t, "RET", instructions[1].Text)
+
+	require.NoError(t, errLast)
+	require.Len(t, last, 1)
+	assert.Equal(t, "DB $CD", last[0].Text)
+}
+
+func TestDisassembleROM_invalidRange(t *testing.T) {
+	// GIVEN
+	rom := make([]byte, 0x8000)
+
+	// WHEN
+	_, errBank0 := DisassembleROM(rom, 0, 0x3FFF, 0x4000, nil)
+	_, errBank1 := DisassembleROM(rom, 1, 0x0100, 0x0200, nil)
+	_, errMissing := DisassembleROM(rom, 2, 0x4000, 0x4100, nil)
+
+	// THEN
+	assert.ErrorIs(t, errBank0, ErrInvalidRange)
+	assert.ErrorIs(t, errBank1, ErrInvalidRange)
+	assert.ErrorIs(t, errMissing, ErrInvalidRange)
+}
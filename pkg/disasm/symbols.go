@@ -0,0 +1,141 @@
+package disasm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var ErrMalformedSymbols = errors.New("malformed symbol file")
+
+type (
+	// Symbol is a label of an address. Bank is the ROM bank (or RAM bank for addresses of the cartridge RAM), -1 if
+	// the symbol file doesn't give one.
+	Symbol struct {
+		Bank    int
+		Address uint16
+		Name    string
+	}
+
+	// Symbols are the labels of a symbol file
+	Symbols struct {
+		banked    map[symbolKey]string
+		byAddress map[uint16]string // first symbol of each address, regardless of the bank
+	}
+
+	symbolKey struct {
+		bank    int
+		address uint16
+	}
+)
+
+// LoadSymbols reads the symbol file at the given path (see ParseSymbols)
+func LoadSymbols(path string) (*Symbols, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseSymbols(file)
+}
+
+// ParseSymbols parses a symbol file as written by RGBDS (rgblink -n) and read by BGB, one "bank:address name" per
+// line with hexadecimal bank and address, e.g. "01:4000 Main.loop". Lines without bank ("4000 Main") are accepted.
+// Comments start with ";".
+func ParseSymbols(r io.Reader) (*Symbols, error) {
+	symbols := &Symbols{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), ";")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+
+		symbol, err := parseSymbol(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrMalformedSymbols, line, err)
+		}
+		symbols.Add(symbol)
+	}
+	return symbols, scanner.Err()
+}
+
+func parseSymbol(fields []string) (Symbol, error) {
+	if len(fields) != 2 {
+		return Symbol{}, fmt.Errorf("expected address and name, got %q", strings.Join(fields, " "))
+	}
+
+	symbol := Symbol{Bank: -1, Name: fields[1]}
+	address := fields[0]
+	if bank, rest, found := strings.Cut(fields[0], ":"); found {
+		parsed, err := strconv.ParseUint(bank, 16, 16)
+		if err != nil {
+			return Symbol{}, err
+		}
+		symbol.Bank = int(parsed)
+		address = rest
+	}
+
+	parsed, err := strconv.ParseUint(address, 16, 16)
+	if err != nil {
+		return Symbol{}, err
+	}
+	symbol.Address = uint16(parsed)
+	return symbol, nil
+}
+
+// Add adds a symbol. The first symbol added for an address wins.
+func (s *Symbols) Add(symbol Symbol) {
+	if s.banked == nil {
+		s.banked = make(map[symbolKey]string)
+		s.byAddress = make(map[uint16]string)
+	}
+
+	key := symbolKey{symbol.Bank, symbol.Address}
+	if _, ok := s.banked[key]; !ok {
+		s.banked[key] = symbol.Name
+	}
+	if _, ok := s.byAddress[symbol.Address]; !ok {
+		s.byAddress[symbol.Address] = symbol.Name
+	}
+}
+
+// Lookup returns the name of the symbol at the given address in the given bank. Symbols of other banks only match
+// if the bank is unknown (-1) or the address isn't in a switchable region (0x4000-0x7FFF and 0xA000-0xBFFF).
+func (s *Symbols) Lookup(bank int, address uint16) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	if name, ok := s.banked[symbolKey{bank, address}]; ok {
+		return name, true
+	}
+	if name, ok := s.banked[symbolKey{-1, address}]; ok {
+		return name, true
+	}
+
+	switchable := address >= 0x4000 && address < 0x8000 || address >= 0xA000 && address < 0xC000
+	if bank < 0 || !switchable {
+		name, ok := s.byAddress[address]
+		return name, ok
+	}
+	return "", false
+}
+
+// label returns the name of the symbol at the given address, or the address in hexadecimal if there is none
+func (s *Symbols) label(bank int, address uint16) string {
+	if address < 0x4000 {
+		bank = 0
+	} else if address >= 0x8000 {
+		bank = -1
+	}
+	if name, ok := s.Lookup(bank, address); ok {
+		return name
+	}
+	return fmt.Sprintf("$%04X", address)
+}
@@ -0,0 +1,54 @@
+package disasm
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestParseSymbols(t *testing.T) {
+	// GIVEN
+	file := `; File generated by rgblink
+00:0150 Main
+01:4000 Init.loop ; bank 1
+02:4000 Other
+00:c000 wBuffer
+FF80 hTemp
+`
+
+	// WHEN
+	symbols, err := ParseSymbols(strings.NewReader(file))
+
+	// THEN
+	require.NoError(t, err)
+	for _, test := range []struct {
+		bank     int
+		address  uint16
+		expected string
+	}{
+		{0, 0x0150, "Main"},
+		{1, 0x4000, "Init.loop"},
+		{2, 0x4000, "Other"},
+		{-1, 0x4000, "Init.loop"},
+		{3, 0xC000, "wBuffer"},
+		{0, 0xFF80, "hTemp"},
+	} {
+		name, ok := symbols.Lookup(test.bank, test.address)
+		assert.True(t, ok, test.expected)
+		assert.Equal(t, test.expected, name)
+	}
+
+	_, ok := symbols.Lookup(3, 0x4000)
+	assert.False(t, ok)
+}
+
+func TestParseSymbols_malformed(t *testing.T) {
+	for _, file := range []string{"00:0150", "XX:0150 Main", "00:10000 Main", "Main 0150"} {
+		// WHEN
+		_, err := ParseSymbols(strings.NewReader(file))
+
+		// THEN
+		assert.ErrorIs(t, err, ErrMalformedSymbols, file)
+	}
+}
@@ -0,0 +1,491 @@
+// Package gameboy provides an embeddable Game Boy emulator based on the cycle accurate model.
+//
+// A minimal example running a game for one second:
+//
+//	gb, err := gameboy.New(gameboy.WithBootROM(bootROM), gameboy.WithCartridge(rom))
+//	if err != nil {
+//		return err
+//	}
+//	for i := 0; i < 60; i++ {
+//		gb.StepFrame()
+//	}
+//	frame := gb.Framebuffer()
+package gameboy
+
+import (
+	"bufio"
+	"errors"
+	"gameboy-emulator/internal/cartridge"
+	"gameboy-emulator/internal/cheat"
+	"gameboy-emulator/internal/cycle/apu"
+	"gameboy-emulator/internal/cycle/cpu"
+	"gameboy-emulator/internal/cycle/emulation"
+	log "go.uber.org/zap"
+	"io"
+	"os"
+)
+
+const (
+	ScreenWidth  = 160
+	ScreenHeight = 144
+
+	// SampleRate of the produced audio samples
+	SampleRate = apu.SamplingRate
+
+	// CyclesPerFrame is the number of clock cycles of one frame, see Emulator.StepFrame
+	CyclesPerFrame = emulation.CyclesPerFrame
+
+	// A rewind snapshot is taken every rewindInterval frames, see WithRewind
+	framesPerSecond = 60
+	rewindInterval  = 2
+)
+
+// ImageExtensions are the file extensions of the ROM images read by ReadCartridgeImage and InsertCartridgeFile
+var ImageExtensions = cartridge.ImageExtensions
+
+// Button of the joypad
+type Button byte
+
+const (
+	ButtonRight Button = iota
+	ButtonLeft
+	ButtonUp
+	ButtonDown
+	ButtonA
+	ButtonB
+	ButtonSelect
+	ButtonStart
+)
+
+// CartridgeHeader holds the information stored in the header of a ROM image, see Emulator.CartridgeHeader
+type CartridgeHeader = cartridge.Header
+
+// CameraSource provides the pictures seen by the sensor of the Game Boy Camera, see WithCameraSource
+type CameraSource = cartridge.CameraSource
+
+// Cheat is a decoded Game Genie or GameShark code, see Emulator.AddCheat
+type Cheat = cheat.Code
+
+// CheatKind is the device a cheat code is made for
+type CheatKind = cheat.Kind
+
+const (
+	CheatGameGenie = cheat.GameGenie
+	CheatGameShark = cheat.GameShark
+)
+
+// Debugger stops the emulation at breakpoints and steps through the program, see Emulator.Debugger
+type Debugger = emulation.Debugger
+
+// Breakpoint of the debugger, see Debugger.AddBreakpoint
+type Breakpoint = emulation.Breakpoint
+
+// Watchpoint of the debugger, see Debugger.AddWatchpoint
+type Watchpoint = emulation.Watchpoint
+
+// WatchAccess is a set of the kinds of memory accesses a watchpoint watches
+type WatchAccess = emulation.WatchAccess
+
+// WatchHit describes the memory access which hit a watchpoint
+type WatchHit = emulation.WatchHit
+
+// TraceOptions select the instructions written to a CPU trace, see Emulator.StartTrace
+type TraceOptions = emulation.TraceOptions
+
+// DebugStop describes where the debugger stopped the emulation, see Debugger.SetStopHandler
+type DebugStop = emulation.Stop
+
+// Registers of the CPU at a debug stop
+type Registers = cpu.Registers
+
+const (
+	StopPause      = emulation.StopPause
+	StopStep       = emulation.StopStep
+	StopBreakpoint = emulation.StopBreakpoint
+	StopWatchpoint = emulation.StopWatchpoint
+
+	WatchRead    = emulation.WatchRead
+	WatchWrite   = emulation.WatchWrite
+	WatchExecute = emulation.WatchExecute
+)
+
+// Frame holds the screen contents as shades from 0 (lightest) to 3 (darkest), indexed by line and column.
+type Frame [ScreenHeight][ScreenWidth]byte
+
+var (
+	ErrInvalidBootROM   = errors.New("boot ROM must be 256 bytes")
+	ErrUnsupportedModel = errors.New("unsupported model")
+
+	ErrUnsupportedMBC   = cartridge.ErrUnsupportedMBC
+	ErrTruncatedROM     = cartridge.ErrTruncatedROM
+	ErrBadHeader        = cartridge.ErrBadHeader
+	ErrROMSize          = cartridge.ErrROMSize
+	ErrNoCameraImages   = cartridge.ErrNoCameraImages
+	ErrNoROMInArchive   = cartridge.ErrNoROMInArchive
+	ErrBadPatch         = cartridge.ErrBadPatch
+	ErrPatchCRCMismatch = cartridge.ErrPatchCRCMismatch
+	ErrMalformedCheat   = cheat.ErrMalformedCode
+
+	ErrMalformedCondition = emulation.ErrMalformedCondition
+	ErrInvalidWatchpoint  = emulation.ErrInvalidWatchpoint
+
+	ErrNoCartridge            = emulation.ErrNoCartridge
+	ErrInvalidState           = emulation.ErrInvalidState
+	ErrStateCartridge         = emulation.ErrStateCartridge
+	ErrInvalidMovie           = emulation.ErrInvalidMovie
+	ErrMovieCartridgeMismatch = emulation.ErrMovieCartridgeMismatch
+	ErrCheatsActive           = emulation.ErrCheatsActive
+)
+
+// Emulator is a complete Game Boy. It is not safe for concurrent use, except for setting the input, changing the
+// cheats and controlling the debugger.
+type Emulator struct {
+	core      *emulation.Core
+	videoSink func(frame Frame)
+	audioSink func(left, right byte)
+	audio     []byte
+	rewind    *emulation.RewindBuffer
+	movie     *emulation.Movie
+
+	cartridgeOptions []cartridge.Option
+}
+
+// New creates an emulator configured by the given options. The machine is turned on and starts running the
+// boot ROM with the first call of StepFrame.
+func New(options ...Option) (*Emulator, error) {
+	c := &config{syncRTC: true}
+	for _, option := range options {
+		option(c)
+	}
+
+	if c.model != ModelDMG {
+		return nil, ErrUnsupportedModel
+	}
+	if len(c.bootROM) != 0x100 {
+		return nil, ErrInvalidBootROM
+	}
+
+	g := &Emulator{
+		core:      emulation.New((*[0x100]byte)(c.bootROM)),
+		videoSink: c.videoSink,
+		audioSink: c.audioSink,
+
+		cartridgeOptions: []cartridge.Option{cartridge.WithHostClockSync(c.syncRTC)},
+	}
+
+	if c.rewindSeconds > 0 {
+		g.rewind = emulation.NewRewindBuffer(c.rewindSeconds*framesPerSecond/rewindInterval, rewindInterval)
+	}
+	if c.camera != nil {
+		g.cartridgeOptions = append(g.cartridgeOptions, cartridge.WithCameraSource(c.camera))
+	}
+
+	serialSink := c.serialSink
+	if serialSink == nil {
+		serialSink = func(data byte) {}
+	}
+	g.core.SetSerialHandler(serialSink)
+	g.core.SetRumbleHandler(c.rumbleSink)
+
+	if c.rom != nil {
+		if err := g.InsertCartridge(c.rom, c.saveData); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// InsertCartridge inserts a cartridge created from the given ROM image and turns the machine off and on. The RAM of
+// the cartridge is initialized with the given save game, which may be nil. An invalid ROM image is reported as
+// error wrapping ErrUnsupportedMBC, ErrTruncatedROM, ErrROMSize or ErrBadHeader, the machine is left untouched then.
+func (g *Emulator) InsertCartridge(rom []byte, saveData []byte) error {
+	if err := g.core.InsertCartridgeImage(rom, saveData, g.cartridgeOptions...); err != nil {
+		return err
+	}
+	g.Reset()
+	return nil
+}
+
+// InsertCartridgeFile inserts the cartridge from the ROM image at the given path (see ReadCartridgeImage) and turns
+// the machine off and on. Unlike InsertCartridge, the save game and the cheats are loaded from the files next to the
+// image, and SaveGame and SaveCheats write them back. If the image can't be loaded, the machine is left untouched.
+func (g *Emulator) InsertCartridgeFile(path string) error {
+	if err := g.core.InsertCartridge(path, g.cartridgeOptions...); err != nil {
+		return err
+	}
+	g.Reset()
+	return nil
+}
+
+// StepFrame runs the emulation until the next frame is completed (VBlank), which takes CyclesPerFrame clock cycles
+// (~16.74ms on the original hardware). If the LCD is off, it runs for CyclesPerFrame clock cycles. The instruction in
+// progress is completed, too. Afterward, the frame is passed to the video sink and the audio samples of the frame to
+// the audio sink.
+func (g *Emulator) StepFrame() {
+	screen, audio := g.core.RunFrame()
+	g.audio = audio
+
+	if g.rewind != nil {
+		if err := g.rewind.FrameCompleted(g.core); err != nil {
+			log.L().Error("Recording rewind snapshot failed, rewinding disabled", log.Error(err))
+			g.rewind = nil
+		}
+	}
+
+	if g.audioSink != nil {
+		for i := 0; i < len(audio); i += 2 {
+			g.audioSink(audio[i], audio[i+1])
+		}
+	}
+	if g.videoSink != nil {
+		g.videoSink(screen)
+	}
+}
+
+// Rewind restores the latest snapshot of the history recorded with WithRewind and runs one frame from there, which is
+// passed to the video sink. The audio of the frame is dropped. Calling it repeatedly plays back the history in
+// reverse. It returns false if the history is exhausted or not recorded, the machine is left untouched then.
+func (g *Emulator) Rewind() bool {
+	if g.rewind == nil {
+		return false
+	}
+
+	restored, err := g.rewind.Rewind(g.core)
+	if err != nil {
+		log.L().Error("Restoring rewind snapshot failed, rewinding disabled", log.Error(err))
+		g.rewind = nil
+	}
+	if !restored {
+		return false
+	}
+
+	screen, _ := g.core.RunFrame()
+	g.audio = nil
+	if g.videoSink != nil {
+		g.videoSink(screen)
+	}
+	return true
+}
+
+// Framebuffer returns a copy of the current screen contents, which is the frame completed by the last call of
+// StepFrame.
+func (g *Emulator) Framebuffer() Frame {
+	return g.core.GetScreen()
+}
+
+// Audio returns the audio samples produced during the last frame, interleaved as left and right channel.
+func (g *Emulator) Audio() []byte {
+	return g.audio
+}
+
+// SetButton presses or releases a button of the joypad. It takes effect at the start of the next frame. May be
+// called concurrently to StepFrame.
+func (g *Emulator) SetButton(button Button, pressed bool) {
+	if pressed {
+		g.core.KeyPressed(byte(button))
+	} else {
+		g.core.KeyReleased(byte(button))
+	}
+}
+
+// SetTilt tilts cartridges with an accelerometer (like Kirby Tilt 'n' Tumble) on both axes from -1 to 1, which
+// corresponds to 90 degrees. Positive values mean tilted to the right and towards the player. It takes effect at the
+// start of the next frame. May be called concurrently to StepFrame.
+func (g *Emulator) SetTilt(x, y float64) {
+	g.core.SetTilt(x, y)
+}
+
+// Registers returns the registers of the CPU, e.g. for inspecting the machine between two calls of StepFrame
+func (g *Emulator) Registers() Registers {
+	return g.core.Registers()
+}
+
+// ReadMemory reads the byte at the given address like the CPU does, but without side effects. It's meant for
+// inspecting the machine between two calls of StepFrame.
+func (g *Emulator) ReadMemory(address uint16) byte {
+	return g.core.PeekMemory(address)
+}
+
+// SaveData returns the contents of the cartridge RAM to be persisted as save game, or nil if no cartridge is
+// inserted. For cartridges with a real time clock, the clock is appended in the format of BGB and VBA-M, so the
+// save game can be exchanged with these emulators.
+func (g *Emulator) SaveData() []byte {
+	return g.core.GetSaveGame()
+}
+
+// SaveGame writes the save game of a cartridge inserted with InsertCartridgeFile to the save file next to its ROM
+// image. Nothing is written for cartridges inserted with InsertCartridge.
+func (g *Emulator) SaveGame() {
+	g.core.SaveGame()
+}
+
+// ImportSaveGame replaces the save game of the inserted cartridge with the contents of the given file, e.g. a .sav
+// file written by another emulator, and turns the machine off and on, so the game picks it up.
+func (g *Emulator) ImportSaveGame(path string) error {
+	return g.core.ImportSaveGame(path)
+}
+
+// ExportSaveGame writes the save game of the inserted cartridge to the given file in the .sav format of other
+// emulators.
+func (g *Emulator) ExportSaveGame(path string) error {
+	return g.core.ExportSaveGame(path)
+}
+
+// ReadCartridgeImage reads the ROM image at the given path for WithCartridge. Images compressed with gzip (.gz) are
+// decompressed. For zip archives (.zip), the entry with the given name is read, or the first .gb or .gbc entry if
+// the name is empty. An IPS, BPS or UPS patch next to the image with the same base name (e.g. "game.ips" for
+// "game.gb") is applied.
+func ReadCartridgeImage(path string, entry string) ([]byte, error) {
+	rom, err := cartridge.ReadImage(path, entry)
+	if err != nil {
+		return nil, err
+	}
+	return cartridge.PatchImage(path, rom)
+}
+
+// CompanionPath returns the path of a file belonging to the ROM image at the given path, e.g. its symbol file for
+// the extension ".sym". Like save games and patches, it's named after the image or the archive containing it.
+func CompanionPath(imagePath string, extension string) string {
+	return cartridge.CompanionPath(imagePath, extension)
+}
+
+// ApplyPatch applies an IPS, BPS or UPS patch to the given ROM image. The checksums of BPS and UPS patches are
+// validated, a patch made for another ROM image fails with ErrPatchCRCMismatch.
+func ApplyPatch(rom []byte, patch []byte) ([]byte, error) {
+	return cartridge.ApplyPatch(rom, patch)
+}
+
+// AddCheat adds an enabled Game Genie ("ABC-DEF" or "ABC-DEF-GHI") or GameShark ("01VVAAAA") code. Game Genie codes
+// patch reads from the cartridge ROM, GameShark codes are written to RAM at the start of each VBlank. A malformed
+// code is reported as error wrapping ErrMalformedCheat. Inserting a cartridge removes all cheats.
+func (g *Emulator) AddCheat(code string, description string) error {
+	return g.core.GetCheats().Add(code, description)
+}
+
+// Cheats returns all cheats in the order they were added. Their index is used by SetCheatEnabled and RemoveCheat.
+func (g *Emulator) Cheats() []Cheat {
+	return g.core.GetCheats().Codes()
+}
+
+// SetCheatEnabled enables or disables the cheat with the given index
+func (g *Emulator) SetCheatEnabled(index int, enabled bool) {
+	g.core.GetCheats().SetEnabled(index, enabled)
+}
+
+// RemoveCheat removes the cheat with the given index
+func (g *Emulator) RemoveCheat(index int) {
+	g.core.GetCheats().Remove(index)
+}
+
+// LoadCheats replaces the cheats with the ones from a cheat file. Each line holds "on" or "off", the code and an
+// optional description, lines starting with "#" are ignored.
+func (g *Emulator) LoadCheats(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	cheats, err := cheat.Load(path)
+	if err != nil {
+		return err
+	}
+	g.core.SetCheats(cheats)
+	return nil
+}
+
+// SaveCheats writes the cheats to the cheat file next to the ROM image inserted with InsertCartridgeFile. Nothing is
+// written for cartridges inserted with InsertCartridge.
+func (g *Emulator) SaveCheats() error {
+	return g.core.SaveCheats()
+}
+
+// Debugger returns the debugger, which is attached with the first call. While the debugger has stopped the
+// emulation, StepFrame blocks until it's resumed, so the debugger is controlled by another goroutine than the one
+// calling StepFrame.
+func (g *Emulator) Debugger() *Debugger {
+	return g.core.Debugger()
+}
+
+// StartTrace writes the registers and the next four bytes of memory before each instruction selected by the given
+// options to w, in the format of Gameboy Doctor (https://github.com/robert/gameboy-doctor). Traces of test ROMs can
+// be compared with its reference logs line by line. StopTrace must be called to flush the trace.
+func (g *Emulator) StartTrace(w io.Writer, options TraceOptions) {
+	g.core.StartTrace(w, options)
+}
+
+// StopTrace ends the trace and flushes it, returning the first error writing it
+func (g *Emulator) StopTrace() error {
+	return g.core.StopTrace()
+}
+
+// LoadCameraImages creates a camera source from PNG images on disk. If the path is a file, every photo shows this
+// image. If it is a directory, the PNG images in it are returned one after another for each photo, ordered by name.
+func LoadCameraImages(path string) (CameraSource, error) {
+	return cartridge.LoadCameraImages(path)
+}
+
+// CartridgeHeader returns the header of the inserted cartridge, e.g. for showing the title of the game. The second
+// result is false if no cartridge is inserted. Use CartridgeHeader.Verify to detect corrupt ROM images.
+func (g *Emulator) CartridgeHeader() (CartridgeHeader, bool) {
+	return g.core.GetCartridgeHeader()
+}
+
+// Reset turns the machine off and on again. The save game of the cartridge is kept, the history recorded for
+// rewinding is dropped.
+func (g *Emulator) Reset() {
+	g.core.PowerCycle()
+	if g.rewind != nil {
+		g.rewind.Clear()
+	}
+}
+
+// SaveState writes a snapshot of the complete machine. StepFrame ends between two instructions, where snapshots can
+// be taken.
+func (g *Emulator) SaveState(w io.Writer) error {
+	return g.core.SaveState(w)
+}
+
+// LoadState restores a snapshot written by SaveState. The cartridge the snapshot was taken with has to be inserted
+// already, a snapshot of another cartridge fails with ErrStateCartridge and a corrupted one with ErrInvalidState.
+// The machine is left untouched if loading fails.
+func (g *Emulator) LoadState(r io.Reader) error {
+	return g.core.LoadState(r)
+}
+
+// PlayMovie plays back a movie recorded by the emulator frontend. The input of the movie replaces the input set
+// with SetButton until the end of the movie is reached.
+func (g *Emulator) PlayMovie(r io.Reader) error {
+	movie, err := emulation.ReadMovie(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+
+	if err := g.core.PlayMovie(movie); err != nil {
+		return err
+	}
+	g.movie = nil
+	return nil
+}
+
+// RecordMovie starts recording the joypad input of every frame into a movie, which is written by StopMovie. If
+// fromPowerOn is set, the machine is turned off and on first (the save game is kept), otherwise the movie starts with
+// a snapshot of the current state. Movies don't contain cheats, so recording fails with ErrCheatsActive while cheats
+// are enabled, and they must stay disabled until the recording is stopped.
+func (g *Emulator) RecordMovie(fromPowerOn bool) error {
+	movie, err := g.core.RecordMovie(fromPowerOn)
+	if err != nil {
+		return err
+	}
+	g.movie = movie
+	return nil
+}
+
+// StopMovie stops recording or playing back a movie. A movie recorded with RecordMovie is written to w.
+func (g *Emulator) StopMovie(w io.Writer) error {
+	g.core.StopMovie()
+
+	movie := g.movie
+	g.movie = nil
+	if movie == nil {
+		return nil
+	}
+	return movie.Write(w)
+}
@@ -0,0 +1,283 @@
+package gameboy
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Boot ROM which only unmaps itself and then slides over the NOPs of the test ROM to 0x0100
+var testBootROM = append([]byte{
+	0x3E, 0x01, // LD A, 0x01
+	0xE0, 0x50, // LD (0xFF00+0x50), A
+}, make([]byte, 0xFC)...)
+
+// Program which turns on the sound, sends a byte over the link cable, writes into the cartridge RAM and then
+// loops forever
+var testProgram = []byte{
+	0x3E, 0x80, 0xE0, 0x26, // NR52: APU on
+	0x3E, 'X', 0xE0, 0x01, // SB: data to send
+	0x3E, 0x81, 0xE0, 0x02, // SC: start transfer
+	0x3E, 0x42, 0xEA, 0x00, 0xA0, // LD (0xA000), 0x42
+	0x18, 0xFE, // JR -2
+}
+
+func newTestROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], testProgram)
+	return rom
+}
+
+// Program which turns on the LCD and then keeps on filling the tile data with a value increased for every pass, so
+// the background changes every few frames
+var animationTestProgram = []byte{
+	0x3E, 0xE4, 0xE0, 0x47, // 0x0100: BGP: shades 0 to 3
+	0x3E, 0x91, 0xE0, 0x40, // 0x0104: LCDC: LCD and background on
+	0x21, 0x00, 0x80, // 0x0108: LD HL, 0x8000
+	0x04,       // 0x010B: INC B
+	0x78,       // 0x010C: LD A, B
+	0x22,       // 0x010D: LD (HL+), A
+	0x7C,       // 0x010E: LD A, H
+	0xFE, 0x98, // 0x010F: CP 0x98
+	0x20, 0xF9, // 0x0111: JR NZ, 0x010C
+	0x18, 0xF3, // 0x0113: JR 0x0108
+}
+
+func newAnimationTestROM() []byte {
+	rom := make([]byte, 0x8000)
+	copy(rom[0x100:], animationTestProgram)
+	return rom
+}
+
+// readVRAM reads the tile data of the tiles shown in the background
+func readVRAM(gb *Emulator) []byte {
+	vram := make([]byte, 0x10)
+	for i := range vram {
+		vram[i] = gb.ReadMemory(0x8000 + uint16(i))
+	}
+	return vram
+}
+
+func TestNew_invalidOptions(t *testing.T) {
+	// WHEN
+	_, errBootROM := New(WithBootROM(make([]byte, 10)))
+	_, errModel := New(WithBootROM(testBootROM), WithModel(Model(42)))
+
+	// THEN
+	assert.ErrorIs(t, errBootROM, ErrInvalidBootROM)
+	assert.ErrorIs(t, errModel, ErrUnsupportedModel)
+}
+
+func TestEmulator_InsertCartridge_invalidROM(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newTestROM()))
+	require.NoError(t, err)
+
+	rom := newTestROM()
+	rom[0x147] = 0x04 // not assigned to any cartridge type
+
+	// WHEN
+	_, errNew := New(WithBootROM(testBootROM), WithCartridge(make([]byte, 0x100)))
+	errInsert := gb.InsertCartridge(rom, nil)
+
+	// THEN
+	assert.ErrorIs(t, errNew, ErrTruncatedROM)
+	assert.ErrorIs(t, errInsert, ErrUnsupportedMBC)
+}
+
+func TestEmulator_StepFrame(t *testing.T) {
+	// GIVEN
+	var frames []Frame
+	var samples int
+	var serial []byte
+
+	gb, err := New(
+		WithBootROM(testBootROM),
+		WithCartridge(newTestROM()),
+		WithVideoSink(func(frame Frame) { frames = append(frames, frame) }),
+		WithAudioSink(func(_, _ byte) { samples++ }),
+		WithSerialSink(func(data byte) { serial = append(serial, data) }),
+	)
+	require.NoError(t, err)
+
+	// WHEN
+	gb.StepFrame()
+	gb.StepFrame()
+
+	// THEN
+	assert.Len(t, frames, 2)
+	assert.Equal(t, frames[1], gb.Framebuffer())
+	assert.InDelta(t, 2*SampleRate/60, samples, 10)     // sink received the samples of both frames
+	assert.InDelta(t, samples/2, len(gb.Audio())/2, 10) // samples of the last frame, two bytes each
+	assert.Equal(t, []byte("X"), serial)
+}
+
+func TestEmulator_Rewind(t *testing.T) {
+	// GIVEN
+	var frames, samples int
+	gb, err := New(
+		WithBootROM(testBootROM),
+		WithCartridge(newAnimationTestROM()),
+		WithVideoSink(func(Frame) { frames++ }),
+		WithAudioSink(func(_, _ byte) { samples++ }),
+		WithRewind(1),
+	)
+	require.NoError(t, err)
+
+	var registers []Registers
+	for i := 0; i < 4; i++ {
+		gb.StepFrame()
+		registers = append(registers, gb.Registers())
+	}
+	frames, samples = 0, 0
+
+	// WHEN
+	var rewound []bool
+	for i := 0; i < 3; i++ {
+		rewound = append(rewound, gb.Rewind())
+	}
+
+	// THEN
+	// Snapshots were taken after the 2nd and 4th frame, so the 3rd frame is shown last. Afterward, the history
+	// is exhausted and the machine is left untouched.
+	assert.Equal(t, []bool{true, true, false}, rewound)
+	assert.Equal(t, registers[2], gb.Registers())
+	assert.Equal(t, 2, frames)
+	assert.Equal(t, 0, samples)
+	assert.Equal(t, 0, gb.rewind.Len())
+}
+
+func TestEmulator_SaveData(t *testing.T) {
+	// GIVEN
+	saveData := make([]byte, 0x2000)
+	saveData[1] = 0x17
+
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newTestROM()), WithSaveData(saveData))
+	require.NoError(t, err)
+
+	// WHEN
+	gb.StepFrame()
+	gb.Reset()
+	result := gb.SaveData()
+
+	// THEN
+	assert.Equal(t, byte(0x42), result[0])
+	assert.Equal(t, byte(0x17), result[1])
+}
+
+func TestEmulator_SaveStateLoadState(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newAnimationTestROM()))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		gb.StepFrame()
+	}
+
+	registers, vram, frame := gb.Registers(), readVRAM(gb), gb.Framebuffer()
+	var state bytes.Buffer
+	require.NoError(t, gb.SaveState(&state))
+
+	gb.SetButton(ButtonStart, true)
+	for i := 0; i < 4; i++ {
+		gb.StepFrame()
+	}
+	require.NotEqual(t, registers, gb.Registers())
+	require.NotEqual(t, vram, readVRAM(gb))
+	require.NotEqual(t, frame, gb.Framebuffer())
+
+	// WHEN
+	err = gb.LoadState(&state)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, registers, gb.Registers())
+	assert.Equal(t, vram, readVRAM(gb))
+	assert.Equal(t, frame, gb.Framebuffer())
+	assert.ErrorIs(t, gb.LoadState(bytes.NewReader([]byte("garbage"))), ErrInvalidState)
+}
+
+func TestEmulator_PlayMovie_noCartridge(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM))
+	require.NoError(t, err)
+
+	// WHEN
+	err = gb.PlayMovie(bytes.NewReader([]byte("GOMEBOY-MOVIE\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00")))
+
+	// THEN
+	assert.ErrorIs(t, err, ErrNoCartridge)
+}
+
+func TestEmulator_RecordMovie(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newAnimationTestROM()))
+	require.NoError(t, err)
+	require.NoError(t, gb.RecordMovie(true))
+
+	for i := 0; i < 4; i++ {
+		gb.StepFrame()
+	}
+	registers := gb.Registers()
+
+	var movie bytes.Buffer
+	require.NoError(t, gb.StopMovie(&movie))
+
+	// WHEN
+	err = gb.PlayMovie(&movie)
+	for i := 0; i < 4; i++ {
+		gb.StepFrame()
+	}
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, registers, gb.Registers())
+}
+
+func TestEmulator_AddCheat(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newTestROM()))
+	require.NoError(t, err)
+
+	// WHEN
+	errValid := gb.AddCheat("010A38C1", "Infinite lives")
+	errMalformed := gb.AddCheat("XYZ", "")
+	gb.SetCheatEnabled(0, false)
+	cheats := gb.Cheats()
+	gb.RemoveCheat(0)
+
+	// THEN
+	require.NoError(t, errValid)
+	assert.ErrorIs(t, errMalformed, ErrMalformedCheat)
+	require.Len(t, cheats, 1)
+	assert.Equal(t, CheatGameShark, cheats[0].Kind)
+	assert.False(t, cheats[0].Enabled)
+	assert.Empty(t, gb.Cheats())
+}
+
+func TestEmulator_Debugger(t *testing.T) {
+	// GIVEN
+	gb, err := New(WithBootROM(testBootROM), WithCartridge(newTestROM()))
+	require.NoError(t, err)
+
+	debugger := gb.Debugger()
+	stops := make(chan DebugStop, 1)
+	debugger.SetStopHandler(func(stop DebugStop) { stops <- stop })
+	_, err = debugger.AddBreakpoint(0x0100, -1, "a == 0x01")
+	require.NoError(t, err)
+
+	// WHEN
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		gb.StepFrame()
+	}()
+	stop := <-stops
+	debugger.Detach()
+	<-done
+
+	// THEN
+	assert.Equal(t, StopBreakpoint, stop.Reason)
+	assert.Equal(t, uint16(0x0100), stop.Registers.PC)
+	assert.Equal(t, "LD A, $80", stop.Instruction)
+}
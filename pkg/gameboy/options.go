@@ -0,0 +1,114 @@
+package gameboy
+
+// Model of the emulated hardware
+type Model int
+
+const (
+	// ModelDMG is the original Game Boy
+	ModelDMG Model = iota
+)
+
+type (
+	// Option configures an Emulator, see New.
+	Option func(c *config)
+
+	config struct {
+		bootROM    []byte
+		rom        []byte
+		saveData   []byte
+		model      Model
+		videoSink  func(frame Frame)
+		audioSink  func(left, right byte)
+		serialSink func(data byte)
+		rumbleSink func(on bool, intensity float64)
+		syncRTC    bool
+		camera     CameraSource
+
+		rewindSeconds int
+	}
+)
+
+// WithBootROM sets the boot ROM (256 bytes) which is run at power-on. Required.
+func WithBootROM(data []byte) Option {
+	return func(c *config) {
+		c.bootROM = data
+	}
+}
+
+// WithCartridge inserts a cartridge created from the given ROM image.
+func WithCartridge(rom []byte) Option {
+	return func(c *config) {
+		c.rom = rom
+	}
+}
+
+// WithSaveData initializes the RAM of the cartridge with a save game, see Emulator.SaveData.
+func WithSaveData(ram []byte) Option {
+	return func(c *config) {
+		c.saveData = ram
+	}
+}
+
+// WithRumbleSink registers a sink which receives the state of the rumble motor of the cartridge after every frame. The
+// intensity is the share of the frame the motor was turned on, from 0 (off) to 1. The sink isn't called for cartridges
+// without rumble motor.
+func WithRumbleSink(sink func(on bool, intensity float64)) Option {
+	return func(c *config) {
+		c.rumbleSink = sink
+	}
+}
+
+// WithHostClockSync controls whether the real time clock of the cartridge is advanced by the time passed on the host
+// since the save game was written (see SaveData). Enabled by default. Otherwise, the clock runs in emulated time only,
+// which makes runs reproducible.
+func WithHostClockSync(enabled bool) Option {
+	return func(c *config) {
+		c.syncRTC = enabled
+	}
+}
+
+// WithCameraSource sets the source of the pictures taken with the Game Boy Camera, see LoadCameraImages. By default,
+// the lens is covered and all pictures are black.
+func WithCameraSource(source CameraSource) Option {
+	return func(c *config) {
+		c.camera = source
+	}
+}
+
+// WithModel selects the emulated hardware. Defaults to ModelDMG, which is the only supported model so far.
+func WithModel(model Model) Option {
+	return func(c *config) {
+		c.model = model
+	}
+}
+
+// WithVideoSink registers a sink which receives the screen contents after every frame.
+func WithVideoSink(sink func(frame Frame)) Option {
+	return func(c *config) {
+		c.videoSink = sink
+	}
+}
+
+// WithAudioSink registers a sink which receives the audio samples of every frame. Samples are unsigned 8-bit values
+// played at SampleRate.
+func WithAudioSink(sink func(left, right byte)) Option {
+	return func(c *config) {
+		c.audioSink = sink
+	}
+}
+
+// WithSerialSink registers a sink which receives every byte the game sends over the link cable. By default,
+// the bytes are discarded.
+func WithSerialSink(sink func(data byte)) Option {
+	return func(c *config) {
+		c.serialSink = sink
+	}
+}
+
+// WithRewind records the history of the last seconds of the emulation, so it can be played back in reverse with
+// Emulator.Rewind. Snapshots are taken every other frame. Disabled by default.
+func WithRewind(seconds int) Option {
+	return func(c *config) {
+		c.rewindSeconds = seconds
+	}
+}
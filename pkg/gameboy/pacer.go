@@ -0,0 +1,27 @@
+package gameboy
+
+import "gameboy-emulator/internal/cycle/emulation"
+
+// FrameDuration is the time the original hardware needs to draw one frame (~16.74ms, ~59.73 Hz)
+const FrameDuration = emulation.FrameDuration
+
+type (
+	// Driver runs an emulator in the background, e.g. a Pacer
+	Driver = emulation.Driver
+
+	// Clock is a monotonic time source used by a Pacer. It can be replaced for testing.
+	Clock = emulation.Clock
+
+	// Pacer runs an emulator frame by frame (see Emulator.StepFrame) in its own goroutine and sleeps after every
+	// frame until the point in time the frame would have been finished on the original hardware. While rewinding,
+	// it plays back the history with Emulator.Rewind instead.
+	//
+	// While the pacer is running, only the methods of the emulator which may be called concurrently to StepFrame
+	// must be used.
+	Pacer = emulation.FramePacedDriver
+)
+
+// NewPacer creates a pacer for the given emulator. If clock is nil, the clock of the system is used.
+func NewPacer(emulator *Emulator, clock Clock) *Pacer {
+	return emulation.NewFramePacedDriver(emulator, clock)
+}
@@ -121,10 +121,13 @@ func (ui *UserInterface) handleOpen() {
 		}
 		saveFile = f.URI().Path()
 
+		if err := ui.emulator.InsertCartridge(saveFile); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+
 		ui.pauseAction.Enable()
 		ui.stopAction.Enable()
-
-		ui.emulator.InsertCartridge(saveFile)
 		ui.emulator.Run()
 		w.Close()
 	}, w)